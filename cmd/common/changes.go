@@ -0,0 +1,26 @@
+package common
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/mholtzscher/github-janitor/internal/sync"
+)
+
+// FormatChange renders a change the way plan and sync print it, e.g.
+// "has_wiki: false → true (repository)". Removals are marked in red.
+func FormatChange(change sync.Change) string {
+	arrow := Yellow("→")
+	if reflect.DeepEqual(change.Current, change.Desired) {
+		arrow = "="
+	}
+	suffix := ""
+	if change.Source != "" {
+		suffix = " (" + change.Source + ")"
+	}
+	if change.Removal {
+		arrow = Red("→")
+		suffix += " " + Red("[REMOVAL]")
+	}
+	return fmt.Sprintf("%s: %v %s %v%s", Cyan(change.Field), change.Current, arrow, change.Desired, suffix)
+}
@@ -0,0 +1,41 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	ufcli "github.com/urfave/cli/v3"
+
+	"github.com/mholtzscher/github-janitor/internal/config"
+	"github.com/mholtzscher/github-janitor/internal/github"
+)
+
+// NewClient creates a GitHub client from the global flags. The --api-url flag
+// takes precedence over the server set in cfg, which may be nil.
+func NewClient(ctx context.Context, cmd *ufcli.Command, cfg *config.Config) (*github.Client, error) {
+	opts := github.Options{
+		Token:  cmd.String(FlagToken),
+		APIURL: cmd.String(FlagAPIURL),
+		AppID:  cmd.Int64(FlagAppID),
+	}
+	if opts.APIURL == "" && cfg != nil {
+		opts.APIURL = cfg.Server
+	}
+
+	keyPath := cmd.String(FlagAppPrivateKey)
+	switch {
+	case opts.AppID != 0 && keyPath == "":
+		return nil, fmt.Errorf("--%s requires --%s", FlagAppID, FlagAppPrivateKey)
+	case opts.AppID == 0 && keyPath != "":
+		return nil, fmt.Errorf("--%s requires --%s", FlagAppPrivateKey, FlagAppID)
+	case keyPath != "":
+		key, err := os.ReadFile(keyPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read GitHub App private key: %w", err)
+		}
+		opts.AppPrivateKey = key
+	}
+
+	return github.NewClient(ctx, opts)
+}
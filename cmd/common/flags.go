@@ -4,5 +4,24 @@ const (
 	FlagNoColor = "no-color"
 	FlagConfig  = "config"
 	FlagToken   = "token"
-	FlagDryRun  = "dry-run"
+	FlagTimeout = "timeout"
+	FlagAPIURL  = "api-url"
+
+	FlagAppID         = "app-id"
+	FlagAppPrivateKey = "app-private-key"
+	FlagDryRun        = "dry-run"
+	FlagOutput        = "output"
+	FlagOut           = "out"
+	FlagPlan          = "plan"
+
+	FlagDetailedExitCode = "detailed-exitcode"
+
+	FlagParallelism  = "parallelism"
+	FlagFailFast     = "fail-fast"
+	FlagSecretsState = "secrets-state"
+
+	FlagOrg    = "org"
+	FlagBranch = "branch"
+	FlagFactor = "factor"
+	FlagForce  = "force"
 )
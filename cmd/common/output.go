@@ -0,0 +1,73 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	ufcli "github.com/urfave/cli/v3"
+
+	"github.com/mholtzscher/github-janitor/internal/github"
+	"github.com/mholtzscher/github-janitor/internal/report"
+	"github.com/mholtzscher/github-janitor/internal/sync"
+)
+
+// OutputFlag returns the --output flag shared by commands that print results.
+func OutputFlag() *ufcli.StringFlag {
+	return &ufcli.StringFlag{
+		Name:    FlagOutput,
+		Aliases: []string{"o"},
+		Value:   report.FormatText,
+		Usage:   "Output format: text, json or yaml",
+		Validator: func(format string) error {
+			if !slices.Contains(report.Formats(), format) {
+				return fmt.Errorf("invalid output format %q: must be one of %v", format, report.Formats())
+			}
+			return nil
+		},
+	}
+}
+
+// InfoWriter returns where progress messages go for the given output format.
+// Machine-readable formats keep stdout for the document itself.
+func InfoWriter(format string) io.Writer {
+	if format == report.FormatText {
+		return os.Stdout
+	}
+	return os.Stderr
+}
+
+// PrintRateLimit reports the API quota left after a run, if GitHub reported one.
+func PrintRateLimit(w io.Writer, client *github.Client) {
+	rate := client.RateLimit()
+	if rate == nil {
+		return
+	}
+	remaining := Green(rate.Remaining)
+	if rate.Limit > 0 && rate.Remaining*10 < rate.Limit { //nolint:mnd // Warn below 10% of the quota
+		remaining = Yellow(rate.Remaining)
+	}
+	fmt.Fprintf(
+		w,
+		"API rate limit: %s/%d remaining (resets at %s)\n",
+		remaining,
+		rate.Limit,
+		rate.Reset.Local().Format(time.Kitchen),
+	)
+}
+
+// CheckInterrupted lists the repositories that were never started and returns
+// an error if the run was cancelled or timed out.
+func CheckInterrupted(ctx context.Context, w io.Writer, results []sync.Result) error {
+	if names := sync.NotProcessed(results); len(names) > 0 {
+		fmt.Fprintf(w, "%s %s\n", Yellow(fmt.Sprintf("Not processed (%d):", len(names))), strings.Join(names, ", "))
+	}
+	if ctx.Err() != nil {
+		return fmt.Errorf("run interrupted: %w", context.Cause(ctx))
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package common
+
+import (
+	"errors"
+
+	ufcli "github.com/urfave/cli/v3"
+
+	"github.com/mholtzscher/github-janitor/internal/sync"
+)
+
+// DefaultParallelism is the number of repositories processed at once by default.
+const DefaultParallelism = 4
+
+// SyncFlags returns the flags that control how repositories are processed.
+func SyncFlags() []ufcli.Flag {
+	return []ufcli.Flag{
+		&ufcli.IntFlag{
+			Name:  FlagParallelism,
+			Value: DefaultParallelism,
+			Usage: "Number of repositories to process concurrently",
+			Validator: func(n int) error {
+				if n < 1 {
+					return errors.New("parallelism must be at least 1")
+				}
+				return nil
+			},
+		},
+		&ufcli.BoolFlag{
+			Name:  FlagFailFast,
+			Usage: "Stop starting new repositories after the first failure",
+		},
+		SecretsStateFlag(),
+	}
+}
+
+// SyncOptions reads the processing flags into syncer options.
+func SyncOptions(cmd *ufcli.Command) sync.Options {
+	return sync.Options{
+		Parallelism:  cmd.Int(FlagParallelism),
+		FailFast:     cmd.Bool(FlagFailFast),
+		SecretsState: cmd.String(FlagSecretsState),
+	}
+}
@@ -0,0 +1,16 @@
+package common
+
+import ufcli "github.com/urfave/cli/v3"
+
+// DefaultSecretsState is the default path of the uploaded secrets record.
+const DefaultSecretsState = ".github-janitor-secrets.json"
+
+// SecretsStateFlag returns the --secrets-state flag shared by commands that
+// compare or upload Actions secrets.
+func SecretsStateFlag() *ufcli.StringFlag {
+	return &ufcli.StringFlag{
+		Name:  FlagSecretsState,
+		Value: DefaultSecretsState,
+		Usage: "File recording hashes of uploaded Actions secrets, used to decide when to upload them again",
+	}
+}
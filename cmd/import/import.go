@@ -0,0 +1,130 @@
+// Package importcmd provides the import subcommand.
+package importcmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	ufcli "github.com/urfave/cli/v3"
+
+	"github.com/mholtzscher/github-janitor/cmd/common"
+	"github.com/mholtzscher/github-janitor/internal/config"
+	"github.com/mholtzscher/github-janitor/internal/github"
+	"github.com/mholtzscher/github-janitor/internal/importer"
+)
+
+const header = "# Generated by 'github-janitor import' from the live repository settings.\n\n"
+
+// NewCommand creates the import command.
+func NewCommand() *ufcli.Command {
+	return &ufcli.Command{
+		Name:      "import",
+		Usage:     "Generate a configuration file from existing repositories",
+		ArgsUsage: "[owner/name...]",
+		Flags: []ufcli.Flag{
+			&ufcli.StringFlag{
+				Name:  common.FlagOrg,
+				Usage: "Import every non-archived repository in this organization",
+			},
+			&ufcli.StringFlag{
+				Name:  common.FlagBranch,
+				Usage: "Branch whose protection is imported (default: each repository's default branch)",
+			},
+			&ufcli.BoolFlag{
+				Name:  common.FlagFactor,
+				Value: true,
+				Usage: "Move settings shared by all repositories into the global settings block",
+			},
+			&ufcli.BoolFlag{
+				Name:  common.FlagForce,
+				Usage: "Overwrite the configuration file if it already exists",
+			},
+		},
+		Action: func(ctx context.Context, cmd *ufcli.Command) error {
+			return runImport(ctx, cmd)
+		},
+	}
+}
+
+func runImport(ctx context.Context, cmd *ufcli.Command) error {
+	configPath := cmd.String(common.FlagConfig)
+	org := cmd.String(common.FlagOrg)
+
+	repos, err := parseRepositories(cmd.Args().Slice())
+	if err != nil {
+		return err
+	}
+	if len(repos) == 0 && org == "" {
+		return errors.New("specify at least one owner/name or --org")
+	}
+
+	// Check if file already exists
+	if _, statErr := os.Stat(configPath); statErr == nil && !cmd.Bool(common.FlagForce) {
+		return fmt.Errorf("config file already exists: %s (use --%s to overwrite)", configPath, common.FlagForce)
+	}
+
+	// Create GitHub client
+	client, err := common.NewClient(ctx, cmd, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create GitHub client: %w", err)
+	}
+
+	// Validate authentication
+	if authErr := client.ValidateAuth(ctx); authErr != nil {
+		return authErr
+	}
+
+	if org != "" {
+		listed, listErr := client.ListRepositories(ctx, github.OwnerTypeOrg, org)
+		if listErr != nil {
+			return listErr
+		}
+		for _, summary := range listed {
+			if !summary.Archived {
+				repos = append(repos, config.Repository{Owner: summary.Owner, Name: summary.Name})
+			}
+		}
+	}
+
+	fmt.Printf("Importing %s repositories...\n", common.Cyan(len(repos))) //nolint:forbidigo // CLI output
+
+	cfg, err := importer.NewImporter(client).Import(ctx, repos, importer.Options{
+		Branch: cmd.String(common.FlagBranch),
+		Factor: cmd.Bool(common.FlagFactor),
+	})
+	if err != nil {
+		return fmt.Errorf("import failed: %w", err)
+	}
+	cfg.Server = cmd.String(common.FlagAPIURL)
+
+	data, err := cfg.Marshal()
+	if err != nil {
+		return err
+	}
+	if writeErr := os.WriteFile(configPath, append([]byte(header), data...), config.DefaultFileMode); writeErr != nil {
+		return fmt.Errorf("failed to create config file: %w", writeErr)
+	}
+
+	fmt.Printf("Created configuration file: %s\n", common.Green(configPath))                //nolint:forbidigo // CLI output
+	fmt.Println("\n" + common.BoldWhite("Next steps:"))                                     //nolint:forbidigo // CLI output
+	fmt.Println("1. Review the generated settings and trim what you do not want to manage") //nolint:forbidigo // CLI output
+	fmt.Println("2. Run 'github-janitor plan' to confirm there is no drift")                //nolint:forbidigo // CLI output
+
+	return nil
+}
+
+// parseRepositories parses owner/name arguments.
+func parseRepositories(args []string) ([]config.Repository, error) {
+	repos := make([]config.Repository, 0, len(args))
+	for _, arg := range args {
+		owner, name, ok := strings.Cut(arg, "/")
+		if !ok || owner == "" || name == "" || strings.Contains(name, "/") {
+			return nil, fmt.Errorf("invalid repository %q: expected owner/name", arg)
+		}
+		repos = append(repos, config.Repository{Owner: owner, Name: name})
+	}
+	return repos, nil
+}
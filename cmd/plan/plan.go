@@ -4,28 +4,47 @@ package plan
 import (
 	"context"
 	"fmt"
-	"reflect"
+	"os"
+	"strings"
 
 	ufcli "github.com/urfave/cli/v3"
 
 	"github.com/mholtzscher/github-janitor/cmd/common"
 	"github.com/mholtzscher/github-janitor/internal/config"
-	"github.com/mholtzscher/github-janitor/internal/github"
+	"github.com/mholtzscher/github-janitor/internal/report"
 	"github.com/mholtzscher/github-janitor/internal/sync"
 )
 
+// Exit codes returned with --detailed-exitcode. No drift exits 0.
+const (
+	ExitCodeError = 1
+	ExitCodeDrift = 2
+)
+
 // NewCommand creates the plan command (dry-run mode).
 func NewCommand() *ufcli.Command {
 	return &ufcli.Command{
-		Name:   "plan",
-		Usage:  "Preview what changes would be made (dry-run mode)",
+		Name:  "plan",
+		Usage: "Preview what changes would be made (dry-run mode)",
+		Flags: append([]ufcli.Flag{
+			common.OutputFlag(),
+			&ufcli.StringFlag{
+				Name:  common.FlagOut,
+				Usage: "Save the computed changes to a plan file for 'sync --plan'",
+			},
+			&ufcli.BoolFlag{
+				Name:  common.FlagDetailedExitCode,
+				Usage: "Exit 0 when there is no drift, 2 when drift is detected and 1 on errors",
+			},
+		}, common.SyncFlags()...),
 		Action: runPlan,
 	}
 }
 
-func runPlan(_ context.Context, cmd *ufcli.Command) error {
+func runPlan(ctx context.Context, cmd *ufcli.Command) error {
 	configPath := cmd.String(common.FlagConfig)
-	token := cmd.String(common.FlagToken)
+	format := cmd.String(common.FlagOutput)
+	info := common.InfoWriter(format)
 
 	// Load configuration
 	cfg, err := config.Load(configPath)
@@ -34,44 +53,95 @@ func runPlan(_ context.Context, cmd *ufcli.Command) error {
 	}
 
 	// Create GitHub client
-	client, err := github.NewClient(token)
+	client, err := common.NewClient(ctx, cmd, cfg)
 	if err != nil {
 		return fmt.Errorf("failed to create GitHub client: %w", err)
 	}
 
 	// Validate authentication
-	if authErr := client.ValidateAuth(); authErr != nil {
+	if authErr := client.ValidateAuth(ctx); authErr != nil {
 		return authErr
 	}
 
-	user, err := client.GetAuthenticatedUser()
+	user, err := client.GetAuthenticatedUser(ctx)
 	if err != nil {
 		return err
 	}
-	fmt.Printf( //nolint:forbidigo // CLI output
+	fmt.Fprintf(
+		info,
 		"Authenticated as: %s (token from: %s)\n\n",
 		common.Cyan(user),
 		common.Cyan(client.TokenSource),
 	)
 
 	// Create syncer
-	syncer := sync.NewSyncer(client, cfg)
+	syncer := sync.NewSyncer(client, cfg, common.SyncOptions(cmd))
+
+	// Expand discovery sources into repositories
+	sources, err := syncer.DiscoverRepositories(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to discover repositories: %w", err)
+	}
 
 	mode := common.Yellow("DRY-RUN (preview only)")
 	modeColor := common.Yellow
 
-	fmt.Printf("Mode: %s\n", mode)                                       //nolint:forbidigo // CLI output
-	fmt.Printf("Repositories: %s\n\n", modeColor(len(cfg.Repositories))) //nolint:forbidigo // CLI output
+	fmt.Fprintf(info, "Mode: %s\n", mode)
+	for _, src := range sources {
+		fmt.Fprintf(info, "Source %s: %s repositories\n", src.Source, modeColor(src.Count))
+	}
+	fmt.Fprintf(info, "Repositories: %s\n\n", modeColor(len(cfg.Repositories)))
 
 	// Execute sync in dry-run mode
-	results, err := syncer.SyncAll(true)
+	results, err := syncer.SyncAll(ctx, true)
 	if err != nil {
 		return fmt.Errorf("sync failed: %w", err)
 	}
 
+	// Save plan file
+	if outPath := cmd.String(common.FlagOut); outPath != "" {
+		plan := sync.NewPlan(results)
+		plan.APIURL = client.APIURL()
+		if writeErr := sync.WritePlan(outPath, plan); writeErr != nil {
+			return writeErr
+		}
+		fmt.Fprintf(
+			info,
+			"Plan saved to: %s (%s repositories with changes)\n",
+			common.Cyan(outPath),
+			common.Yellow(len(plan.Repositories)),
+		)
+	}
+
 	// Print results
-	printResults(results)
+	if format != report.FormatText {
+		if writeErr := report.Write(os.Stdout, format, report.New(results, true)); writeErr != nil {
+			return writeErr
+		}
+	} else {
+		printResults(results)
+	}
+	common.PrintRateLimit(info, client)
+	if interruptErr := common.CheckInterrupted(ctx, info, results); interruptErr != nil {
+		return interruptErr
+	}
 
+	return detailedExit(cmd, results)
+}
+
+// detailedExit maps the results onto an exit code when --detailed-exitcode is set.
+func detailedExit(cmd *ufcli.Command, results []sync.Result) error {
+	if !cmd.Bool(common.FlagDetailedExitCode) {
+		return nil
+	}
+
+	summary := sync.Summarize(results)
+	switch {
+	case summary.Failed > 0:
+		return ufcli.Exit(fmt.Sprintf("errors in %d repositories", summary.Failed), ExitCodeError)
+	case summary.Drifted > 0:
+		return ufcli.Exit(fmt.Sprintf("drift detected in %d repositories", summary.Drifted), ExitCodeDrift)
+	}
 	return nil
 }
 
@@ -80,6 +150,7 @@ func printResults(results []sync.Result) {
 	fmt.Println(common.BoldWhite("SYNC RESULTS"))                                   //nolint:forbidigo // CLI output
 	fmt.Println(common.BoldWhite(common.Repeat("=", common.SeparatorWidth)))        //nolint:forbidigo // CLI output
 
+	removals := 0
 	for _, result := range results {
 		status := common.Green("✓")
 		if result.Error != nil {
@@ -93,25 +164,47 @@ func printResults(results []sync.Result) {
 			continue
 		}
 
+		if result.Skipped != "" {
+			fmt.Println("   " + common.Yellow("Skipped: "+result.Skipped)) //nolint:forbidigo // CLI output
+			continue
+		}
+
 		if !result.Exists {
 			fmt.Println("   " + common.Yellow("Skipped: repository does not exist")) //nolint:forbidigo // CLI output
 			continue
 		}
 
 		for _, change := range result.Changes {
-			arrow := common.Yellow("→ ")
-			if reflect.DeepEqual(change.Current, change.Desired) {
-				arrow = "="
+			if change.Removal {
+				removals++
 			}
-			fmt.Printf( //nolint:forbidigo // CLI output
-				"   %s: %v %s %v\n",
-				common.Cyan(change.Field),
-				change.Current,
-				arrow,
-				change.Desired,
-			)
+			fmt.Println("   " + common.FormatChange(change)) //nolint:forbidigo // CLI output
+			printDiff(change.Diff)
 		}
 	}
 
 	fmt.Println("\n" + common.BoldWhite(common.Repeat("=", common.SeparatorWidth))) //nolint:forbidigo // CLI output
+	if removals > 0 {
+		message := fmt.Sprintf("%d removal(s) planned; review them before applying", removals)
+		fmt.Println(common.Red(message)) //nolint:forbidigo // CLI output
+	}
+}
+
+// printDiff prints a unified diff below its change, coloring added and
+// removed lines.
+func printDiff(diff string) {
+	for line := range strings.Lines(diff) {
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+			line = common.BoldWhite(line)
+		case strings.HasPrefix(line, "+"):
+			line = common.Green(line)
+		case strings.HasPrefix(line, "-"):
+			line = common.Red(line)
+		case strings.HasPrefix(line, "@@"):
+			line = common.Cyan(line)
+		}
+		fmt.Println("      " + line) //nolint:forbidigo // CLI output
+	}
 }
@@ -8,6 +8,7 @@ import (
 	ufcli "github.com/urfave/cli/v3"
 
 	"github.com/mholtzscher/github-janitor/cmd/common"
+	importcmd "github.com/mholtzscher/github-janitor/cmd/import"
 	initcmd "github.com/mholtzscher/github-janitor/cmd/init"
 	"github.com/mholtzscher/github-janitor/cmd/plan"
 	"github.com/mholtzscher/github-janitor/cmd/sync"
@@ -21,6 +22,9 @@ var Version = "0.1.3" //nolint:gochecknoglobals // x-release-please-version
 
 // Run is the entry point for the CLI.
 func Run(ctx context.Context, args []string) error {
+	cancel := context.CancelFunc(func() {})
+	defer func() { cancel() }()
+
 	app := &ufcli.Command{
 		Name:    "github-janitor",
 		Usage:   "Synchronize GitHub repository settings across multiple repos",
@@ -29,6 +33,9 @@ func Run(ctx context.Context, args []string) error {
 			if cmd.Bool(common.FlagNoColor) {
 				color.NoColor = true //nolint:reassign // Setting color.NoColor is the intended way to disable colors
 			}
+			if timeout := cmd.Duration(common.FlagTimeout); timeout > 0 {
+				ctx, cancel = context.WithTimeout(ctx, timeout)
+			}
 			return ctx, nil
 		},
 		Flags: []ufcli.Flag{
@@ -49,12 +56,32 @@ func Run(ctx context.Context, args []string) error {
 				Usage:   "GitHub personal access token (overrides auto-detection)",
 				Sources: ufcli.EnvVars(github.EnvToken),
 			},
+			&ufcli.StringFlag{
+				Name:    common.FlagAPIURL,
+				Usage:   "GitHub Enterprise Server API URL (overrides 'server' in the config file)",
+				Sources: ufcli.EnvVars(github.EnvAPIURL),
+			},
+			&ufcli.Int64Flag{
+				Name:    common.FlagAppID,
+				Usage:   "Authenticate as the GitHub App with this ID (requires --app-private-key)",
+				Sources: ufcli.EnvVars(github.EnvAppID),
+			},
+			&ufcli.StringFlag{
+				Name:    common.FlagAppPrivateKey,
+				Usage:   "Path to the GitHub App private key (PEM)",
+				Sources: ufcli.EnvVars(github.EnvAppPrivateKeyPath),
+			},
+			&ufcli.DurationFlag{
+				Name:  common.FlagTimeout,
+				Usage: "Stop the run after this long (e.g. 10m); 0 means no limit",
+			},
 		},
 		Commands: []*ufcli.Command{
 			sync.NewCommand(),
 			plan.NewCommand(),
 			validate.NewCommand(),
 			initcmd.NewCommand(),
+			importcmd.NewCommand(),
 		},
 	}
 
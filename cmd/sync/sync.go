@@ -4,13 +4,13 @@ package sync
 import (
 	"context"
 	"fmt"
-	"reflect"
+	"os"
 
 	ufcli "github.com/urfave/cli/v3"
 
 	"github.com/mholtzscher/github-janitor/cmd/common"
 	"github.com/mholtzscher/github-janitor/internal/config"
-	"github.com/mholtzscher/github-janitor/internal/github"
+	"github.com/mholtzscher/github-janitor/internal/report"
 	"github.com/mholtzscher/github-janitor/internal/sync"
 )
 
@@ -19,21 +19,33 @@ func NewCommand() *ufcli.Command {
 	return &ufcli.Command{
 		Name:  "sync",
 		Usage: "Apply settings to all configured repositories",
-		Flags: []ufcli.Flag{
+		Flags: append([]ufcli.Flag{
 			&ufcli.BoolFlag{
 				Name:  common.FlagDryRun,
 				Usage: "Preview changes without applying them",
 			},
-		},
-		Action: func(_ context.Context, cmd *ufcli.Command) error {
-			return runSync(cmd, cmd.Bool(common.FlagDryRun))
+			&ufcli.StringFlag{
+				Name:  common.FlagPlan,
+				Usage: "Apply a plan saved with 'plan --out' instead of the configuration file",
+			},
+			common.OutputFlag(),
+		}, common.SyncFlags()...),
+		Action: func(ctx context.Context, cmd *ufcli.Command) error {
+			if planPath := cmd.String(common.FlagPlan); planPath != "" {
+				if cmd.Bool(common.FlagDryRun) {
+					return fmt.Errorf("--%s cannot be combined with --%s", common.FlagPlan, common.FlagDryRun)
+				}
+				return runApplyPlan(ctx, cmd, planPath)
+			}
+			return runSync(ctx, cmd, cmd.Bool(common.FlagDryRun))
 		},
 	}
 }
 
-func runSync(cmd *ufcli.Command, dryRun bool) error {
+func runSync(ctx context.Context, cmd *ufcli.Command, dryRun bool) error {
 	configPath := cmd.String(common.FlagConfig)
-	token := cmd.String(common.FlagToken)
+	format := cmd.String(common.FlagOutput)
+	info := common.InfoWriter(format)
 
 	// Load configuration
 	cfg, err := config.Load(configPath)
@@ -42,28 +54,35 @@ func runSync(cmd *ufcli.Command, dryRun bool) error {
 	}
 
 	// Create GitHub client
-	client, err := github.NewClient(token)
+	client, err := common.NewClient(ctx, cmd, cfg)
 	if err != nil {
 		return fmt.Errorf("failed to create GitHub client: %w", err)
 	}
 
 	// Validate authentication
-	if authErr := client.ValidateAuth(); authErr != nil {
+	if authErr := client.ValidateAuth(ctx); authErr != nil {
 		return authErr
 	}
 
-	user, err := client.GetAuthenticatedUser()
+	user, err := client.GetAuthenticatedUser(ctx)
 	if err != nil {
 		return err
 	}
-	fmt.Printf( //nolint:forbidigo // CLI output
+	fmt.Fprintf(
+		info,
 		"Authenticated as: %s (token from: %s)\n\n",
 		common.Cyan(user),
 		common.Cyan(client.TokenSource),
 	)
 
 	// Create syncer
-	syncer := sync.NewSyncer(client, cfg)
+	syncer := sync.NewSyncer(client, cfg, common.SyncOptions(cmd))
+
+	// Expand discovery sources into repositories
+	sources, err := syncer.DiscoverRepositories(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to discover repositories: %w", err)
+	}
 
 	mode := common.BoldWhite("APPLYING")
 	modeColor := common.Cyan
@@ -71,19 +90,87 @@ func runSync(cmd *ufcli.Command, dryRun bool) error {
 		mode = common.Yellow("DRY-RUN (preview only)")
 		modeColor = common.Yellow
 	}
-	fmt.Printf("Mode: %s\n", mode)                                       //nolint:forbidigo // CLI output
-	fmt.Printf("Repositories: %s\n\n", modeColor(len(cfg.Repositories))) //nolint:forbidigo // CLI output
+	fmt.Fprintf(info, "Mode: %s\n", mode)
+	for _, src := range sources {
+		fmt.Fprintf(info, "Source %s: %s repositories\n", src.Source, modeColor(src.Count))
+	}
+	fmt.Fprintf(info, "Repositories: %s\n\n", modeColor(len(cfg.Repositories)))
 
 	// Execute sync
-	results, err := syncer.SyncAll(dryRun)
+	results, err := syncer.SyncAll(ctx, dryRun)
 	if err != nil {
 		return fmt.Errorf("sync failed: %w", err)
 	}
 
 	// Print results
-	printResults(results)
+	if format != report.FormatText {
+		if writeErr := report.Write(os.Stdout, format, report.New(results, dryRun)); writeErr != nil {
+			return writeErr
+		}
+	} else {
+		printResults(results)
+	}
+	common.PrintRateLimit(info, client)
+
+	return common.CheckInterrupted(ctx, info, results)
+}
+
+func runApplyPlan(ctx context.Context, cmd *ufcli.Command, planPath string) error {
+	format := cmd.String(common.FlagOutput)
+	info := common.InfoWriter(format)
+
+	// Load saved plan
+	plan, err := sync.ReadPlan(planPath)
+	if err != nil {
+		return err
+	}
+
+	// Create GitHub client for the server the plan was made against;
+	// --api-url may only name the same server.
+	client, err := common.NewClient(ctx, cmd, &config.Config{Server: plan.APIURL})
+	if err != nil {
+		return fmt.Errorf("failed to create GitHub client: %w", err)
+	}
+	if serverErr := plan.CheckAPIURL(client.APIURL()); serverErr != nil {
+		return serverErr
+	}
+
+	// Validate authentication
+	if authErr := client.ValidateAuth(ctx); authErr != nil {
+		return authErr
+	}
+
+	user, err := client.GetAuthenticatedUser(ctx)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(
+		info,
+		"Authenticated as: %s (token from: %s)\n\n",
+		common.Cyan(user),
+		common.Cyan(client.TokenSource),
+	)
+
+	// A saved plan carries everything it needs, so no configuration is loaded.
+	syncer := sync.NewSyncer(client, &config.Config{}, common.SyncOptions(cmd))
+
+	fmt.Fprintf(info, "Mode: %s\n", common.BoldWhite("APPLYING SAVED PLAN"))
+	fmt.Fprintf(info, "Plan: %s\n", common.Cyan(planPath))
+	fmt.Fprintf(info, "Repositories: %s\n\n", common.Cyan(len(plan.Repositories)))
+
+	results := syncer.ApplyPlan(ctx, plan)
+
+	// Print results
+	if format != report.FormatText {
+		if writeErr := report.Write(os.Stdout, format, report.New(results, false)); writeErr != nil {
+			return writeErr
+		}
+	} else {
+		printResults(results)
+	}
+	common.PrintRateLimit(info, client)
 
-	return nil
+	return common.CheckInterrupted(ctx, info, results)
 }
 
 func printResults(results []sync.Result) {
@@ -104,23 +191,18 @@ func printResults(results []sync.Result) {
 			continue
 		}
 
+		if result.Skipped != "" {
+			fmt.Println("   " + common.Yellow("Skipped: "+result.Skipped)) //nolint:forbidigo // CLI output
+			continue
+		}
+
 		if !result.Exists {
 			fmt.Println("   " + common.Yellow("Skipped: repository does not exist")) //nolint:forbidigo // CLI output
 			continue
 		}
 
 		for _, change := range result.Changes {
-			arrow := common.Yellow("→")
-			if reflect.DeepEqual(change.Current, change.Desired) {
-				arrow = "="
-			}
-			fmt.Printf( //nolint:forbidigo // CLI output
-				"   %s: %v %s %v\n",
-				common.Cyan(change.Field),
-				change.Current,
-				arrow,
-				change.Desired,
-			)
+			fmt.Println("   " + common.FormatChange(change)) //nolint:forbidigo // CLI output
 		}
 	}
 
@@ -9,7 +9,6 @@ import (
 
 	"github.com/mholtzscher/github-janitor/cmd/common"
 	"github.com/mholtzscher/github-janitor/internal/config"
-	"github.com/mholtzscher/github-janitor/internal/github"
 )
 
 // NewCommand creates the validate command.
@@ -17,15 +16,14 @@ func NewCommand() *ufcli.Command {
 	return &ufcli.Command{
 		Name:  "validate",
 		Usage: "Validate configuration file and authentication",
-		Action: func(_ context.Context, cmd *ufcli.Command) error {
-			return runValidate(cmd)
+		Action: func(ctx context.Context, cmd *ufcli.Command) error {
+			return runValidate(ctx, cmd)
 		},
 	}
 }
 
-func runValidate(cmd *ufcli.Command) error {
+func runValidate(ctx context.Context, cmd *ufcli.Command) error {
 	configPath := cmd.String(common.FlagConfig)
-	token := cmd.String(common.FlagToken)
 
 	fmt.Println(common.Cyan("Validating configuration...")) //nolint:forbidigo // CLI output
 
@@ -39,19 +37,25 @@ func runValidate(cmd *ufcli.Command) error {
 		"Configuration valid: %s repositories configured\n",
 		common.Green(len(cfg.Repositories)),
 	)
+	if len(cfg.Sources) > 0 {
+		fmt.Printf( //nolint:forbidigo // CLI output
+			"Discovery sources: %s (expanded when running plan or sync)\n",
+			common.Green(len(cfg.Sources)),
+		)
+	}
 
 	// Validate authentication
 	fmt.Println("\n" + common.Cyan("Validating GitHub authentication...")) //nolint:forbidigo // CLI output
-	client, err := github.NewClient(token)
+	client, err := common.NewClient(ctx, cmd, cfg)
 	if err != nil {
 		return fmt.Errorf("authentication error: %w", err)
 	}
 
-	if authErr := client.ValidateAuth(); authErr != nil {
+	if authErr := client.ValidateAuth(ctx); authErr != nil {
 		return authErr
 	}
 
-	user, err := client.GetAuthenticatedUser()
+	user, err := client.GetAuthenticatedUser(ctx)
 	if err != nil {
 		return err
 	}
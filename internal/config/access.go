@@ -0,0 +1,61 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Repository permissions. Custom repository roles are referred to by name.
+const (
+	PermissionPull     = "pull"
+	PermissionTriage   = "triage"
+	PermissionPush     = "push"
+	PermissionMaintain = "maintain"
+	PermissionAdmin    = "admin"
+
+	// PermissionNone removes an inherited grant.
+	PermissionNone = "none"
+)
+
+// Access declares who may access a repository. Each map, once set, lists
+// every grant of its kind: grants on the repository that are not listed are
+// removed. A map that is not set leaves grants of its kind alone.
+type Access struct {
+	// Teams maps team slugs in the repository's organization to a permission.
+	Teams map[string]string `yaml:"teams,omitempty"`
+
+	// Collaborators maps user logins to a permission.
+	Collaborators map[string]string `yaml:"collaborators,omitempty"`
+}
+
+// validate checks that every grant names a team or user and a permission.
+func (a *Access) validate() error {
+	if a == nil {
+		return nil
+	}
+	if err := validateGrants("teams", a.Teams); err != nil {
+		return err
+	}
+	return validateGrants("collaborators", a.Collaborators)
+}
+
+// validateGrants rejects empty names and permissions, and names that only
+// differ in case, since GitHub treats them as the same team or user.
+func validateGrants(key string, grants map[string]string) error {
+	seen := make(map[string]string, len(grants))
+	for _, name := range slices.Sorted(maps.Keys(grants)) {
+		if name == "" {
+			return fmt.Errorf("access.%s: name is required", key)
+		}
+		if previous, ok := seen[strings.ToLower(name)]; ok {
+			return fmt.Errorf("access.%s: %q and %q name the same grant", key, previous, name)
+		}
+		seen[strings.ToLower(name)] = name
+		if grants[name] == "" {
+			return fmt.Errorf("access.%s.%s: permission is required", key, name)
+		}
+	}
+	return nil
+}
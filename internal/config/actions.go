@@ -0,0 +1,71 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Allowed actions policies.
+const (
+	AllowedActionsAll       = "all"
+	AllowedActionsLocalOnly = "local_only"
+	AllowedActionsSelected  = "selected"
+)
+
+// Default GITHUB_TOKEN permissions.
+const (
+	WorkflowPermissionsRead  = "read"
+	WorkflowPermissionsWrite = "write"
+)
+
+// Actions is the GitHub Actions policy of a repository. Unset fields are left
+// as they are.
+type Actions struct {
+	// Enabled turns GitHub Actions on or off for the repository.
+	Enabled *bool `yaml:"enabled,omitempty"`
+
+	// AllowedActions is all, local_only or selected.
+	AllowedActions *string `yaml:"allowed_actions,omitempty"`
+
+	// SelectedActions lists the actions allowed by the selected policy. It
+	// is ignored by the other policies.
+	SelectedActions *SelectedActions `yaml:"selected_actions,omitempty"`
+
+	// DefaultWorkflowPermissions is the default GITHUB_TOKEN permission:
+	// read or write.
+	DefaultWorkflowPermissions *string `yaml:"default_workflow_permissions,omitempty"`
+
+	// CanApprovePullRequests allows workflows to approve pull requests.
+	CanApprovePullRequests *bool `yaml:"can_approve_pull_requests,omitempty"`
+}
+
+// SelectedActions lists the actions and reusable workflows allowed when
+// allowed_actions is selected, in addition to those in the repository.
+type SelectedActions struct {
+	GithubOwned bool     `yaml:"github_owned,omitempty"`
+	Verified    bool     `yaml:"verified,omitempty"`
+	Patterns    []string `yaml:"patterns,omitempty"`
+}
+
+// validate checks the allowed actions policy and the default token permission.
+func (a *Actions) validate() error {
+	if a == nil {
+		return nil
+	}
+	if a.AllowedActions != nil {
+		valid := []string{AllowedActionsAll, AllowedActionsLocalOnly, AllowedActionsSelected}
+		if !contains(valid, *a.AllowedActions) {
+			return fmt.Errorf("invalid actions.allowed_actions: must be one of %v", valid)
+		}
+		if isFalse(a.Enabled) {
+			return errors.New("actions: allowed_actions requires actions to be enabled")
+		}
+	}
+	if a.DefaultWorkflowPermissions != nil {
+		valid := []string{WorkflowPermissionsRead, WorkflowPermissionsWrite}
+		if !contains(valid, *a.DefaultWorkflowPermissions) {
+			return fmt.Errorf("invalid actions.default_workflow_permissions: must be one of %v", valid)
+		}
+	}
+	return nil
+}
@@ -1,9 +1,12 @@
 package config
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
+	"net/url"
 	"os"
+	"regexp"
 	"slices"
 
 	"gopkg.in/yaml.v3"
@@ -33,14 +36,36 @@ const (
 
 // Config represents the complete configuration file.
 type Config struct {
+	// Server is the API URL of a GitHub Enterprise Server instance, e.g.
+	// https://github.example.com/api/v3. Empty means github.com.
+	Server string `yaml:"server,omitempty"`
+
 	Repositories []Repository `yaml:"repositories"`
-	Settings     Settings     `yaml:"settings"`
+
+	// Sources are not expanded by Load: the repositories they discover are
+	// appended to Repositories by the syncer when plan or sync runs.
+	Sources  []Source           `yaml:"sources,omitempty"`
+	Include  []string           `yaml:"include,omitempty"`
+	Exclude  []Exclusion        `yaml:"exclude,omitempty"`
+	Settings Settings           `yaml:"settings"`
+	Profiles map[string]Profile `yaml:"profiles,omitempty"`
+
+	// selectors holds the compiled regex Include and Exclude patterns, keyed
+	// by pattern. Validate fills it in.
+	selectors map[string]*regexp.Regexp
 }
 
 // Repository represents a target repository.
 type Repository struct {
 	Owner string `yaml:"owner"`
 	Name  string `yaml:"name"`
+
+	// Profiles lists named profiles applied on top of the global settings, in order.
+	Profiles []string `yaml:"profiles,omitempty"`
+
+	// Settings optionally overrides the global settings for this repository only.
+	// Values set here are deep-merged over the global settings and any profiles.
+	Settings *Settings `yaml:"settings,omitempty"`
 }
 
 // FullName returns the full repository name (owner/name).
@@ -48,6 +73,31 @@ func (r Repository) FullName() string {
 	return fmt.Sprintf("%s/%s", r.Owner, r.Name)
 }
 
+// Source discovers repositories owned by an organization or user.
+// Omitted filters match every repository.
+type Source struct {
+	Org  string `yaml:"org,omitempty"`
+	User string `yaml:"user,omitempty"`
+
+	// Filters
+	Visibility *string  `yaml:"visibility,omitempty"`
+	Fork       *bool    `yaml:"fork,omitempty"`
+	Archived   *bool    `yaml:"archived,omitempty"`
+	Languages  []string `yaml:"languages,omitempty"`
+	Topics     []string `yaml:"topics,omitempty"`
+
+	// Profiles are applied to every repository discovered by this source.
+	Profiles []string `yaml:"profiles,omitempty"`
+}
+
+// String returns a short label for the source (e.g. "org:acme").
+func (s Source) String() string {
+	if s.Org != "" {
+		return "org:" + s.Org
+	}
+	return "user:" + s.User
+}
+
 // Settings represents the settings to apply to all repositories
 // Use pointers to distinguish between "not set" (nil) and "set to false".
 type Settings struct {
@@ -88,7 +138,89 @@ type Settings struct {
 	// GitHub Pages
 	GitHubPages *GitHubPages `yaml:"github_pages,omitempty"`
 
-	BranchProtection *BranchProtection `yaml:"branch_protection,omitempty"`
+	// BranchProtection lists classic branch protection rules, one per
+	// branch pattern. A single rule may also be written as a mapping.
+	// A wildcard pattern is applied to each branch matching it when sync
+	// runs, not created as a pattern rule on GitHub, so branches created
+	// later stay unprotected until the next sync.
+	BranchProtection BranchProtectionRules `yaml:"branch_protection,omitempty"`
+
+	// Rulesets are reconciled by name; a layer that sets rulesets replaces
+	// the inherited list.
+	Rulesets []Ruleset `yaml:"rulesets,omitempty"`
+
+	// Labels are reconciled by name; a layer that sets labels replaces the
+	// inherited list.
+	Labels []Label `yaml:"labels,omitempty"`
+
+	// UnmanagedLabels controls labels that are not configured: keep
+	// (default), report or delete. It only applies when labels are set.
+	UnmanagedLabels *string `yaml:"unmanaged_labels,omitempty"`
+
+	// Webhooks are reconciled by URL; a layer that sets webhooks replaces the
+	// inherited list.
+	Webhooks []Webhook `yaml:"webhooks,omitempty"`
+
+	// UnmanagedWebhooks controls webhooks that are not configured: keep
+	// (default), report or delete. It only applies when webhooks are set.
+	UnmanagedWebhooks *string `yaml:"unmanaged_webhooks,omitempty"`
+
+	// Access declares team and collaborator permissions. Grants are merged
+	// with inherited grants by team or login.
+	Access *Access `yaml:"access,omitempty"`
+
+	// Variables declares repository Actions variables by name. Variables are
+	// merged with inherited variables by name.
+	Variables map[string]string `yaml:"variables,omitempty"`
+
+	// Secrets declares repository Actions secrets by name, each read from an
+	// environment variable or a file. Secrets are merged with inherited
+	// secrets by name.
+	Secrets map[string]Secret `yaml:"secrets,omitempty"`
+
+	// Environments declares deployment environments by name. An environment
+	// set by a layer replaces the inherited environment of the same name.
+	Environments map[string]Environment `yaml:"environments,omitempty"`
+
+	// UnmanagedEnvironments controls environments that are not configured:
+	// keep (default), report or delete. With no environments set, report or
+	// delete applies to every environment.
+	UnmanagedEnvironments *string `yaml:"unmanaged_environments,omitempty"`
+
+	// DeployKeys are reconciled by title; a layer that sets deploy keys
+	// replaces the inherited list.
+	DeployKeys []DeployKey `yaml:"deploy_keys,omitempty"`
+
+	// UnmanagedDeployKeys controls deploy keys that are not configured: keep
+	// (default), report or delete. With no deploy keys set, report or delete
+	// applies to every deploy key.
+	UnmanagedDeployKeys *string `yaml:"unmanaged_deploy_keys,omitempty"`
+
+	// Security toggles security and analysis features.
+	Security *Security `yaml:"security,omitempty"`
+
+	// Actions is the GitHub Actions policy.
+	Actions *Actions `yaml:"actions,omitempty"`
+
+	// Files maps repository paths to local source files. Files are merged
+	// with inherited files by path.
+	Files map[string]File `yaml:"files,omitempty"`
+
+	// FilesMode controls how file changes are written: commit (default)
+	// straight to the default branch, or pull_request.
+	FilesMode *string `yaml:"files_mode,omitempty"`
+
+	// FilesAutoMerge enables auto-merge on file pull requests with the given
+	// merge method: merge, squash or rebase. It only applies to pull_request mode.
+	FilesAutoMerge *string `yaml:"files_auto_merge,omitempty"`
+}
+
+// Profile is a named, reusable settings block that repositories opt into.
+type Profile struct {
+	// Extends lists profiles applied before this one, in order.
+	Extends []string `yaml:"extends,omitempty"`
+
+	Settings `yaml:",inline"`
 }
 
 // GitHubPages represents GitHub Pages configuration.
@@ -98,7 +230,7 @@ type GitHubPages struct {
 
 // BranchProtection represents branch protection settings.
 type BranchProtection struct {
-	Enabled bool   `yaml:"enabled"`
+	Enabled *bool  `yaml:"enabled,omitempty"`
 	Pattern string `yaml:"pattern"`
 
 	RequiredReviews     *int  `yaml:"required_reviews,omitempty"`
@@ -120,6 +252,63 @@ type BranchProtection struct {
 	AllowDeletions                *bool `yaml:"allow_deletions,omitempty"`
 }
 
+// BranchProtectionRules is a list of branch protection rules keyed by pattern.
+type BranchProtectionRules []BranchProtection
+
+// UnmarshalYAML accepts either a list of rules or a single rule mapping.
+func (r *BranchProtectionRules) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.MappingNode {
+		var rule BranchProtection
+		if err := node.Decode(&rule); err != nil {
+			return err
+		}
+		*r = BranchProtectionRules{rule}
+		return nil
+	}
+
+	var rules []BranchProtection
+	if err := node.Decode(&rules); err != nil {
+		return err
+	}
+	*r = rules
+	return nil
+}
+
+// patterns returns the pattern of each rule, in order.
+func (r BranchProtectionRules) patterns() []string {
+	patterns := make([]string, 0, len(r))
+	for _, bp := range r {
+		patterns = append(patterns, bp.Pattern)
+	}
+	return patterns
+}
+
+// validate checks each rule and rejects duplicate patterns.
+func (r BranchProtectionRules) validate() error {
+	seen := make(map[string]bool, len(r))
+	for _, bp := range r {
+		if bp.IsEnabled() && bp.Pattern == "" {
+			return errors.New("branch_protection: pattern is required when enabled")
+		}
+		if seen[bp.Pattern] {
+			return fmt.Errorf("branch_protection: duplicate rule for pattern %q", bp.Pattern)
+		}
+		seen[bp.Pattern] = true
+		if bp.RequiredReviews != nil {
+			if *bp.RequiredReviews < 0 || *bp.RequiredReviews > 6 {
+				return errors.New("branch_protection: required_reviews must be between 0 and 6")
+			}
+		}
+	}
+	return nil
+}
+
+// IsEnabled reports whether branch protection is enabled.
+// An omitted enabled key is treated as disabled.
+func (bp *BranchProtection) IsEnabled() bool {
+	return bp != nil && bp.Enabled != nil && *bp.Enabled
+}
+
 // Load reads and parses the configuration file.
 func Load(path string) (*Config, error) {
 	data, err := os.ReadFile(path)
@@ -139,12 +328,32 @@ func Load(path string) (*Config, error) {
 	return &cfg, nil
 }
 
+// Marshal encodes the configuration as YAML.
+func (c *Config) Marshal() ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2) //nolint:mnd // Two-space indentation matches ExampleConfig
+	if err := enc.Encode(c); err != nil {
+		return nil, fmt.Errorf("failed to encode config: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encode config: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
 // Validate checks if the configuration is valid.
-func (c *Config) Validate() error { //nolint:gocognit // Validation logic is inherently branching
-	if len(c.Repositories) == 0 {
+func (c *Config) Validate() error {
+	if len(c.Repositories) == 0 && len(c.Sources) == 0 {
 		return errors.New("no repositories configured")
 	}
 
+	if c.Server != "" {
+		if u, err := url.Parse(c.Server); err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return fmt.Errorf("server: invalid URL %q: must be an absolute http(s) URL", c.Server)
+		}
+	}
+
 	for i, repo := range c.Repositories {
 		if repo.Owner == "" {
 			return fmt.Errorf("repository %d: owner is required", i)
@@ -154,51 +363,152 @@ func (c *Config) Validate() error { //nolint:gocognit // Validation logic is inh
 		}
 	}
 
-	if c.Settings.Visibility != nil && *c.Settings.Visibility != VisibilityPublic &&
-		*c.Settings.Visibility != VisibilityPrivate {
-		return errors.New("invalid visibility: must be 'public' or 'private'")
+	for i, src := range c.Sources {
+		if err := c.validateSource(src); err != nil {
+			return fmt.Errorf("source %d: %w", i, err)
+		}
 	}
 
-	if c.Settings.BranchProtection != nil {
-		bp := c.Settings.BranchProtection
-		if bp.Enabled && bp.Pattern == "" {
-			return errors.New("branch_protection: pattern is required when enabled")
-		}
-		if bp.RequiredReviews != nil {
-			if *bp.RequiredReviews < 0 || *bp.RequiredReviews > 6 {
-				return errors.New("branch_protection: required_reviews must be between 0 and 6")
+	if err := c.compileSelectors(); err != nil {
+		return err
+	}
+
+	if err := c.Settings.validate(); err != nil {
+		return err
+	}
+
+	if err := c.validateProfiles(); err != nil {
+		return err
+	}
+
+	for _, repo := range c.Repositories {
+		for _, name := range repo.Profiles {
+			if _, ok := c.Profiles[name]; !ok {
+				return fmt.Errorf("repository %s: unknown profile %q", repo.FullName(), name)
 			}
 		}
+		if repo.Settings == nil && len(repo.Profiles) == 0 {
+			continue
+		}
+		settings, _ := c.SettingsFor(repo)
+		if err := settings.validate(); err != nil {
+			return fmt.Errorf("repository %s: %w", repo.FullName(), err)
+		}
+	}
+
+	return nil
+}
+
+// validateSource checks a discovery source.
+func (c *Config) validateSource(src Source) error {
+	if (src.Org == "") == (src.User == "") {
+		return errors.New("exactly one of org or user is required")
+	}
+	if src.Visibility != nil && *src.Visibility != VisibilityPublic && *src.Visibility != VisibilityPrivate {
+		return errors.New("invalid visibility: must be 'public' or 'private'")
+	}
+	for _, name := range src.Profiles {
+		if _, ok := c.Profiles[name]; !ok {
+			return fmt.Errorf("unknown profile %q", name)
+		}
+	}
+	return nil
+}
+
+// validate checks if a settings block is valid.
+func (s *Settings) validate() error { //nolint:gocognit // Validation logic is inherently branching
+	if s.Visibility != nil && *s.Visibility != VisibilityPublic &&
+		*s.Visibility != VisibilityPrivate {
+		return errors.New("invalid visibility: must be 'public' or 'private'")
+	}
+
+	if err := s.BranchProtection.validate(); err != nil {
+		return err
+	}
+
+	if err := validateRulesets(s.Rulesets); err != nil {
+		return err
+	}
+
+	if err := validateLabels(s.Labels); err != nil {
+		return err
+	}
+	if err := validateUnmanaged("unmanaged_labels", s.UnmanagedLabels); err != nil {
+		return err
+	}
+
+	if err := validateWebhooks(s.Webhooks); err != nil {
+		return err
+	}
+	if err := validateUnmanaged("unmanaged_webhooks", s.UnmanagedWebhooks); err != nil {
+		return err
+	}
+
+	if err := s.Access.validate(); err != nil {
+		return err
+	}
+
+	if err := validateVariables(s.Variables); err != nil {
+		return err
+	}
+	if err := validateSecrets(s.Secrets); err != nil {
+		return err
+	}
+
+	if err := validateEnvironments(s.Environments); err != nil {
+		return err
+	}
+	if err := validateUnmanaged("unmanaged_environments", s.UnmanagedEnvironments); err != nil {
+		return err
+	}
+
+	if err := validateDeployKeys(s.DeployKeys); err != nil {
+		return err
+	}
+	if err := validateUnmanaged("unmanaged_deploy_keys", s.UnmanagedDeployKeys); err != nil {
+		return err
+	}
+
+	if err := s.Security.validate(); err != nil {
+		return err
+	}
+
+	if err := s.Actions.validate(); err != nil {
+		return err
+	}
+
+	if err := validateFiles(s.Files, s.FilesMode, s.FilesAutoMerge); err != nil {
+		return err
 	}
 
 	// Validate squash merge commit title
-	if c.Settings.SquashMergeCommitTitle != nil {
+	if s.SquashMergeCommitTitle != nil {
 		valid := []string{SquashTitlePRTitle, SquashTitleCommitOrPRTitle}
-		if !contains(valid, *c.Settings.SquashMergeCommitTitle) {
+		if !contains(valid, *s.SquashMergeCommitTitle) {
 			return fmt.Errorf("invalid squash_merge_commit_title: must be one of %v", valid)
 		}
 	}
 
 	// Validate squash merge commit message
-	if c.Settings.SquashMergeCommitMessage != nil {
+	if s.SquashMergeCommitMessage != nil {
 		valid := []string{SquashMessagePRBody, SquashMessageCommitMessages, SquashMessageBlank}
-		if !contains(valid, *c.Settings.SquashMergeCommitMessage) {
+		if !contains(valid, *s.SquashMergeCommitMessage) {
 			return fmt.Errorf("invalid squash_merge_commit_message: must be one of %v", valid)
 		}
 	}
 
 	// Validate merge commit title
-	if c.Settings.MergeCommitTitle != nil {
+	if s.MergeCommitTitle != nil {
 		valid := []string{MergeTitlePRTitle, MergeTitleMergeMessage}
-		if !contains(valid, *c.Settings.MergeCommitTitle) {
+		if !contains(valid, *s.MergeCommitTitle) {
 			return fmt.Errorf("invalid merge_commit_title: must be one of %v", valid)
 		}
 	}
 
 	// Validate merge commit message
-	if c.Settings.MergeCommitMessage != nil {
+	if s.MergeCommitMessage != nil {
 		valid := []string{MergeMessagePRBody, MergeMessagePRTitle, MergeMessageBlank}
-		if !contains(valid, *c.Settings.MergeCommitMessage) {
+		if !contains(valid, *s.MergeCommitMessage) {
 			return fmt.Errorf("invalid merge_commit_message: must be one of %v", valid)
 		}
 	}
@@ -213,11 +523,44 @@ func contains(slice []string, item string) bool {
 
 // ExampleConfig returns an example configuration as a string.
 func ExampleConfig() string {
-	return `repositories:
+	return `# GitHub Enterprise Server API URL (omit for github.com)
+# server: https://github.example.com/api/v3
+
+repositories:
   - owner: mholtzscher
     name: repo1
   - owner: mholtzscher
     name: repo2
+  - owner: mholtzscher
+    name: docs
+    # Profiles are applied in order on top of the global settings
+    profiles: [library]
+    # Per-repository overrides are deep-merged over the global settings and profiles
+    settings:
+      has_wiki: true
+      description: "Project documentation"
+
+# Discover repositories from an organization or user (filters are optional)
+# sources:
+#   - org: my-org
+#     visibility: public
+#     fork: false
+#     archived: false
+#     languages: ["Go"]
+#     topics: ["service"]
+#     profiles: [library]
+
+# Limit the repository set by name (globs, or regexes starting with ^)
+# include: ["repo*"]
+exclude:
+  - pattern: "repo2"
+    reason: "Archived upstream, kept for reference"
+
+# Named settings blocks that repositories opt into via "profiles"
+profiles:
+  library:
+    allow_auto_merge: true
+    topics: ["library"]
 
 settings:
   # Merge methods
@@ -260,22 +603,137 @@ settings:
   github_pages:
     enabled: false
 
-  # Branch protection (applied to all repos)
+  # Branch protection rules (applied to all repos), one per branch pattern
   branch_protection:
-    enabled: true
-    pattern: "main"
-    required_reviews: 1
-    require_status_checks: true
-    status_check_contexts: ["ci/test"]
-    dismiss_stale_reviews: true
-    # Enhanced protection settings
-    require_code_owner_reviews: false
-    require_branches_up_to_date: true
-    include_admins: false
-    require_linear_history: false
-    require_signed_commits: false
-    require_conversation_resolution: true
-    allow_force_pushes: false
-    allow_deletions: false
+    - enabled: true
+      pattern: "main"
+      required_reviews: 1
+      require_status_checks: true
+      status_check_contexts: ["ci/test"]
+      dismiss_stale_reviews: true
+      # Enhanced protection settings
+      require_code_owner_reviews: false
+      require_branches_up_to_date: true
+      include_admins: false
+      require_linear_history: false
+      require_signed_commits: false
+      require_conversation_resolution: true
+      allow_force_pushes: false
+      allow_deletions: false
+    - enabled: true
+      pattern: "release/*"
+      allow_deletions: false
+
+  # Repository rulesets, matched to existing rulesets by name
+  # rulesets:
+  #   - name: protect-main
+  #     target: branch            # branch, tag or push
+  #     enforcement: active       # active, evaluate or disabled
+  #     include: ["~DEFAULT_BRANCH"]
+  #     bypass_actors:
+  #       - actor_type: OrganizationAdmin
+  #         bypass_mode: always
+  #     rules:
+  #       deletion: true
+  #       non_fast_forward: true
+  #       pull_request:
+  #         required_approving_review_count: 1
+  #       required_status_checks:
+  #         contexts: ["ci/test"]
+  #       commit_message_pattern:
+  #         operator: regex
+  #         pattern: "^(feat|fix|chore|docs)"
+  #   - name: legacy
+  #     delete: true
+
+  # Issue labels, matched to existing labels by name (ignoring case)
+  # labels:
+  #   - name: bug
+  #     color: "d73a4a"
+  #     description: "Something isn't working"
+  #   - name: good first issue
+  #     color: "7057ff"
+  #     aliases: ["starter"]          # renamed in place, keeping assignments
+  # unmanaged_labels: keep            # keep, report or delete
+
+  # Webhooks, matched to existing webhooks by URL
+  # webhooks:
+  #   - url: https://ci.example.com/github
+  #     content_type: json              # json or form
+  #     events: ["push", "pull_request"]
+  #     active: true
+  #     secret_env: CI_WEBHOOK_SECRET   # environment variable holding the secret
+  # unmanaged_webhooks: report        # keep, report or delete
+
+  # Team and collaborator permissions: pull, triage, push, maintain, admin or
+  # a custom role. Unlisted grants of a listed kind are removed.
+  # access:
+  #   teams:
+  #     platform: admin
+  #     everyone: pull
+  #   collaborators:
+  #     octocat: push
+
+  # Actions variables and secrets. Secret values are read from an environment
+  # variable or a file when syncing and never stored in this file.
+  # variables:
+  #   DEPLOY_REGION: us-east-1
+  # secrets:
+  #   NPM_TOKEN:
+  #     env: NPM_TOKEN
+  #   SIGNING_KEY:
+  #     file: keys/signing.pem
+
+  # Deployment environments, matched to existing environments by name
+  # environments:
+  #   production:
+  #     wait_timer: 30                  # minutes
+  #     reviewers:
+  #       users: ["octocat"]
+  #       teams: ["release-managers"]
+  #     deployment_branch_policy:
+  #       protected_branches: true      # or custom_branches: ["main", "release/*"]
+  #     variables:
+  #       DEPLOY_URL: https://example.com
+  # unmanaged_environments: report    # keep, report or delete
+
+  # Deploy keys, matched to existing deploy keys by title
+  # deploy_keys:
+  #   - title: mirror
+  #     key_file: keys/mirror.pub       # OpenSSH public key
+  #   - title: release-bot
+  #     key_file: keys/release-bot.pub
+  #     read_only: false
+  # unmanaged_deploy_keys: report     # keep, report or delete
+
+  # Security and analysis features
+  # security:
+  #   dependabot_alerts: true
+  #   dependabot_security_updates: true
+  #   secret_scanning: true
+  #   secret_scanning_push_protection: true
+  #   private_vulnerability_reporting: true
+  #   code_scanning_default_setup: true
+
+  # GitHub Actions policy
+  # actions:
+  #   enabled: true
+  #   allowed_actions: selected        # all, local_only or selected
+  #   selected_actions:
+  #     github_owned: true
+  #     verified: false
+  #     patterns: ["docker/*"]
+  #   default_workflow_permissions: read   # read or write
+  #   can_approve_pull_requests: false
+
+  # Repository files kept in sync with local files
+  # files:
+  #   LICENSE:
+  #     source: files/LICENSE
+  #   .github/CODEOWNERS:
+  #     source: files/CODEOWNERS.tmpl
+  #     template: true                 # renders {{ .Owner }}, {{ .Name }}, {{ .DefaultBranch }}
+  # files_mode: pull_request           # commit (default) or pull_request
+  # files_auto_merge: squash           # merge, squash or rebase
 `
 }
@@ -0,0 +1,70 @@
+package config
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// DeployKey is a repository deploy key. Deploy keys are matched to the keys
+// that already exist on a repository by title.
+type DeployKey struct {
+	Title string `yaml:"title"`
+
+	// KeyFile is the path of the public key file, e.g. id_ed25519.pub,
+	// relative to the working directory.
+	KeyFile string `yaml:"key_file"`
+
+	// ReadOnly defaults to true; set it to false to grant write access.
+	ReadOnly *bool `yaml:"read_only,omitempty"`
+}
+
+// ReadOnlyOrDefault reports whether the key is read-only, defaulting to true.
+func (k DeployKey) ReadOnlyOrDefault() bool {
+	return k.ReadOnly == nil || *k.ReadOnly
+}
+
+// PublicKey reads the public key from KeyFile and returns its type and
+// base64 key material, without the trailing comment, as GitHub reports it.
+func (k DeployKey) PublicKey() (string, error) {
+	data, err := os.ReadFile(k.KeyFile)
+	if err != nil {
+		return "", fmt.Errorf("deploy key %s: failed to read key file: %w", k.Title, err)
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) < 2 || !isBase64(fields[1]) {
+		return "", fmt.Errorf("deploy key %s: %s is not an OpenSSH public key", k.Title, k.KeyFile)
+	}
+	return fields[0] + " " + fields[1], nil
+}
+
+// isBase64 reports whether s is standard base64, as OpenSSH key material is.
+func isBase64(s string) bool {
+	_, err := base64.StdEncoding.DecodeString(s)
+	return err == nil
+}
+
+// UnmanagedDeployKeysOrDefault returns the unmanaged deploy key mode,
+// defaulting to keep.
+func (s *Settings) UnmanagedDeployKeysOrDefault() string {
+	return unmanagedOrDefault(s.UnmanagedDeployKeys)
+}
+
+// validateDeployKeys checks that deploy keys have unique titles and a key file.
+func validateDeployKeys(keys []DeployKey) error {
+	seen := make(map[string]bool, len(keys))
+	for i, key := range keys {
+		if key.Title == "" {
+			return fmt.Errorf("deploy_keys %d: title is required", i)
+		}
+		if seen[key.Title] {
+			return fmt.Errorf("deploy_keys: duplicate deploy key title %q", key.Title)
+		}
+		seen[key.Title] = true
+		if key.KeyFile == "" {
+			return fmt.Errorf("deploy key %q: key_file is required", key.Title)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// Limits GitHub places on deployment environments.
+const (
+	MaxWaitTimer            = 43200
+	MaxEnvironmentReviewers = 6
+)
+
+// Environment is a deployment environment. Unset fields are left as they are
+// on an existing environment.
+type Environment struct {
+	// WaitTimer is the number of minutes a deployment waits before it
+	// proceeds, from 0 to 43200.
+	WaitTimer *int `yaml:"wait_timer,omitempty"`
+
+	// Reviewers lists the users and teams that must approve deployments.
+	Reviewers *EnvironmentReviewers `yaml:"reviewers,omitempty"`
+
+	// DeploymentBranchPolicy restricts which branches may deploy. Without
+	// one, any branch may deploy.
+	DeploymentBranchPolicy *DeploymentBranchPolicy `yaml:"deployment_branch_policy,omitempty"`
+
+	// Variables declares environment-scoped Actions variables by name.
+	Variables map[string]string `yaml:"variables,omitempty"`
+}
+
+// EnvironmentReviewers lists the required reviewers of an environment.
+type EnvironmentReviewers struct {
+	// Users are user logins.
+	Users []string `yaml:"users,omitempty"`
+
+	// Teams are team slugs in the repository's organization.
+	Teams []string `yaml:"teams,omitempty"`
+}
+
+// DeploymentBranchPolicy restricts deployments to protected branches or to
+// branches matching custom name patterns.
+type DeploymentBranchPolicy struct {
+	ProtectedBranches bool     `yaml:"protected_branches,omitempty"`
+	CustomBranches    []string `yaml:"custom_branches,omitempty"`
+}
+
+// UnmanagedEnvironmentsOrDefault returns the unmanaged environment mode,
+// defaulting to keep.
+func (s *Settings) UnmanagedEnvironmentsOrDefault() string {
+	return unmanagedOrDefault(s.UnmanagedEnvironments)
+}
+
+// validateEnvironments checks wait timers, reviewer counts, branch policies
+// and variable names of each environment.
+func validateEnvironments(environments map[string]Environment) error {
+	for _, name := range slices.Sorted(maps.Keys(environments)) {
+		env := environments[name]
+		if name == "" {
+			return errors.New("environments: name is required")
+		}
+		if env.WaitTimer != nil && (*env.WaitTimer < 0 || *env.WaitTimer > MaxWaitTimer) {
+			return fmt.Errorf("environment %q: wait_timer must be between 0 and %d minutes", name, MaxWaitTimer)
+		}
+		if env.Reviewers != nil && len(env.Reviewers.Users)+len(env.Reviewers.Teams) > MaxEnvironmentReviewers {
+			return fmt.Errorf("environment %q: at most %d reviewers are allowed", name, MaxEnvironmentReviewers)
+		}
+		if policy := env.DeploymentBranchPolicy; policy != nil {
+			if policy.ProtectedBranches == (len(policy.CustomBranches) > 0) {
+				return fmt.Errorf(
+					"environment %q: deployment_branch_policy needs exactly one of protected_branches or custom_branches",
+					name,
+				)
+			}
+		}
+		if err := validateVariables(env.Variables); err != nil {
+			return fmt.Errorf("environment %q: %w", name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+)
+
+// How file changes are written to a repository.
+const (
+	FilesModeCommit      = "commit"
+	FilesModePullRequest = "pull_request"
+)
+
+// Merge methods for auto-merging file pull requests.
+const (
+	AutoMergeMerge  = "merge"
+	AutoMergeSquash = "squash"
+	AutoMergeRebase = "rebase"
+)
+
+// File is a repository file kept in sync with a local source file.
+type File struct {
+	// Source is the path of the local file, relative to the working directory.
+	Source string `yaml:"source"`
+
+	// Template renders the source as a Go template with the repository's
+	// .Owner, .Name and .DefaultBranch.
+	Template bool `yaml:"template,omitempty"`
+}
+
+// FilesModeOrDefault returns how file changes are written, defaulting to
+// committing to the default branch.
+func (s *Settings) FilesModeOrDefault() string {
+	if s.FilesMode == nil {
+		return FilesModeCommit
+	}
+	return *s.FilesMode
+}
+
+// validateFiles checks that each file has a source and a clean relative
+// repository path, and that the files mode and auto-merge method are known.
+func validateFiles(files map[string]File, mode, autoMerge *string) error {
+	for _, name := range slices.Sorted(maps.Keys(files)) {
+		if name == "" || strings.HasPrefix(name, "/") || path.Clean(name) != name ||
+			name == ".." || strings.HasPrefix(name, "../") {
+			return fmt.Errorf("files: %q must be a clean path relative to the repository root", name)
+		}
+		if files[name].Source == "" {
+			return fmt.Errorf("file %q: source is required", name)
+		}
+	}
+	if mode != nil {
+		valid := []string{FilesModeCommit, FilesModePullRequest}
+		if !contains(valid, *mode) {
+			return fmt.Errorf("invalid files_mode: must be one of %v", valid)
+		}
+	}
+	if autoMerge != nil {
+		valid := []string{AutoMergeMerge, AutoMergeSquash, AutoMergeRebase}
+		if !contains(valid, *autoMerge) {
+			return fmt.Errorf("invalid files_auto_merge: must be one of %v", valid)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// labelColorPattern matches a label color without the leading '#'.
+var labelColorPattern = regexp.MustCompile(`^[0-9a-fA-F]{6}$`)
+
+// Label is an issue label. Labels are matched to the labels that already
+// exist on a repository by name, ignoring case.
+type Label struct {
+	Name string `yaml:"name"`
+
+	// Color is a hex color such as "d73a4a"; a leading '#' is allowed.
+	Color string `yaml:"color"`
+
+	Description string `yaml:"description,omitempty"`
+
+	// Aliases are former names of the label. An existing label with one of
+	// these names is renamed, keeping it on the issues it is applied to.
+	Aliases []string `yaml:"aliases,omitempty"`
+}
+
+// NormalizedColor returns the label color in the lowercase form GitHub
+// reports, without the leading '#'.
+func (l Label) NormalizedColor() string {
+	return strings.ToLower(strings.TrimPrefix(l.Color, "#"))
+}
+
+// UnmanagedLabelsOrDefault returns the unmanaged label mode, defaulting to keep.
+func (s *Settings) UnmanagedLabelsOrDefault() string {
+	return unmanagedOrDefault(s.UnmanagedLabels)
+}
+
+// validateLabels checks label names, colors and aliases. Names and aliases
+// must be unique across the list, ignoring case.
+func validateLabels(labels []Label) error {
+	seen := make(map[string]bool)
+	claim := func(name string) error {
+		key := strings.ToLower(name)
+		if seen[key] {
+			return fmt.Errorf("labels: duplicate label name or alias %q", name)
+		}
+		seen[key] = true
+		return nil
+	}
+
+	for i, label := range labels {
+		if label.Name == "" {
+			return fmt.Errorf("labels %d: name is required", i)
+		}
+		if err := claim(label.Name); err != nil {
+			return err
+		}
+		if !labelColorPattern.MatchString(strings.TrimPrefix(label.Color, "#")) {
+			return fmt.Errorf("label %q: invalid color %q: must be a 6-digit hex color", label.Name, label.Color)
+		}
+		for _, alias := range label.Aliases {
+			if alias == "" {
+				return fmt.Errorf("label %q: aliases must not be empty", label.Name)
+			}
+			if err := claim(alias); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,266 @@
+package config
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// Setting layers, in the order they are applied. Profile layers are named
+// LayerProfilePrefix followed by the profile name (see ProfileLayer).
+const (
+	LayerGlobal        = "global"
+	LayerProfilePrefix = "profile:"
+	LayerRepository    = "repository"
+)
+
+// Origins records which layer each resolved setting came from, keyed by its
+// dotted YAML path (e.g. "has_wiki"). Branch protection rules are keyed by
+// their pattern (e.g. "branch_protection.main.required_reviews") and map
+// entries by their key (e.g. "access.teams.platform").
+type Origins map[string]string
+
+// Of returns the layer that set the given path, or "" if no layer set it.
+func (o Origins) Of(path string) string {
+	return o[path]
+}
+
+// SettingsFor returns the effective settings for a repository: the global
+// settings, then each referenced profile in order, then the repository's own
+// settings block, each deep-merged over the previous layers.
+func (c *Config) SettingsFor(repo Repository) (Settings, Origins) {
+	origins := make(Origins)
+
+	var settings Settings
+	mergeSettings(&settings, &c.Settings, LayerGlobal, origins)
+	for _, name := range c.expandProfiles(repo.Profiles) {
+		profile := c.Profiles[name]
+		mergeSettings(&settings, &profile.Settings, ProfileLayer(name), origins)
+	}
+	if repo.Settings != nil {
+		mergeSettings(&settings, repo.Settings, LayerRepository, origins)
+	}
+
+	return settings, origins
+}
+
+// mergeSettings overlays every value set in src onto dst, recording layer as
+// the origin of each overlaid value. Nested blocks are merged field by field,
+// maps key by key, and branch protection rules are merged with the inherited
+// rule for the same pattern. Other lists replace the inherited list wholesale.
+func mergeSettings(dst, src *Settings, layer string, origins Origins) {
+	mergeStruct(reflect.ValueOf(dst).Elem(), reflect.ValueOf(src).Elem(), "", layer, origins)
+}
+
+func mergeStruct(dst, src reflect.Value, prefix, layer string, origins Origins) {
+	t := src.Type()
+	for i := range t.NumField() {
+		key := yamlKey(t.Field(i))
+		if key == "" {
+			continue
+		}
+		path := prefix + key
+
+		sf := src.Field(i)
+		df := dst.Field(i)
+		if sf.IsZero() {
+			continue
+		}
+
+		if rules, ok := sf.Interface().(BranchProtectionRules); ok {
+			inherited, _ := df.Interface().(BranchProtectionRules)
+			df.Set(reflect.ValueOf(mergeBranchProtection(inherited, rules, path, layer, origins)))
+			origins[path] = layer
+			continue
+		}
+
+		if sf.Kind() == reflect.Map {
+			// Merge maps key by key into a copy of the inherited map.
+			merged := reflect.MakeMap(sf.Type())
+			if !df.IsNil() {
+				for iter := df.MapRange(); iter.Next(); {
+					merged.SetMapIndex(iter.Key(), iter.Value())
+				}
+			}
+			for iter := sf.MapRange(); iter.Next(); {
+				merged.SetMapIndex(iter.Key(), iter.Value())
+				origins[path+"."+iter.Key().String()] = layer
+			}
+			df.Set(merged)
+			origins[path] = layer
+			continue
+		}
+
+		if sf.Kind() == reflect.Pointer && sf.Elem().Kind() == reflect.Struct {
+			// Copy the inherited block before merging so the lower layer is never mutated.
+			block := reflect.New(sf.Elem().Type())
+			if !df.IsNil() {
+				block.Elem().Set(df.Elem())
+			}
+			df.Set(block)
+			origins[path] = layer
+			mergeStruct(block.Elem(), sf.Elem(), path+".", layer, origins)
+			continue
+		}
+
+		df.Set(sf)
+		origins[path] = layer
+	}
+}
+
+// mergeBranchProtection merges each rule in src into the inherited rule with
+// the same pattern, appending rules for new patterns.
+func mergeBranchProtection(
+	dst, src BranchProtectionRules,
+	path, layer string,
+	origins Origins,
+) BranchProtectionRules {
+	// Copy the inherited rules before merging so the lower layer is never mutated.
+	merged := slices.Clone(dst)
+	for _, rule := range src {
+		i := slices.IndexFunc(merged, func(r BranchProtection) bool { return r.Pattern == rule.Pattern })
+		if i < 0 {
+			merged = append(merged, BranchProtection{})
+			i = len(merged) - 1
+		}
+		prefix := path + "." + rule.Pattern + "."
+		mergeStruct(reflect.ValueOf(&merged[i]).Elem(), reflect.ValueOf(&rule).Elem(), prefix, layer, origins)
+	}
+	return merged
+}
+
+// yamlKey returns the YAML key for a struct field, or "" if it is not serialized.
+func yamlKey(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+// FactorSettings moves every value shared by all repositories into a new
+// global settings block, leaving only the per-repository differences in each
+// repository's settings. It is the inverse of the merge done by SettingsFor.
+func FactorSettings(repos []Repository) Settings {
+	var global Settings
+	if len(repos) == 0 {
+		return global
+	}
+
+	blocks := make([]reflect.Value, 0, len(repos))
+	for i := range repos {
+		if repos[i].Settings == nil {
+			repos[i].Settings = &Settings{}
+		}
+		blocks = append(blocks, reflect.ValueOf(repos[i].Settings).Elem())
+	}
+	factorStruct(reflect.ValueOf(&global).Elem(), blocks)
+
+	for i := range repos {
+		if reflect.ValueOf(repos[i].Settings).Elem().IsZero() {
+			repos[i].Settings = nil
+		}
+	}
+
+	return global
+}
+
+func factorStruct(dst reflect.Value, blocks []reflect.Value) {
+	t := dst.Type()
+	for i := range t.NumField() {
+		if yamlKey(t.Field(i)) == "" {
+			continue
+		}
+
+		first := blocks[0].Field(i)
+		if first.IsZero() {
+			continue
+		}
+
+		if _, ok := first.Interface().(BranchProtectionRules); ok {
+			factorBranchProtection(dst.Field(i), blocks, i)
+			continue
+		}
+
+		if first.Kind() == reflect.Pointer && first.Elem().Kind() == reflect.Struct {
+			nested := make([]reflect.Value, 0, len(blocks))
+			for _, block := range blocks {
+				if block.Field(i).IsNil() {
+					break
+				}
+				nested = append(nested, block.Field(i).Elem())
+			}
+			if len(nested) != len(blocks) {
+				continue
+			}
+
+			shared := reflect.New(first.Elem().Type())
+			factorStruct(shared.Elem(), nested)
+			if !shared.Elem().IsZero() {
+				dst.Field(i).Set(shared)
+			}
+			for _, block := range blocks {
+				if block.Field(i).Elem().IsZero() {
+					block.Field(i).SetZero()
+				}
+			}
+			continue
+		}
+
+		shared := true
+		for _, block := range blocks[1:] {
+			if !reflect.DeepEqual(block.Field(i).Interface(), first.Interface()) {
+				shared = false
+				break
+			}
+		}
+		if !shared {
+			continue
+		}
+
+		dst.Field(i).Set(first)
+		for _, block := range blocks {
+			block.Field(i).SetZero()
+		}
+	}
+}
+
+// factorBranchProtection moves the values shared by every repository's branch
+// protection rules into dst. Rules are only factored when every repository
+// protects the same patterns in the same order; each repository keeps a rule
+// for a pattern only while it still differs from the shared rule.
+func factorBranchProtection(dst reflect.Value, blocks []reflect.Value, field int) {
+	lists := make([]BranchProtectionRules, 0, len(blocks))
+	for _, block := range blocks {
+		rules, _ := block.Field(field).Interface().(BranchProtectionRules)
+		lists = append(lists, rules)
+	}
+	patterns := lists[0].patterns()
+	for _, rules := range lists[1:] {
+		if !slices.Equal(rules.patterns(), patterns) {
+			return
+		}
+	}
+
+	shared := make(BranchProtectionRules, len(lists[0]))
+	for j := range shared {
+		nested := make([]reflect.Value, 0, len(lists))
+		for _, rules := range lists {
+			nested = append(nested, reflect.ValueOf(&rules[j]).Elem())
+		}
+		factorStruct(reflect.ValueOf(&shared[j]).Elem(), nested)
+		shared[j].Pattern = patterns[j]
+	}
+	dst.Set(reflect.ValueOf(shared))
+
+	for k, rules := range lists {
+		var remaining BranchProtectionRules
+		for j, rule := range rules {
+			if !reflect.ValueOf(rule).IsZero() {
+				rule.Pattern = patterns[j]
+				remaining = append(remaining, rule)
+			}
+		}
+		blocks[k].Field(field).Set(reflect.ValueOf(remaining))
+	}
+}
@@ -0,0 +1,94 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ProfileLayer returns the layer name used for settings that came from a profile.
+func ProfileLayer(name string) string {
+	return LayerProfilePrefix + name
+}
+
+// validateProfiles checks profile settings, extends references and cycles.
+func (c *Config) validateProfiles() error {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		profile := c.Profiles[name]
+		for _, parent := range profile.Extends {
+			if _, ok := c.Profiles[parent]; !ok {
+				return fmt.Errorf("profile %q: extends unknown profile %q", name, parent)
+			}
+		}
+		if err := profile.validate(); err != nil {
+			return fmt.Errorf("profile %q: %w", name, err)
+		}
+	}
+
+	// Depth-first search; a profile reached again while still on the stack is a cycle.
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make(map[string]int, len(c.Profiles))
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		path = append(path, name)
+		switch state[name] {
+		case visiting:
+			start := slices.Index(path, name)
+			return fmt.Errorf("profile cycle detected: %s", strings.Join(path[start:], " -> "))
+		case done:
+			return nil
+		}
+		state[name] = visiting
+		for _, parent := range c.Profiles[name].Extends {
+			if err := visit(parent, path); err != nil {
+				return err
+			}
+		}
+		state[name] = done
+		return nil
+	}
+
+	for _, name := range names {
+		if err := visit(name, nil); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// expandProfiles returns the profiles to apply for the given references, with
+// each profile's extends expanded before it. A profile is applied at most once.
+func (c *Config) expandProfiles(refs []string) []string {
+	var order []string
+	seen := make(map[string]bool)
+
+	var visit func(name string)
+	visit = func(name string) {
+		profile, ok := c.Profiles[name]
+		if !ok || seen[name] {
+			return
+		}
+		seen[name] = true
+		for _, parent := range profile.Extends {
+			visit(parent)
+		}
+		order = append(order, name)
+	}
+
+	for _, name := range refs {
+		visit(name)
+	}
+
+	return order
+}
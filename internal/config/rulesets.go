@@ -0,0 +1,213 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// Ruleset targets.
+const (
+	RulesetTargetBranch = "branch"
+	RulesetTargetTag    = "tag"
+	RulesetTargetPush   = "push"
+)
+
+// Ruleset enforcement levels.
+const (
+	EnforcementActive   = "active"
+	EnforcementEvaluate = "evaluate"
+	EnforcementDisabled = "disabled"
+)
+
+// Ruleset is a repository ruleset. Rulesets are matched to the rulesets that
+// already exist on a repository by name; rulesets not listed are left alone.
+type Ruleset struct {
+	Name string `yaml:"name"`
+
+	// Delete removes an existing ruleset with this name instead of managing it.
+	Delete bool `yaml:"delete,omitempty"`
+
+	// Target is what the ruleset applies to: branch (default), tag or push.
+	Target string `yaml:"target,omitempty"`
+
+	// Enforcement is active (default), evaluate or disabled.
+	Enforcement string `yaml:"enforcement,omitempty"`
+
+	// Include and Exclude select refs by pattern, e.g. "~DEFAULT_BRANCH",
+	// "~ALL" or "refs/heads/release/*". They do not apply to push rulesets.
+	Include []string `yaml:"include,omitempty"`
+	Exclude []string `yaml:"exclude,omitempty"`
+
+	BypassActors []BypassActor `yaml:"bypass_actors,omitempty"`
+	Rules        RulesetRules  `yaml:"rules,omitempty"`
+}
+
+// BypassActor may bypass a ruleset.
+type BypassActor struct {
+	// ActorType is Team, Integration, OrganizationAdmin, RepositoryRole or DeployKey.
+	ActorType string `yaml:"actor_type"`
+	ActorID   int64  `yaml:"actor_id,omitempty"`
+
+	// BypassMode is always (default) or pull_request.
+	BypassMode string `yaml:"bypass_mode,omitempty"`
+}
+
+// RulesetRules lists the rules enforced by a ruleset. Unset rules are not enforced.
+type RulesetRules struct {
+	Creation              bool `yaml:"creation,omitempty"`
+	Update                bool `yaml:"update,omitempty"`
+	Deletion              bool `yaml:"deletion,omitempty"`
+	RequiredLinearHistory bool `yaml:"required_linear_history,omitempty"`
+	RequiredSignatures    bool `yaml:"required_signatures,omitempty"`
+	NonFastForward        bool `yaml:"non_fast_forward,omitempty"`
+
+	PullRequest          *PullRequestRule  `yaml:"pull_request,omitempty"`
+	RequiredStatusChecks *StatusChecksRule `yaml:"required_status_checks,omitempty"`
+
+	// RequiredDeployments lists environments that must deploy successfully first.
+	RequiredDeployments []string `yaml:"required_deployments,omitempty"`
+
+	CommitMessagePattern     *PatternRule `yaml:"commit_message_pattern,omitempty"`
+	CommitAuthorEmailPattern *PatternRule `yaml:"commit_author_email_pattern,omitempty"`
+	CommitterEmailPattern    *PatternRule `yaml:"committer_email_pattern,omitempty"`
+	BranchNamePattern        *PatternRule `yaml:"branch_name_pattern,omitempty"`
+	TagNamePattern           *PatternRule `yaml:"tag_name_pattern,omitempty"`
+
+	// Push rules.
+	FilePathRestriction      []string `yaml:"file_path_restriction,omitempty"`
+	FileExtensionRestriction []string `yaml:"file_extension_restriction,omitempty"`
+	MaxFilePathLength        int      `yaml:"max_file_path_length,omitempty"`
+	MaxFileSize              int64    `yaml:"max_file_size,omitempty"`
+}
+
+// PullRequestRule requires changes to go through a pull request.
+type PullRequestRule struct {
+	RequiredApprovingReviewCount   int  `yaml:"required_approving_review_count,omitempty"`
+	DismissStaleReviewsOnPush      bool `yaml:"dismiss_stale_reviews_on_push,omitempty"`
+	RequireCodeOwnerReview         bool `yaml:"require_code_owner_review,omitempty"`
+	RequireLastPushApproval        bool `yaml:"require_last_push_approval,omitempty"`
+	RequiredReviewThreadResolution bool `yaml:"required_review_thread_resolution,omitempty"`
+}
+
+// StatusChecksRule requires status checks to pass.
+type StatusChecksRule struct {
+	Contexts []string `yaml:"contexts"`
+
+	// Strict requires branches to be up to date before merging.
+	Strict bool `yaml:"strict,omitempty"`
+}
+
+// PatternRule requires a commit or ref attribute to match a pattern.
+type PatternRule struct {
+	Name string `yaml:"name,omitempty"`
+
+	// Operator is starts_with, ends_with, contains or regex.
+	Operator string `yaml:"operator"`
+	Pattern  string `yaml:"pattern"`
+	Negate   bool   `yaml:"negate,omitempty"`
+}
+
+// TargetOrDefault returns the ruleset target, defaulting to branch.
+func (r Ruleset) TargetOrDefault() string {
+	if r.Target == "" {
+		return RulesetTargetBranch
+	}
+	return r.Target
+}
+
+// EnforcementOrDefault returns the enforcement level, defaulting to active.
+func (r Ruleset) EnforcementOrDefault() string {
+	if r.Enforcement == "" {
+		return EnforcementActive
+	}
+	return r.Enforcement
+}
+
+// validateRulesets checks ruleset names, targets and rule parameters.
+func validateRulesets(rulesets []Ruleset) error {
+	seen := make(map[string]bool, len(rulesets))
+	for i, rs := range rulesets {
+		if rs.Name == "" {
+			return fmt.Errorf("rulesets %d: name is required", i)
+		}
+		if seen[rs.Name] {
+			return fmt.Errorf("rulesets: duplicate ruleset name %q", rs.Name)
+		}
+		seen[rs.Name] = true
+
+		if err := rs.validate(); err != nil {
+			return fmt.Errorf("ruleset %q: %w", rs.Name, err)
+		}
+	}
+	return nil
+}
+
+func (r Ruleset) validate() error { //nolint:gocognit // Validation logic is inherently branching
+	if r.Delete {
+		return nil
+	}
+
+	targets := []string{RulesetTargetBranch, RulesetTargetTag, RulesetTargetPush}
+	if !slices.Contains(targets, r.TargetOrDefault()) {
+		return fmt.Errorf("invalid target %q: must be one of %v", r.Target, targets)
+	}
+	enforcements := []string{EnforcementActive, EnforcementEvaluate, EnforcementDisabled}
+	if !slices.Contains(enforcements, r.EnforcementOrDefault()) {
+		return fmt.Errorf("invalid enforcement %q: must be one of %v", r.Enforcement, enforcements)
+	}
+
+	if r.TargetOrDefault() == RulesetTargetPush {
+		if len(r.Include) > 0 || len(r.Exclude) > 0 {
+			return errors.New("include and exclude do not apply to push rulesets")
+		}
+	} else if len(r.Include) == 0 {
+		return errors.New("include is required (e.g. [\"~DEFAULT_BRANCH\"])")
+	}
+
+	for _, actor := range r.BypassActors {
+		types := []string{"Team", "Integration", "OrganizationAdmin", "RepositoryRole", "DeployKey"}
+		if !slices.Contains(types, actor.ActorType) {
+			return fmt.Errorf("bypass_actors: invalid actor_type %q: must be one of %v", actor.ActorType, types)
+		}
+		if actor.BypassMode != "" && actor.BypassMode != "always" && actor.BypassMode != "pull_request" {
+			return fmt.Errorf("bypass_actors: invalid bypass_mode %q: must be 'always' or 'pull_request'", actor.BypassMode)
+		}
+	}
+
+	rules := r.Rules
+	if rules.PullRequest != nil {
+		if n := rules.PullRequest.RequiredApprovingReviewCount; n < 0 || n > 10 {
+			return errors.New("pull_request: required_approving_review_count must be between 0 and 10")
+		}
+	}
+	if rules.RequiredStatusChecks != nil && len(rules.RequiredStatusChecks.Contexts) == 0 {
+		return errors.New("required_status_checks: contexts is required")
+	}
+
+	patterns := map[string]*PatternRule{
+		"commit_message_pattern":      rules.CommitMessagePattern,
+		"commit_author_email_pattern": rules.CommitAuthorEmailPattern,
+		"committer_email_pattern":     rules.CommitterEmailPattern,
+		"branch_name_pattern":         rules.BranchNamePattern,
+		"tag_name_pattern":            rules.TagNamePattern,
+	}
+	for key, pattern := range patterns {
+		if pattern == nil {
+			continue
+		}
+		operators := []string{"starts_with", "ends_with", "contains", "regex"}
+		if !slices.Contains(operators, pattern.Operator) {
+			return fmt.Errorf("%s: invalid operator %q: must be one of %v", key, pattern.Operator, operators)
+		}
+		if pattern.Pattern == "" {
+			return fmt.Errorf("%s: pattern is required", key)
+		}
+	}
+
+	if rules.MaxFilePathLength < 0 || rules.MaxFileSize < 0 {
+		return errors.New("max_file_path_length and max_file_size must not be negative")
+	}
+
+	return nil
+}
@@ -0,0 +1,87 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// actionsNamePattern matches valid Actions variable and secret names.
+var actionsNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Secret is a repository Actions secret. Its value is read from an
+// environment variable or a local file and never appears in the configuration.
+type Secret struct {
+	// Env names the environment variable holding the secret value.
+	Env string `yaml:"env,omitempty"`
+
+	// File is the path of a file holding the secret value, relative to the
+	// working directory. A single trailing newline is removed.
+	File string `yaml:"file,omitempty"`
+}
+
+// Value reads the secret value from its environment variable or file.
+func (s Secret) Value() (string, error) {
+	if s.File != "" {
+		data, err := os.ReadFile(s.File)
+		if err != nil {
+			return "", fmt.Errorf("failed to read secret file: %w", err)
+		}
+		return strings.TrimSuffix(strings.TrimSuffix(string(data), "\n"), "\r"), nil
+	}
+	value, ok := os.LookupEnv(s.Env)
+	if !ok || value == "" {
+		return "", fmt.Errorf("environment variable %s is not set", s.Env)
+	}
+	return value, nil
+}
+
+// validateActionsName checks an Actions variable or secret name.
+func validateActionsName(kind, name string) error {
+	if !actionsNamePattern.MatchString(name) {
+		return fmt.Errorf("%s %q: names may only contain letters, digits and underscores", kind, name)
+	}
+	if strings.HasPrefix(strings.ToUpper(name), "GITHUB_") {
+		return fmt.Errorf("%s %q: names must not start with GITHUB_", kind, name)
+	}
+	return nil
+}
+
+// validateVariables checks Actions variable names. Names are case-insensitive.
+func validateVariables(variables map[string]string) error {
+	seen := make(map[string]bool, len(variables))
+	for _, name := range slices.Sorted(maps.Keys(variables)) {
+		if err := validateActionsName("variable", name); err != nil {
+			return err
+		}
+		if seen[strings.ToUpper(name)] {
+			return fmt.Errorf("variables: duplicate variable %q", name)
+		}
+		seen[strings.ToUpper(name)] = true
+	}
+	return nil
+}
+
+// validateSecrets checks Actions secret names and that each secret has
+// exactly one value source.
+func validateSecrets(secrets map[string]Secret) error {
+	seen := make(map[string]bool, len(secrets))
+	for _, name := range slices.Sorted(maps.Keys(secrets)) {
+		secret := secrets[name]
+		if err := validateActionsName("secret", name); err != nil {
+			return err
+		}
+		if seen[strings.ToUpper(name)] {
+			return fmt.Errorf("secrets: duplicate secret %q", name)
+		}
+		seen[strings.ToUpper(name)] = true
+
+		if (secret.Env == "") == (secret.File == "") {
+			return fmt.Errorf("secret %q: exactly one of env or file is required", name)
+		}
+	}
+	return nil
+}
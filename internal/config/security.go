@@ -0,0 +1,34 @@
+package config
+
+import "errors"
+
+// Security toggles repository security features. Unset features are left as
+// they are.
+type Security struct {
+	DependabotAlerts              *bool `yaml:"dependabot_alerts,omitempty"`
+	DependabotSecurityUpdates     *bool `yaml:"dependabot_security_updates,omitempty"`
+	SecretScanning                *bool `yaml:"secret_scanning,omitempty"`
+	SecretScanningPushProtection  *bool `yaml:"secret_scanning_push_protection,omitempty"`
+	PrivateVulnerabilityReporting *bool `yaml:"private_vulnerability_reporting,omitempty"`
+	CodeScanningDefaultSetup      *bool `yaml:"code_scanning_default_setup,omitempty"`
+}
+
+// validate rejects features enabled on top of a feature they depend on being disabled.
+func (s *Security) validate() error {
+	if s == nil {
+		return nil
+	}
+	if isTrue(s.DependabotSecurityUpdates) && isFalse(s.DependabotAlerts) {
+		return errors.New("security: dependabot_security_updates requires dependabot_alerts")
+	}
+	if isTrue(s.SecretScanningPushProtection) && isFalse(s.SecretScanning) {
+		return errors.New("security: secret_scanning_push_protection requires secret_scanning")
+	}
+	return nil
+}
+
+// isTrue reports whether b is set to true.
+func isTrue(b *bool) bool { return b != nil && *b }
+
+// isFalse reports whether b is set to false.
+func isFalse(b *bool) bool { return b != nil && !*b }
@@ -0,0 +1,113 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Exclusion skips repositories matching Pattern, recording why they were skipped.
+type Exclusion struct {
+	Pattern string `yaml:"pattern"`
+	Reason  string `yaml:"reason,omitempty"`
+}
+
+// Included reports whether a repository matches the include patterns.
+// Every repository is included when no include patterns are configured.
+// The configuration must have been validated first.
+func (c *Config) Included(repo Repository) bool {
+	if len(c.Include) == 0 {
+		return true
+	}
+	for _, pattern := range c.Include {
+		if c.matchPattern(pattern, repo) {
+			return true
+		}
+	}
+	return false
+}
+
+// Excluded returns the first exclusion matching a repository, or nil.
+// The configuration must have been validated first.
+func (c *Config) Excluded(repo Repository) *Exclusion {
+	for i := range c.Exclude {
+		if c.matchPattern(c.Exclude[i].Pattern, repo) {
+			return &c.Exclude[i]
+		}
+	}
+	return nil
+}
+
+// matchPattern matches a repository against a selector pattern.
+//
+// Patterns starting with ^ are regular expressions; anything else is a glob
+// (see path.Match). Patterns containing a slash are matched against the full
+// owner/name, all others against the repository name alone. Regular
+// expressions are compiled once by Validate.
+func (c *Config) matchPattern(pattern string, repo Repository) bool {
+	target := repo.Name
+	if strings.Contains(pattern, "/") {
+		target = repo.FullName()
+	}
+
+	if strings.HasPrefix(pattern, "^") {
+		re, ok := c.selectors[pattern]
+		if !ok {
+			panic(fmt.Sprintf("config: selector %q used before Validate", pattern))
+		}
+		return re.MatchString(target)
+	}
+
+	// Validate has already rejected malformed globs.
+	matched, _ := path.Match(pattern, target)
+	return matched
+}
+
+// compileSelectors validates the include and exclude patterns and stores the
+// compiled regular expressions for matchPattern.
+func (c *Config) compileSelectors() error {
+	c.selectors = make(map[string]*regexp.Regexp)
+	for i, pattern := range c.Include {
+		if err := c.compileSelector(pattern); err != nil {
+			return fmt.Errorf("include %d: %w", i, err)
+		}
+	}
+	for i, exclusion := range c.Exclude {
+		if err := c.compileSelector(exclusion.Pattern); err != nil {
+			return fmt.Errorf("exclude %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func (c *Config) compileSelector(pattern string) error {
+	re, err := validatePattern(pattern)
+	if err != nil {
+		return err
+	}
+	if re != nil {
+		c.selectors[pattern] = re
+	}
+	return nil
+}
+
+// validatePattern checks that a selector pattern is non-empty and compiles.
+// It returns the compiled expression for regex patterns and nil for globs.
+func validatePattern(pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, errors.New("pattern is required")
+	}
+	if strings.HasPrefix(pattern, "^") {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex %q: %w", pattern, err)
+		}
+		return re, nil
+	}
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, fmt.Errorf("invalid glob %q: %w", pattern, err)
+	}
+	return nil, nil //nolint:nilnil // Globs have nothing to compile
+}
@@ -0,0 +1,31 @@
+package config
+
+import "fmt"
+
+// Modes for sub-resources, such as labels or webhooks, that exist on a
+// repository but are not configured.
+const (
+	UnmanagedKeep   = "keep"
+	UnmanagedReport = "report"
+	UnmanagedDelete = "delete"
+)
+
+// unmanagedOrDefault returns the configured unmanaged mode, defaulting to keep.
+func unmanagedOrDefault(mode *string) string {
+	if mode == nil {
+		return UnmanagedKeep
+	}
+	return *mode
+}
+
+// validateUnmanaged checks an unmanaged mode setting named key.
+func validateUnmanaged(key string, mode *string) error {
+	if mode == nil {
+		return nil
+	}
+	valid := []string{UnmanagedKeep, UnmanagedReport, UnmanagedDelete}
+	if !contains(valid, *mode) {
+		return fmt.Errorf("invalid %s: must be one of %v", key, valid)
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+)
+
+// Webhook payload content types.
+const (
+	ContentTypeJSON = "json"
+	ContentTypeForm = "form"
+)
+
+// Webhook is a repository webhook. Webhooks are matched to the webhooks that
+// already exist on a repository by URL.
+type Webhook struct {
+	URL string `yaml:"url"`
+
+	// ContentType is json (default) or form.
+	ContentType string `yaml:"content_type,omitempty"`
+
+	// Events lists the events that trigger the webhook; defaults to ["push"].
+	Events []string `yaml:"events,omitempty"`
+
+	// Active defaults to true.
+	Active *bool `yaml:"active,omitempty"`
+
+	// SecretEnv names the environment variable holding the webhook secret.
+	// The secret itself never appears in the configuration or in output.
+	SecretEnv string `yaml:"secret_env,omitempty"`
+}
+
+// ContentTypeOrDefault returns the payload content type, defaulting to json.
+func (w Webhook) ContentTypeOrDefault() string {
+	if w.ContentType == "" {
+		return ContentTypeJSON
+	}
+	return w.ContentType
+}
+
+// EventsOrDefault returns the webhook events, defaulting to push.
+func (w Webhook) EventsOrDefault() []string {
+	if len(w.Events) == 0 {
+		return []string{"push"}
+	}
+	return w.Events
+}
+
+// ActiveOrDefault reports whether the webhook is active, defaulting to true.
+func (w Webhook) ActiveOrDefault() bool {
+	return w.Active == nil || *w.Active
+}
+
+// Secret reads the webhook secret from the environment variable named by
+// SecretEnv. It returns "" if the webhook has no secret.
+func (w Webhook) Secret() (string, error) {
+	if w.SecretEnv == "" {
+		return "", nil
+	}
+	secret, ok := os.LookupEnv(w.SecretEnv)
+	if !ok || secret == "" {
+		return "", fmt.Errorf("webhook %s: environment variable %s is not set", w.URL, w.SecretEnv)
+	}
+	return secret, nil
+}
+
+// UnmanagedWebhooksOrDefault returns the unmanaged webhook mode, defaulting to keep.
+func (s *Settings) UnmanagedWebhooksOrDefault() string {
+	return unmanagedOrDefault(s.UnmanagedWebhooks)
+}
+
+// validateWebhooks checks webhook URLs and content types.
+func validateWebhooks(webhooks []Webhook) error {
+	seen := make(map[string]bool, len(webhooks))
+	for i, hook := range webhooks {
+		if hook.URL == "" {
+			return fmt.Errorf("webhooks %d: url is required", i)
+		}
+		if seen[hook.URL] {
+			return fmt.Errorf("webhooks: duplicate webhook url %q", hook.URL)
+		}
+		seen[hook.URL] = true
+
+		u, err := url.Parse(hook.URL)
+		if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return fmt.Errorf("webhook %q: url must be an absolute http or https URL", hook.URL)
+		}
+		if u.User != nil {
+			return errors.New("webhook url must not contain credentials; use secret_env for the secret")
+		}
+		if hook.ContentTypeOrDefault() != ContentTypeJSON && hook.ContentTypeOrDefault() != ContentTypeForm {
+			return fmt.Errorf("webhook %q: invalid content_type %q: must be 'json' or 'form'", hook.URL, hook.ContentType)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/v82/github"
+)
+
+// AccessGrant is the permission a team or user holds on a repository.
+type AccessGrant struct {
+	// Name is the team slug or user login.
+	Name       string `json:"name"`
+	Permission string `json:"permission"`
+
+	// InvitationID identifies a collaborator invitation that has not been
+	// accepted yet; it is zero otherwise.
+	InvitationID int64 `json:"invitation_id,omitempty"`
+}
+
+// NormalizePermission maps the role names GitHub reports for the built-in
+// roles ("read", "write") onto the permission names used to grant them
+// ("pull", "push"). Other permissions, including custom roles, are unchanged.
+func NormalizePermission(permission string) string {
+	switch permission {
+	case "read":
+		return "pull"
+	case "write":
+		return "push"
+	}
+	return permission
+}
+
+// ListTeamAccess lists the teams with access to a repository.
+func (c *Client) ListTeamAccess(ctx context.Context, owner, name string) ([]AccessGrant, error) {
+	var grants []AccessGrant
+	opts := &github.ListOptions{PerPage: listPageSize}
+	for {
+		teams, resp, err := c.client.Repositories.ListTeams(ctx, owner, name, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list teams for %s/%s: %w", owner, name, err)
+		}
+		for _, team := range teams {
+			grants = append(grants, AccessGrant{
+				Name:       team.GetSlug(),
+				Permission: NormalizePermission(team.GetPermission()),
+			})
+		}
+		if resp == nil || resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return grants, nil
+}
+
+// SetTeamAccess grants a team of the repository owner's organization the
+// given permission, adding the team to the repository if needed.
+func (c *Client) SetTeamAccess(ctx context.Context, owner, name, team, permission string) error {
+	opts := &github.TeamAddTeamRepoOptions{Permission: permission}
+	if _, err := c.client.Teams.AddTeamRepoBySlug(ctx, owner, team, owner, name, opts); err != nil {
+		return fmt.Errorf("failed to grant team %q %s access: %w", team, permission, err)
+	}
+	return nil
+}
+
+// RemoveTeamAccess removes a team from a repository.
+func (c *Client) RemoveTeamAccess(ctx context.Context, owner, name, team string) error {
+	if _, err := c.client.Teams.RemoveTeamRepoBySlug(ctx, owner, team, owner, name); err != nil {
+		return fmt.Errorf("failed to remove team %q: %w", team, err)
+	}
+	return nil
+}
+
+// ListCollaborators lists the users granted access to a repository directly,
+// including pending invitations. The repository owner is not included.
+func (c *Client) ListCollaborators(ctx context.Context, owner, name string) ([]AccessGrant, error) {
+	var grants []AccessGrant
+	opts := &github.ListCollaboratorsOptions{
+		Affiliation: "direct",
+		ListOptions: github.ListOptions{PerPage: listPageSize},
+	}
+	for {
+		users, resp, err := c.client.Repositories.ListCollaborators(ctx, owner, name, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list collaborators for %s/%s: %w", owner, name, err)
+		}
+		for _, user := range users {
+			if strings.EqualFold(user.GetLogin(), owner) {
+				continue
+			}
+			grants = append(grants, AccessGrant{
+				Name:       user.GetLogin(),
+				Permission: NormalizePermission(user.GetRoleName()),
+			})
+		}
+		if resp == nil || resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	inviteOpts := &github.ListOptions{PerPage: listPageSize}
+	for {
+		invitations, resp, err := c.client.Repositories.ListInvitations(ctx, owner, name, inviteOpts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list invitations for %s/%s: %w", owner, name, err)
+		}
+		for _, invitation := range invitations {
+			grants = append(grants, AccessGrant{
+				Name:         invitation.GetInvitee().GetLogin(),
+				Permission:   NormalizePermission(invitation.GetPermissions()),
+				InvitationID: invitation.GetID(),
+			})
+		}
+		if resp == nil || resp.NextPage == 0 {
+			break
+		}
+		inviteOpts.Page = resp.NextPage
+	}
+
+	return grants, nil
+}
+
+// SetCollaboratorAccess grants a user the collaborator's permission. Users who
+// are not collaborators yet are invited; pending invitations are updated.
+func (c *Client) SetCollaboratorAccess(ctx context.Context, owner, name string, collaborator *AccessGrant) error {
+	var err error
+	if collaborator.InvitationID != 0 {
+		_, _, err = c.client.Repositories.UpdateInvitation(
+			ctx, owner, name, collaborator.InvitationID, invitationPermission(collaborator.Permission),
+		)
+	} else {
+		opts := &github.RepositoryAddCollaboratorOptions{Permission: collaborator.Permission}
+		_, _, err = c.client.Repositories.AddCollaborator(ctx, owner, name, collaborator.Name, opts)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to grant %q %s access: %w", collaborator.Name, collaborator.Permission, err)
+	}
+	return nil
+}
+
+// invitationPermission converts a permission to the name the invitations API
+// expects, which is the reverse of NormalizePermission.
+func invitationPermission(permission string) string {
+	switch permission {
+	case "pull":
+		return "read"
+	case "push":
+		return "write"
+	}
+	return permission
+}
+
+// RemoveCollaborator removes a collaborator, or withdraws their invitation if
+// it has not been accepted.
+func (c *Client) RemoveCollaborator(ctx context.Context, owner, name string, collaborator *AccessGrant) error {
+	var err error
+	if collaborator.InvitationID != 0 {
+		_, err = c.client.Repositories.DeleteInvitation(ctx, owner, name, collaborator.InvitationID)
+	} else {
+		_, err = c.client.Repositories.RemoveCollaborator(ctx, owner, name, collaborator.Name)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to remove collaborator %q: %w", collaborator.Name, err)
+	}
+	return nil
+}
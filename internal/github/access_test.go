@@ -0,0 +1,78 @@
+package github //nolint:testpackage // Tests internal implementation details
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestListCollaborators_IncludesInvitations(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/o/r/collaborators", func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("affiliation"); got != "direct" {
+			t.Errorf("affiliation = %q; want direct", got)
+		}
+		fmt.Fprint(w, `[{"login":"o","role_name":"admin"},{"login":"alice","role_name":"write"},
+			{"login":"bob","role_name":"ci-role"}]`)
+	})
+	mux.HandleFunc("GET /repos/o/r/invitations", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `[{"id":9,"invitee":{"login":"carol"},"permissions":"read"}]`)
+	})
+	c, _ := newTestClient(t, mux)
+
+	grants, err := c.ListCollaborators(t.Context(), "o", "r")
+	if err != nil {
+		t.Fatalf("ListCollaborators() error = %v", err)
+	}
+	want := []AccessGrant{
+		{Name: "alice", Permission: "push"},
+		{Name: "bob", Permission: "ci-role"},
+		{Name: "carol", Permission: "pull", InvitationID: 9},
+	}
+	if !reflect.DeepEqual(grants, want) {
+		t.Fatalf("ListCollaborators() = %+v; want %+v", grants, want)
+	}
+}
+
+func TestRemoveCollaborator_WithdrawsInvitation(t *testing.T) {
+	var deleted bool
+	mux := http.NewServeMux()
+	mux.HandleFunc("DELETE /repos/o/r/invitations/9", func(w http.ResponseWriter, _ *http.Request) {
+		deleted = true
+		w.WriteHeader(http.StatusNoContent)
+	})
+	c, _ := newTestClient(t, mux)
+
+	if err := c.RemoveCollaborator(t.Context(), "o", "r", &AccessGrant{Name: "carol", InvitationID: 9}); err != nil {
+		t.Fatalf("RemoveCollaborator() error = %v", err)
+	}
+	if !deleted {
+		t.Fatal("invitation was not deleted")
+	}
+}
+
+func TestSetCollaboratorAccess_UpdatesInvitation(t *testing.T) {
+	var permissions string
+	mux := http.NewServeMux()
+	mux.HandleFunc("PATCH /repos/o/r/invitations/9", func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Permissions string `json:"permissions"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decode body: %v", err)
+		}
+		permissions = body.Permissions
+		fmt.Fprint(w, `{"id":9}`)
+	})
+	c, _ := newTestClient(t, mux)
+
+	grant := &AccessGrant{Name: "carol", Permission: "push", InvitationID: 9}
+	if err := c.SetCollaboratorAccess(t.Context(), "o", "r", grant); err != nil {
+		t.Fatalf("SetCollaboratorAccess() error = %v", err)
+	}
+	if permissions != "write" {
+		t.Fatalf("permissions = %q; want write", permissions)
+	}
+}
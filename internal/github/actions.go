@@ -0,0 +1,98 @@
+package github
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-github/v82/github"
+)
+
+// allowedActionsSelected is the allowed actions policy that uses an allow list.
+const allowedActionsSelected = "selected"
+
+// ActionsPermissions is the GitHub Actions policy of a repository.
+type ActionsPermissions struct {
+	Enabled bool `json:"enabled"`
+
+	// AllowedActions is all, local_only or selected. It is empty when
+	// Actions is disabled.
+	AllowedActions string `json:"allowed_actions,omitempty"`
+
+	// The selected policy's allow list.
+	GithubOwnedAllowed bool     `json:"github_owned_allowed,omitempty"`
+	VerifiedAllowed    bool     `json:"verified_allowed,omitempty"`
+	PatternsAllowed    []string `json:"patterns_allowed,omitempty"`
+
+	// DefaultWorkflowPermissions is the default GITHUB_TOKEN permission,
+	// read or write.
+	DefaultWorkflowPermissions string `json:"default_workflow_permissions"`
+
+	CanApprovePullRequests bool `json:"can_approve_pull_requests"`
+}
+
+// GetActionsPermissions returns the GitHub Actions policy of a repository,
+// including the allow list when the selected policy is in use and the
+// default workflow token permissions.
+func (c *Client) GetActionsPermissions(ctx context.Context, owner, name string) (*ActionsPermissions, error) {
+	permissions, _, err := c.client.Repositories.GetActionsPermissions(ctx, owner, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get actions permissions for %s/%s: %w", owner, name, err)
+	}
+	info := &ActionsPermissions{
+		Enabled:        permissions.GetEnabled(),
+		AllowedActions: permissions.GetAllowedActions(),
+	}
+
+	if info.Enabled && info.AllowedActions == allowedActionsSelected {
+		allowed, _, allowedErr := c.client.Repositories.GetActionsAllowed(ctx, owner, name)
+		if allowedErr != nil {
+			return nil, fmt.Errorf("failed to get allowed actions for %s/%s: %w", owner, name, allowedErr)
+		}
+		info.GithubOwnedAllowed = allowed.GetGithubOwnedAllowed()
+		info.VerifiedAllowed = allowed.GetVerifiedAllowed()
+		info.PatternsAllowed = allowed.PatternsAllowed
+	}
+
+	workflow, _, err := c.client.Repositories.GetDefaultWorkflowPermissions(ctx, owner, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workflow permissions for %s/%s: %w", owner, name, err)
+	}
+	info.DefaultWorkflowPermissions = workflow.GetDefaultWorkflowPermissions()
+	info.CanApprovePullRequests = workflow.GetCanApprovePullRequestReviews()
+
+	return info, nil
+}
+
+// SetActionsPermissions replaces the GitHub Actions policy of a repository.
+// The allow list is only sent for the selected policy.
+func (c *Client) SetActionsPermissions(ctx context.Context, owner, name string, permissions *ActionsPermissions) error {
+	policy := github.ActionsPermissionsRepository{Enabled: github.Ptr(permissions.Enabled)}
+	if permissions.Enabled && permissions.AllowedActions != "" {
+		policy.AllowedActions = github.Ptr(permissions.AllowedActions)
+	}
+	if _, _, err := c.client.Repositories.UpdateActionsPermissions(ctx, owner, name, policy); err != nil {
+		return fmt.Errorf("failed to update actions permissions: %w", err)
+	}
+
+	if permissions.Enabled && permissions.AllowedActions == allowedActionsSelected {
+		allowed := github.ActionsAllowed{
+			GithubOwnedAllowed: github.Ptr(permissions.GithubOwnedAllowed),
+			VerifiedAllowed:    github.Ptr(permissions.VerifiedAllowed),
+			PatternsAllowed:    permissions.PatternsAllowed,
+		}
+		if _, _, err := c.client.Repositories.EditActionsAllowed(ctx, owner, name, allowed); err != nil {
+			return fmt.Errorf("failed to update allowed actions: %w", err)
+		}
+	}
+
+	workflow := github.DefaultWorkflowPermissionRepository{
+		CanApprovePullRequestReviews: github.Ptr(permissions.CanApprovePullRequests),
+	}
+	if permissions.DefaultWorkflowPermissions != "" {
+		workflow.DefaultWorkflowPermissions = github.Ptr(permissions.DefaultWorkflowPermissions)
+	}
+	if _, _, err := c.client.Repositories.UpdateDefaultWorkflowPermissions(ctx, owner, name, workflow); err != nil {
+		return fmt.Errorf("failed to update workflow permissions: %w", err)
+	}
+	return nil
+}
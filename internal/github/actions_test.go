@@ -0,0 +1,77 @@
+package github //nolint:testpackage // Tests internal implementation details
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestGetActionsPermissions(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/o/r/actions/permissions", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `{"enabled":true,"allowed_actions":"selected"}`)
+	})
+	mux.HandleFunc("GET /repos/o/r/actions/permissions/selected-actions", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `{"github_owned_allowed":true,"verified_allowed":false,"patterns_allowed":["docker/*"]}`)
+	})
+	mux.HandleFunc("GET /repos/o/r/actions/permissions/workflow", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `{"default_workflow_permissions":"write","can_approve_pull_request_reviews":true}`)
+	})
+	c, _ := newTestClient(t, mux)
+
+	got, err := c.GetActionsPermissions(t.Context(), "o", "r")
+	if err != nil {
+		t.Fatalf("GetActionsPermissions() error = %v", err)
+	}
+	want := &ActionsPermissions{
+		Enabled:                    true,
+		AllowedActions:             "selected",
+		GithubOwnedAllowed:         true,
+		PatternsAllowed:            []string{"docker/*"},
+		DefaultWorkflowPermissions: "write",
+		CanApprovePullRequests:     true,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("GetActionsPermissions() = %+v; want %+v", got, want)
+	}
+}
+
+func TestSetActionsPermissions_Disabled(t *testing.T) {
+	var policy, workflow map[string]any
+	mux := http.NewServeMux()
+	mux.HandleFunc("PUT /repos/o/r/actions/permissions", func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&policy); err != nil {
+			t.Errorf("decode body: %v", err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("PUT /repos/o/r/actions/permissions/selected-actions", func(w http.ResponseWriter, _ *http.Request) {
+		t.Error("selected actions updated; want them left alone while Actions is disabled")
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("PUT /repos/o/r/actions/permissions/workflow", func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&workflow); err != nil {
+			t.Errorf("decode body: %v", err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	c, _ := newTestClient(t, mux)
+
+	permissions := &ActionsPermissions{
+		Enabled:                    false,
+		AllowedActions:             "selected",
+		DefaultWorkflowPermissions: "read",
+	}
+	if err := c.SetActionsPermissions(t.Context(), "o", "r", permissions); err != nil {
+		t.Fatalf("SetActionsPermissions() error = %v", err)
+	}
+	if want := map[string]any{"enabled": false}; !reflect.DeepEqual(policy, want) {
+		t.Fatalf("policy = %v; want %v", policy, want)
+	}
+	wantWorkflow := map[string]any{"default_workflow_permissions": "read", "can_approve_pull_request_reviews": false}
+	if !reflect.DeepEqual(workflow, wantWorkflow) {
+		t.Fatalf("workflow = %v; want %v", workflow, wantWorkflow)
+	}
+}
@@ -0,0 +1,339 @@
+package github
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v82/github"
+)
+
+// GitHub App token lifetimes.
+const (
+	// appJWTLifetime is kept under GitHub's ten minute maximum.
+	appJWTLifetime = 9 * time.Minute
+
+	// appJWTClockSkew backdates the JWT issue time to tolerate clock drift.
+	appJWTClockSkew = time.Minute
+
+	// installationTokenRefresh renews installation tokens this long before
+	// they expire so in-flight requests never use an expired token.
+	installationTokenRefresh = 5 * time.Minute
+)
+
+// TokenSourceApp is reported for GitHub App authentication.
+const TokenSourceApp = "GitHub App"
+
+// appAuth authenticates requests as a GitHub App installation.
+//
+// Each request is routed to the installation for the repository owner in its
+// URL. Installations are looked up on first use and their access tokens are
+// cached until shortly before they expire.
+type appAuth struct {
+	base  http.RoundTripper
+	jwt   *github.Client
+	appID int64
+	key   *rsa.PrivateKey
+	now   func() time.Time
+
+	// basePath is the API path prefix (e.g. "/api/v3/" on Enterprise Server).
+	basePath string
+
+	// mu guards the maps below. It is never held across API calls; lookups
+	// and token refreshes are serialized per owner and per installation by
+	// the mutexes in locks instead.
+	mu            sync.Mutex
+	installations map[string]int64
+	tokens        map[int64]*github.InstallationToken
+	locks         map[string]*sync.Mutex
+}
+
+// repositoryContextKey carries the repository a request acts on, for
+// requests such as GraphQL whose path does not name an owner.
+type repositoryContextKey struct{}
+
+// repositoryRef identifies the repository of a request.
+type repositoryRef struct {
+	owner, name string
+}
+
+// withRepository records the repository a request acts on in ctx, so that
+// GitHub App authentication can route requests whose path does not name it.
+func withRepository(ctx context.Context, owner, name string) context.Context {
+	return context.WithValue(ctx, repositoryContextKey{}, repositoryRef{owner: owner, name: name})
+}
+
+// newAppAuth creates the app authenticator. Requests for installation lookups
+// and tokens are signed with a JWT and sent through base.
+func newAppAuth(appID int64, privateKey []byte, base http.RoundTripper) (*appAuth, error) {
+	key, err := parsePrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	app := &appAuth{
+		base:          base,
+		appID:         appID,
+		key:           key,
+		now:           time.Now,
+		installations: make(map[string]int64),
+		tokens:        make(map[int64]*github.InstallationToken),
+		locks:         make(map[string]*sync.Mutex),
+	}
+	app.jwt = newGitHubClient(&http.Client{Transport: newRetryTransport(jwtTransport{app: app})})
+	return app, nil
+}
+
+// loadInstallations records the installation of every account the app is
+// installed on and returns their IDs.
+func (a *appAuth) loadInstallations(ctx context.Context) ([]int64, error) {
+	var ids []int64
+	opts := &github.ListOptions{PerPage: listPageSize}
+	for {
+		installations, resp, err := a.jwt.Apps.ListInstallations(ctx, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list GitHub App installations: %w", err)
+		}
+		a.mu.Lock()
+		for _, installation := range installations {
+			a.installations[strings.ToLower(installation.GetAccount().GetLogin())] = installation.GetID()
+			ids = append(ids, installation.GetID())
+		}
+		a.mu.Unlock()
+		if resp == nil || resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("GitHub App %d is not installed on any account", a.appID)
+	}
+	return ids, nil
+}
+
+// slug returns the app's slug, e.g. "my-app".
+func (a *appAuth) slug(ctx context.Context) (string, error) {
+	app, _, err := a.jwt.Apps.Get(ctx, "")
+	if err != nil {
+		return "", fmt.Errorf("failed to authenticate as GitHub App %d: %w", a.appID, err)
+	}
+	return app.GetSlug(), nil
+}
+
+// RoundTrip implements http.RoundTripper.
+func (a *appAuth) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := a.token(req.Context(), req.URL.Path)
+	if err != nil {
+		return nil, err
+	}
+	authed := req.Clone(req.Context())
+	authed.Header.Set("Authorization", "Bearer "+token)
+	return a.base.RoundTrip(authed)
+}
+
+// token returns a valid installation token for the owner addressed by path.
+func (a *appAuth) token(ctx context.Context, path string) (string, error) {
+	id, err := a.installationFor(ctx, path)
+	if err != nil {
+		return "", err
+	}
+
+	unlock := a.lock("installation/" + strconv.FormatInt(id, 10))
+	defer unlock()
+
+	a.mu.Lock()
+	cached, ok := a.tokens[id]
+	a.mu.Unlock()
+	if ok && a.now().Add(installationTokenRefresh).Before(cached.GetExpiresAt().Time) {
+		return cached.GetToken(), nil
+	}
+
+	token, _, err := a.jwt.Apps.CreateInstallationToken(ctx, id, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create token for GitHub App installation %d: %w", id, err)
+	}
+	a.mu.Lock()
+	a.tokens[id] = token
+	a.mu.Unlock()
+	return token.GetToken(), nil
+}
+
+// lock acquires the mutex for key, creating it on first use, and returns the
+// function that releases it.
+func (a *appAuth) lock(key string) func() {
+	a.mu.Lock()
+	l, ok := a.locks[key]
+	if !ok {
+		l = &sync.Mutex{}
+		a.locks[key] = l
+	}
+	a.mu.Unlock()
+
+	l.Lock()
+	return l.Unlock
+}
+
+// installationFor resolves the installation for the owner in an API path,
+// or for the repository recorded by withRepository when the path names no
+// owner, looking it up on first use.
+func (a *appAuth) installationFor(ctx context.Context, path string) (int64, error) {
+	kind, owner, repo := splitOwnerPath(strings.TrimPrefix(path, a.basePath))
+	if ref, ok := ctx.Value(repositoryContextKey{}).(repositoryRef); ok && owner == "" {
+		kind, owner, repo = "repos", ref.owner, ref.name
+	}
+	if owner == "" {
+		// Requests that do not name an owner can only be routed when the
+		// app has a single installation.
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		if len(a.installations) == 1 {
+			for _, id := range a.installations {
+				return id, nil
+			}
+		}
+		return 0, fmt.Errorf("cannot choose a GitHub App installation for %s", path)
+	}
+
+	key := strings.ToLower(owner)
+	unlock := a.lock("owner/" + key)
+	defer unlock()
+
+	a.mu.Lock()
+	id, ok := a.installations[key]
+	a.mu.Unlock()
+	if ok {
+		return id, nil
+	}
+
+	var (
+		installation *github.Installation
+		err          error
+	)
+	switch {
+	case kind == "repos" && repo != "":
+		installation, _, err = a.jwt.Apps.FindRepositoryInstallation(ctx, owner, repo)
+	case kind == "orgs":
+		installation, _, err = a.jwt.Apps.FindOrganizationInstallation(ctx, owner)
+	default:
+		installation, _, err = a.jwt.Apps.FindUserInstallation(ctx, owner)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("GitHub App %d is not installed for %s: %w", a.appID, owner, err)
+	}
+
+	a.mu.Lock()
+	a.installations[key] = installation.GetID()
+	a.mu.Unlock()
+	return installation.GetID(), nil
+}
+
+// tokenSourceForInstallations describes the installations in use, e.g. "GitHub App (installation 42)".
+func tokenSourceForInstallations(ids []int64) string {
+	slices.Sort(ids)
+	parts := make([]string, 0, len(ids))
+	for _, id := range ids {
+		parts = append(parts, strconv.FormatInt(id, 10))
+	}
+	if len(ids) == 1 {
+		return fmt.Sprintf("%s (installation %s)", TokenSourceApp, parts[0])
+	}
+	return fmt.Sprintf("%s (installations %s)", TokenSourceApp, strings.Join(parts, ", "))
+}
+
+// splitOwnerPath extracts the owner from a REST path relative to the API
+// root, such as "repos/{owner}/{repo}/...", "orgs/{org}/..." or "users/{user}/...".
+func splitOwnerPath(path string) (string, string, string) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	if len(segments) < 2 { //nolint:mnd // Kind and owner segments
+		return "", "", ""
+	}
+	switch segments[0] {
+	case "repos":
+		if len(segments) > 2 { //nolint:mnd // Kind, owner and repository segments
+			return segments[0], segments[1], segments[2]
+		}
+		return segments[0], segments[1], ""
+	case "orgs", "users":
+		return segments[0], segments[1], ""
+	}
+	return "", "", ""
+}
+
+// jwtTransport signs requests with a freshly minted app JWT.
+type jwtTransport struct {
+	app *appAuth
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t jwtTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := signAppJWT(t.app.appID, t.app.key, t.app.now())
+	if err != nil {
+		return nil, err
+	}
+	authed := req.Clone(req.Context())
+	authed.Header.Set("Authorization", "Bearer "+token)
+	return t.app.base.RoundTrip(authed)
+}
+
+// signAppJWT creates the RS256 JWT that authenticates as the app itself.
+func signAppJWT(appID int64, key *rsa.PrivateKey, now time.Time) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]int64{
+		"iat": now.Add(-appJWTClockSkew).Unix(),
+		"exp": now.Add(appJWTLifetime).Unix(),
+		"iss": appID,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign GitHub App JWT: %w", err)
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// parsePrivateKey decodes a PEM encoded PKCS#1 or PKCS#8 RSA private key.
+func parsePrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("invalid GitHub App private key: no PEM data found")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid GitHub App private key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("invalid GitHub App private key: not an RSA key")
+	}
+	return key, nil
+}
@@ -0,0 +1,313 @@
+package github //nolint:testpackage // Tests internal implementation details
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	gosync "sync"
+	"testing"
+	"time"
+)
+
+func newTestKey(t *testing.T) (*rsa.PrivateKey, []byte) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	der := x509.MarshalPKCS1PrivateKey(key)
+	return key, pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: der})
+}
+
+func TestSignAppJWT(t *testing.T) {
+	key, _ := newTestKey(t)
+	now := time.Unix(1_700_000_000, 0)
+
+	token, err := signAppJWT(42, key, now)
+	if err != nil {
+		t.Fatalf("signAppJWT() error = %v", err)
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		t.Fatalf("token has %d parts; want 3", len(parts))
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		t.Fatalf("decode signature: %v", err)
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if verifyErr := rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], signature); verifyErr != nil {
+		t.Fatalf("signature does not verify: %v", verifyErr)
+	}
+
+	var header map[string]string
+	decodeSegment(t, parts[0], &header)
+	if header["alg"] != "RS256" || header["typ"] != "JWT" {
+		t.Fatalf("header = %v; want RS256 JWT", header)
+	}
+
+	var claims map[string]int64
+	decodeSegment(t, parts[1], &claims)
+	if claims["iss"] != 42 {
+		t.Fatalf("iss = %d; want 42", claims["iss"])
+	}
+	if claims["iat"] != now.Add(-appJWTClockSkew).Unix() || claims["exp"] != now.Add(appJWTLifetime).Unix() {
+		t.Fatalf("claims = %v; want iat/exp around %v", claims, now)
+	}
+}
+
+func decodeSegment(t *testing.T, segment string, v any) {
+	t.Helper()
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		t.Fatalf("decode segment: %v", err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		t.Fatalf("unmarshal segment: %v", err)
+	}
+}
+
+// fakeAppServer stands in for the GitHub App endpoints and records the
+// installation tokens it issues.
+type fakeAppServer struct {
+	mu         gosync.Mutex
+	tokenCalls map[string]int
+	repoAuth   map[string]string
+	expiresIn  time.Duration
+}
+
+func (f *fakeAppServer) handler(t *testing.T) *http.ServeMux {
+	t.Helper()
+	requireJWT := func(r *http.Request) {
+		if auth := r.Header.Get("Authorization"); strings.Count(auth, ".") != 2 {
+			t.Errorf("%s Authorization = %q; want app JWT", r.URL.Path, auth)
+		}
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v3/app", func(w http.ResponseWriter, r *http.Request) {
+		requireJWT(r)
+		fmt.Fprint(w, `{"id":42,"slug":"janitor"}`)
+	})
+	mux.HandleFunc("GET /api/v3/app/installations", func(w http.ResponseWriter, r *http.Request) {
+		requireJWT(r)
+		fmt.Fprint(w, `[{"id":11,"account":{"login":"acme"}},{"id":22,"account":{"login":"Other"}}]`)
+	})
+	mux.HandleFunc("GET /api/v3/repos/late/r/installation", func(w http.ResponseWriter, r *http.Request) {
+		requireJWT(r)
+		fmt.Fprint(w, `{"id":33,"account":{"login":"late"}}`)
+	})
+	mux.HandleFunc("POST /api/v3/app/installations/{id}/access_tokens", func(w http.ResponseWriter, r *http.Request) {
+		requireJWT(r)
+		id := r.PathValue("id")
+		f.mu.Lock()
+		f.tokenCalls[id]++
+		n := f.tokenCalls[id]
+		f.mu.Unlock()
+		expires := time.Now().Add(f.expiresIn).UTC().Format(time.RFC3339)
+		fmt.Fprintf(w, `{"token":"tok-%s-%d","expires_at":%q}`, id, n, expires)
+	})
+	mux.HandleFunc("GET /api/v3/repos/{owner}/{repo}", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		f.repoAuth[r.PathValue("owner")] = r.Header.Get("Authorization")
+		f.mu.Unlock()
+		fmt.Fprintf(w, `{"name":%q}`, r.PathValue("repo"))
+	})
+	return mux
+}
+
+func TestNewClient_GitHubApp(t *testing.T) {
+	_, pemKey := newTestKey(t)
+	fake := &fakeAppServer{
+		tokenCalls: make(map[string]int),
+		repoAuth:   make(map[string]string),
+		expiresIn:  time.Hour,
+	}
+	server := httptest.NewServer(fake.handler(t))
+	t.Cleanup(server.Close)
+
+	c, err := NewClient(t.Context(), Options{APIURL: server.URL, AppID: 42, AppPrivateKey: pemKey})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	if c.TokenSource != "GitHub App (installations 11, 22)" {
+		t.Fatalf("TokenSource = %q; want GitHub App (installations 11, 22)", c.TokenSource)
+	}
+
+	if authErr := c.ValidateAuth(t.Context()); authErr != nil {
+		t.Fatalf("ValidateAuth() error = %v", authErr)
+	}
+	user, err := c.GetAuthenticatedUser(t.Context())
+	if err != nil || user != "janitor[bot]" {
+		t.Fatalf("GetAuthenticatedUser() = %q, %v; want janitor[bot]", user, err)
+	}
+
+	for _, owner := range []string{"acme", "other", "acme", "late"} {
+		if _, getErr := c.GetRepository(t.Context(), owner, "r"); getErr != nil {
+			t.Fatalf("GetRepository(%s) error = %v", owner, getErr)
+		}
+	}
+
+	wantAuth := map[string]string{
+		"acme":  "Bearer tok-11-1",
+		"other": "Bearer tok-22-1",
+		"late":  "Bearer tok-33-1",
+	}
+	for owner, want := range wantAuth {
+		if got := fake.repoAuth[owner]; got != want {
+			t.Fatalf("Authorization for %s = %q; want %q", owner, got, want)
+		}
+	}
+	if fake.tokenCalls["11"] != 1 {
+		t.Fatalf("token requests for installation 11 = %d; want 1 (cached)", fake.tokenCalls["11"])
+	}
+}
+
+func TestAppAuth_RefreshesExpiringTokens(t *testing.T) {
+	_, pemKey := newTestKey(t)
+	fake := &fakeAppServer{
+		tokenCalls: make(map[string]int),
+		repoAuth:   make(map[string]string),
+		expiresIn:  time.Hour,
+	}
+	server := httptest.NewServer(fake.handler(t))
+	t.Cleanup(server.Close)
+
+	c, err := NewClient(t.Context(), Options{APIURL: server.URL, AppID: 42, AppPrivateKey: pemKey})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	if _, getErr := c.GetRepository(t.Context(), "acme", "r"); getErr != nil {
+		t.Fatalf("GetRepository() error = %v", getErr)
+	}
+	// Move the clock to within the refresh window of the cached token.
+	c.app.now = func() time.Time { return time.Now().Add(time.Hour - installationTokenRefresh/2) }
+	if _, getErr := c.GetRepository(t.Context(), "acme", "r"); getErr != nil {
+		t.Fatalf("GetRepository() error = %v", getErr)
+	}
+
+	if fake.tokenCalls["11"] != 2 {
+		t.Fatalf("token requests = %d; want 2 (refreshed)", fake.tokenCalls["11"])
+	}
+	if got := fake.repoAuth["acme"]; got != "Bearer tok-11-2" {
+		t.Fatalf("Authorization = %q; want Bearer tok-11-2", got)
+	}
+}
+
+func TestAppAuth_RoutesGraphQLByRepository(t *testing.T) {
+	_, pemKey := newTestKey(t)
+	fake := &fakeAppServer{
+		tokenCalls: make(map[string]int),
+		repoAuth:   make(map[string]string),
+		expiresIn:  time.Hour,
+	}
+	mux := fake.handler(t)
+	var graphQLAuth string
+	mux.HandleFunc("POST /api/graphql", func(w http.ResponseWriter, r *http.Request) {
+		graphQLAuth = r.Header.Get("Authorization")
+		fmt.Fprint(w, `{"data":{}}`)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	c, err := NewClient(t.Context(), Options{APIURL: server.URL, AppID: 42, AppPrivateKey: pemKey})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	// The app has two installations, so only the repository passed along
+	// with the request can choose between them.
+	pr := &PullRequestInfo{NodeID: "PR_7"}
+	if mergeErr := c.EnableAutoMerge(t.Context(), "Other", "r", pr, "squash"); mergeErr != nil {
+		t.Fatalf("EnableAutoMerge() error = %v", mergeErr)
+	}
+	if graphQLAuth != "Bearer tok-22-1" {
+		t.Fatalf("Authorization = %q; want Bearer tok-22-1", graphQLAuth)
+	}
+}
+
+func TestAppAuth_DoesNotBlockOtherInstallations(t *testing.T) {
+	_, pemKey := newTestKey(t)
+	fake := &fakeAppServer{
+		tokenCalls: make(map[string]int),
+		repoAuth:   make(map[string]string),
+		expiresIn:  time.Hour,
+	}
+	mux := fake.handler(t)
+	blocked := make(chan struct{}, 1)
+	release := make(chan struct{})
+	var releaseOnce gosync.Once
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v3/app/installations/11/access_tokens" {
+			select {
+			case blocked <- struct{}{}:
+			default:
+			}
+			<-release
+		}
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+	t.Cleanup(func() { releaseOnce.Do(func() { close(release) }) })
+
+	c, err := NewClient(t.Context(), Options{APIURL: server.URL, AppID: 42, AppPrivateKey: pemKey})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	var wg gosync.WaitGroup
+	errs := make(chan error, 2)
+	for range 2 {
+		wg.Go(func() {
+			_, getErr := c.GetRepository(t.Context(), "acme", "r")
+			errs <- getErr
+		})
+	}
+	<-blocked
+
+	// A token request in flight for one installation must not hold up
+	// requests for another.
+	done := make(chan error, 1)
+	go func() {
+		_, getErr := c.GetRepository(t.Context(), "other", "r")
+		done <- getErr
+	}()
+	select {
+	case getErr := <-done:
+		if getErr != nil {
+			t.Fatalf("GetRepository(other) error = %v", getErr)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("GetRepository(other) blocked behind installation 11")
+	}
+
+	releaseOnce.Do(func() { close(release) })
+	wg.Wait()
+	close(errs)
+	for getErr := range errs {
+		if getErr != nil {
+			t.Fatalf("GetRepository(acme) error = %v", getErr)
+		}
+	}
+	if fake.tokenCalls["11"] != 1 {
+		t.Fatalf("token requests for installation 11 = %d; want 1 (shared)", fake.tokenCalls["11"])
+	}
+}
+
+func TestParsePrivateKey_Invalid(t *testing.T) {
+	if _, err := parsePrivateKey([]byte("not a key")); err == nil {
+		t.Fatal("parsePrivateKey() error = nil; want error")
+	}
+}
@@ -18,12 +18,25 @@ const (
 	TokenSourceFlag   = "--token flag"
 	TokenSourceEnvVar = "GITHUB_TOKEN env var" //nolint:gosec // Not a credential, just source name
 	TokenSourceGhCLI  = "gh CLI"
+
+	EnvAPIURL = "GITHUB_API_URL"
+
+	EnvAppID             = "GITHUB_APP_ID"
+	EnvAppPrivateKeyPath = "GITHUB_APP_PRIVATE_KEY_PATH"
+
+	OwnerTypeOrg  = "org"
+	OwnerTypeUser = "user"
+
+	listPageSize = 100
 )
 
 // Client wraps the GitHub API client.
+// A Client is safe for concurrent use once created.
 type Client struct {
 	client      *github.Client
-	ctx         context.Context
+	transport   *retryTransport
+	app         *appAuth
+	apiURL      string
 	TokenSource string
 }
 
@@ -34,16 +47,40 @@ func derefBool(v *bool) bool {
 	return *v
 }
 
-// NewClient creates a new GitHub client with the given token
-// If token is empty, it attempts to auto-detect from gh CLI or GITHUB_TOKEN env var.
-func NewClient(token string) (*Client, error) {
-	ctx := context.Background()
+// Options configures how a Client connects and authenticates.
+type Options struct {
+	// Token is the access token. If empty, it is auto-detected.
+	Token string
+
+	// APIURL is the REST API base URL of a GitHub Enterprise Server instance,
+	// e.g. https://github.example.com/api/v3. Empty means github.com.
+	APIURL string
+
+	// AppID and AppPrivateKey (PEM) authenticate as a GitHub App instead of
+	// with a token. Requests use the app's installation for each owner.
+	AppID         int64
+	AppPrivateKey []byte
+}
+
+// NewClient creates a new GitHub client with the given options.
+// If neither a token nor a GitHub App is given, it attempts to auto-detect a
+// token from gh CLI or GITHUB_TOKEN env var.
+func NewClient(ctx context.Context, opts Options) (*Client, error) {
+	host, err := hostname(opts.APIURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.AppID != 0 {
+		return newAppClient(ctx, opts, host)
+	}
+
+	token := opts.Token
 	tokenSource := TokenSourceFlag
 
 	// If no token provided, try to auto-detect
 	if token == "" {
-		var err error
-		token, tokenSource, err = detectToken()
+		token, tokenSource, err = detectToken(ctx, host)
 		if err != nil {
 			return nil, err
 		}
@@ -53,27 +90,127 @@ func NewClient(token string) (*Client, error) {
 		&oauth2.Token{AccessToken: token},
 	)
 	tc := oauth2.NewClient(ctx, ts)
-	client := github.NewClient(tc)
+	transport := newRetryTransport(tc.Transport)
+	tc.Transport = transport
+
+	client, err := withServer(newGitHubClient(tc), opts.APIURL, host)
+	if err != nil {
+		return nil, err
+	}
 
 	return &Client{
 		client:      client,
-		ctx:         ctx,
+		transport:   transport,
+		apiURL:      serverURL(client, host),
 		TokenSource: tokenSource,
 	}, nil
 }
 
+// newAppClient creates a client that authenticates as a GitHub App. The app's
+// installations are listed up front so TokenSource can name them.
+func newAppClient(ctx context.Context, opts Options, host string) (*Client, error) {
+	app, err := newAppAuth(opts.AppID, opts.AppPrivateKey, nil)
+	if err != nil {
+		return nil, err
+	}
+	if app.jwt, err = withServer(app.jwt, opts.APIURL, host); err != nil {
+		return nil, err
+	}
+
+	transport := newRetryTransport(app)
+	client, err := withServer(newGitHubClient(&http.Client{Transport: transport}), opts.APIURL, host)
+	if err != nil {
+		return nil, err
+	}
+	app.basePath = client.BaseURL.Path
+
+	ids, err := app.loadInstallations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Client{
+		client:      client,
+		transport:   transport,
+		app:         app,
+		apiURL:      serverURL(client, host),
+		TokenSource: tokenSourceForInstallations(ids),
+	}, nil
+}
+
+// newGitHubClient creates a go-github client that leaves rate limits to
+// retryTransport. go-github would otherwise refuse every request after a
+// response reporting an exhausted quota, before the transport could wait
+// for the reset.
+func newGitHubClient(httpClient *http.Client) *github.Client {
+	client := github.NewClient(httpClient)
+	client.DisableRateLimitCheck = true
+	return client
+}
+
+// withServer points client at an Enterprise Server API URL, if one is set.
+func withServer(client *github.Client, apiURL, host string) (*github.Client, error) {
+	if host == "" {
+		return client, nil
+	}
+	baseURL, uploadURL := enterpriseURLs(apiURL)
+	server, err := client.WithEnterpriseURLs(baseURL, uploadURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid API URL %q: %w", apiURL, err)
+	}
+	// WithEnterpriseURLs returns a copy that does not keep this setting.
+	server.DisableRateLimitCheck = client.DisableRateLimitCheck
+	return server, nil
+}
+
+// serverURL returns the API base URL of an Enterprise Server client, or ""
+// for github.com.
+func serverURL(client *github.Client, host string) string {
+	if host == "" {
+		return ""
+	}
+	return client.BaseURL.String()
+}
+
+// APIURL returns the REST API base URL of the Enterprise Server instance the
+// client talks to, e.g. https://github.example.com/api/v3/, or "" for
+// github.com.
+func (c *Client) APIURL() string {
+	return c.apiURL
+}
+
+// RateLimit returns the API quota reported by the most recent response,
+// or nil if no request has been made yet.
+func (c *Client) RateLimit() *RateLimit {
+	if c.transport == nil {
+		return nil
+	}
+	return c.transport.RateLimit()
+}
+
 // detectToken attempts to find a GitHub token from various sources.
-func detectToken() (string, string, error) {
+// A non-empty host selects which gh CLI login to use.
+func detectToken(ctx context.Context, host string) (string, string, error) {
 	// First, try GITHUB_TOKEN environment variable
 	if token := os.Getenv(EnvToken); token != "" {
 		return token, TokenSourceEnvVar, nil
 	}
 
 	// Second, try to get token from gh CLI
-	if token, err := getGhCliToken(); err == nil && token != "" {
+	if token, err := getGhCliToken(ctx, host); err == nil && token != "" {
+		if host != "" {
+			return token, fmt.Sprintf("%s (%s)", TokenSourceGhCLI, host), nil
+		}
 		return token, TokenSourceGhCLI, nil
 	}
 
+	if host != "" {
+		return "", "", fmt.Errorf(
+			"no GitHub token found. Set %s environment variable or authenticate with 'gh auth login --hostname %s'",
+			EnvToken,
+			host,
+		)
+	}
 	return "", "", fmt.Errorf(
 		"no GitHub token found. Set %s environment variable or authenticate with 'gh auth login'",
 		EnvToken,
@@ -81,9 +218,12 @@ func detectToken() (string, string, error) {
 }
 
 // getGhCliToken attempts to get a token from the GitHub CLI.
-func getGhCliToken() (string, error) {
-	ctx := context.Background()
-	cmd := exec.CommandContext(ctx, "gh", "auth", "token")
+func getGhCliToken(ctx context.Context, host string) (string, error) {
+	args := []string{"auth", "token"}
+	if host != "" {
+		args = append(args, "--hostname", host)
+	}
+	cmd := exec.CommandContext(ctx, "gh", args...)
 	output, err := cmd.Output()
 	if err != nil {
 		return "", err
@@ -92,8 +232,13 @@ func getGhCliToken() (string, error) {
 }
 
 // ValidateAuth checks if the client can authenticate with GitHub.
-func (c *Client) ValidateAuth() error {
-	_, resp, err := c.client.Users.Get(c.ctx, "")
+func (c *Client) ValidateAuth(ctx context.Context) error {
+	if c.app != nil {
+		_, err := c.app.slug(ctx)
+		return err
+	}
+
+	_, resp, err := c.client.Users.Get(ctx, "")
 	if err != nil {
 		return fmt.Errorf("failed to authenticate: %w", err)
 	}
@@ -104,8 +249,17 @@ func (c *Client) ValidateAuth() error {
 }
 
 // GetAuthenticatedUser returns the currently authenticated user.
-func (c *Client) GetAuthenticatedUser() (string, error) {
-	user, _, err := c.client.Users.Get(c.ctx, "")
+// For a GitHub App this is the app's bot account, e.g. "my-app[bot]".
+func (c *Client) GetAuthenticatedUser(ctx context.Context) (string, error) {
+	if c.app != nil {
+		slug, err := c.app.slug(ctx)
+		if err != nil {
+			return "", err
+		}
+		return slug + "[bot]", nil
+	}
+
+	user, _, err := c.client.Users.Get(ctx, "")
 	if err != nil {
 		return "", fmt.Errorf("failed to get authenticated user: %w", err)
 	}
@@ -115,40 +269,123 @@ func (c *Client) GetAuthenticatedUser() (string, error) {
 	return *user.Login, nil
 }
 
+// RepositorySummary holds the listing fields used to discover repositories.
+type RepositorySummary struct {
+	Owner    string
+	Name     string
+	Private  bool
+	Fork     bool
+	Archived bool
+	Language string
+	Topics   []string
+}
+
+// ListRepositories lists every repository owned by an organization or user,
+// following pagination until all pages have been read.
+func (c *Client) ListRepositories(ctx context.Context, ownerType, owner string) ([]RepositorySummary, error) {
+	var list func(page int) ([]*github.Repository, *github.Response, error)
+
+	switch ownerType {
+	case OwnerTypeOrg:
+		list = func(page int) ([]*github.Repository, *github.Response, error) {
+			return c.client.Repositories.ListByOrg(ctx, owner, &github.RepositoryListByOrgOptions{
+				Type:        "all",
+				ListOptions: github.ListOptions{Page: page, PerPage: listPageSize},
+			})
+		}
+	case OwnerTypeUser:
+		// The public user listing omits private repositories, so list the
+		// authenticated user's own repositories through the /user endpoint.
+		login, err := c.GetAuthenticatedUser(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if strings.EqualFold(login, owner) {
+			list = func(page int) ([]*github.Repository, *github.Response, error) {
+				return c.client.Repositories.ListByAuthenticatedUser(
+					ctx,
+					&github.RepositoryListByAuthenticatedUserOptions{
+						Affiliation: "owner",
+						ListOptions: github.ListOptions{Page: page, PerPage: listPageSize},
+					},
+				)
+			}
+		} else {
+			list = func(page int) ([]*github.Repository, *github.Response, error) {
+				return c.client.Repositories.ListByUser(ctx, owner, &github.RepositoryListByUserOptions{
+					Type:        "owner",
+					ListOptions: github.ListOptions{Page: page, PerPage: listPageSize},
+				})
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unknown owner type: %s", ownerType)
+	}
+
+	var summaries []RepositorySummary
+	page := 1
+	for {
+		repos, resp, err := list(page)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list repositories for %s %s: %w", ownerType, owner, err)
+		}
+		for _, repo := range repos {
+			summaries = append(summaries, RepositorySummary{
+				Owner:    repo.GetOwner().GetLogin(),
+				Name:     repo.GetName(),
+				Private:  repo.GetPrivate(),
+				Fork:     repo.GetFork(),
+				Archived: repo.GetArchived(),
+				Language: repo.GetLanguage(),
+				Topics:   repo.Topics,
+			})
+		}
+		if resp == nil || resp.NextPage == 0 {
+			break
+		}
+		page = resp.NextPage
+	}
+
+	return summaries, nil
+}
+
 // RepositoryInfo holds information about a repository.
 type RepositoryInfo struct {
-	Owner            string
-	Name             string
-	AllowMergeCommit bool
-	AllowSquashMerge bool
-	AllowRebaseMerge bool
-	Private          bool
-	Exists           bool
+	Owner            string `json:"owner"`
+	Name             string `json:"name"`
+	AllowMergeCommit bool   `json:"allow_merge_commit"`
+	AllowSquashMerge bool   `json:"allow_squash_merge"`
+	AllowRebaseMerge bool   `json:"allow_rebase_merge"`
+	Private          bool   `json:"private"`
+	Exists           bool   `json:"exists"`
 
 	// Repository metadata
-	Description string
-	Homepage    string
-	Topics      []string
+	Description string   `json:"description"`
+	Homepage    string   `json:"homepage"`
+	Topics      []string `json:"topics"`
 
 	// Repository settings
-	DefaultBranch      string
-	AllowAutoMerge     bool
-	GitHubPagesEnabled bool
+	DefaultBranch      string `json:"default_branch"`
+	AllowAutoMerge     bool   `json:"allow_auto_merge"`
+	GitHubPagesEnabled bool   `json:"github_pages_enabled"`
 
 	// New repository settings
-	DeleteBranchOnMerge      bool
-	SquashMergeCommitTitle   string
-	SquashMergeCommitMessage string
-	MergeCommitTitle         string
-	MergeCommitMessage       string
-	HasIssues                bool
-	HasProjects              bool
-	HasWiki                  bool
-	HasDiscussions           bool
-	Archived                 bool
-	AllowUpdateBranch        bool
-	WebCommitSignoffRequired bool
-	AllowForking             bool
+	DeleteBranchOnMerge      bool   `json:"delete_branch_on_merge"`
+	SquashMergeCommitTitle   string `json:"squash_merge_commit_title"`
+	SquashMergeCommitMessage string `json:"squash_merge_commit_message"`
+	MergeCommitTitle         string `json:"merge_commit_title"`
+	MergeCommitMessage       string `json:"merge_commit_message"`
+	HasIssues                bool   `json:"has_issues"`
+	HasProjects              bool   `json:"has_projects"`
+	HasWiki                  bool   `json:"has_wiki"`
+	HasDiscussions           bool   `json:"has_discussions"`
+	Archived                 bool   `json:"archived"`
+	AllowUpdateBranch        bool   `json:"allow_update_branch"`
+	WebCommitSignoffRequired bool   `json:"web_commit_signoff_required"`
+	AllowForking             bool   `json:"allow_forking"`
+
+	// SecurityAndAnalysis holds the security features reported with the repository.
+	SecurityAndAnalysis SecurityAndAnalysis `json:"security_and_analysis"`
 }
 
 // Repository settings updates use go-github's *github.Repository directly.
@@ -156,9 +393,10 @@ type RepositoryInfo struct {
 
 // GetRepository fetches information about a repository.
 func (c *Client) GetRepository( //nolint:gocognit // Field mapping is straightforward
+	ctx context.Context,
 	owner, name string,
 ) (*RepositoryInfo, error) {
-	repo, resp, err := c.client.Repositories.Get(c.ctx, owner, name)
+	repo, resp, err := c.client.Repositories.Get(ctx, owner, name)
 	if err != nil {
 		if resp != nil && resp.StatusCode == http.StatusNotFound {
 			return &RepositoryInfo{
@@ -244,18 +482,19 @@ func (c *Client) GetRepository( //nolint:gocognit // Field mapping is straightfo
 	if repo.AllowForking != nil {
 		info.AllowForking = *repo.AllowForking
 	}
+	info.SecurityAndAnalysis = securityAndAnalysis(repo.SecurityAndAnalysis)
 
 	return info, nil
 }
 
 // UpdateRepositorySettings updates repository settings.
 // Only non-nil pointer fields in patch are sent to the GitHub API.
-func (c *Client) UpdateRepositorySettings(owner, name string, patch *github.Repository) error {
+func (c *Client) UpdateRepositorySettings(ctx context.Context, owner, name string, patch *github.Repository) error {
 	if patch == nil {
 		patch = &github.Repository{}
 	}
 
-	_, _, err := c.client.Repositories.Edit(c.ctx, owner, name, patch)
+	_, _, err := c.client.Repositories.Edit(ctx, owner, name, patch)
 	if err != nil {
 		return fmt.Errorf("failed to update repository %s/%s: %w", owner, name, err)
 	}
@@ -265,38 +504,39 @@ func (c *Client) UpdateRepositorySettings(owner, name string, patch *github.Repo
 
 // BranchProtectionInfo holds branch protection settings.
 type BranchProtectionInfo struct {
-	Enabled bool
-	Pattern string
+	Enabled bool   `json:"enabled"`
+	Pattern string `json:"pattern"`
 
-	PullRequestReviewsEnabled bool
-	RequiredReviews           int
-	DismissStaleReviews       bool
-	RequireCodeOwnerReviews   bool
+	PullRequestReviewsEnabled bool `json:"pull_request_reviews_enabled"`
+	RequiredReviews           int  `json:"required_reviews"`
+	DismissStaleReviews       bool `json:"dismiss_stale_reviews"`
+	RequireCodeOwnerReviews   bool `json:"require_code_owner_reviews"`
 
-	StatusChecksEnabled     bool
-	RequireBranchesUpToDate bool
-	StatusCheckContexts     []string
-	StatusCheckChecks       []*github.RequiredStatusCheck
+	StatusChecksEnabled     bool                          `json:"status_checks_enabled"`
+	RequireBranchesUpToDate bool                          `json:"require_branches_up_to_date"`
+	StatusCheckContexts     []string                      `json:"status_check_contexts"`
+	StatusCheckChecks       []*github.RequiredStatusCheck `json:"status_check_checks"`
 
-	RestrictionsEnabled bool
-	RestrictionsUsers   []string
-	RestrictionsTeams   []string
-	RestrictionsApps    []string
+	RestrictionsEnabled bool     `json:"restrictions_enabled"`
+	RestrictionsUsers   []string `json:"restrictions_users"`
+	RestrictionsTeams   []string `json:"restrictions_teams"`
+	RestrictionsApps    []string `json:"restrictions_apps"`
 
 	// Enhanced branch protection settings
-	IncludeAdmins                 bool
-	RequireLinearHistory          bool
-	RequireSignedCommits          bool
-	RequireConversationResolution bool
-	AllowForcePushes              bool
-	AllowDeletions                bool
+	IncludeAdmins                 bool `json:"include_admins"`
+	RequireLinearHistory          bool `json:"require_linear_history"`
+	RequireSignedCommits          bool `json:"require_signed_commits"`
+	RequireConversationResolution bool `json:"require_conversation_resolution"`
+	AllowForcePushes              bool `json:"allow_force_pushes"`
+	AllowDeletions                bool `json:"allow_deletions"`
 }
 
 // GetBranchProtection fetches branch protection settings.
 func (c *Client) GetBranchProtection( //nolint:gocognit // Field mapping is straightforward
+	ctx context.Context,
 	owner, name, pattern string,
 ) (*BranchProtectionInfo, error) {
-	protection, resp, err := c.client.Repositories.GetBranchProtection(c.ctx, owner, name, pattern)
+	protection, resp, err := c.client.Repositories.GetBranchProtection(ctx, owner, name, pattern)
 	if err != nil {
 		if resp != nil && resp.StatusCode == http.StatusNotFound {
 			// No protection exists
@@ -372,11 +612,35 @@ func (c *Client) GetBranchProtection( //nolint:gocognit // Field mapping is stra
 	return info, nil
 }
 
+// ListBranches lists the names of every branch in a repository.
+func (c *Client) ListBranches(ctx context.Context, owner, name string) ([]string, error) {
+	var names []string
+	opts := &github.BranchListOptions{ListOptions: github.ListOptions{PerPage: listPageSize}}
+	for {
+		branches, resp, err := c.client.Repositories.ListBranches(ctx, owner, name, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list branches for %s/%s: %w", owner, name, err)
+		}
+		for _, branch := range branches {
+			names = append(names, branch.GetName())
+		}
+		if resp == nil || resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return names, nil
+}
+
 // UpdateBranchProtection updates branch protection settings.
-func (c *Client) UpdateBranchProtection(owner, name string, protection *BranchProtectionInfo) error {
+func (c *Client) UpdateBranchProtection(
+	ctx context.Context,
+	owner, name string,
+	protection *BranchProtectionInfo,
+) error {
 	if !protection.Enabled {
 		// Remove protection if disabled
-		_, err := c.client.Repositories.RemoveBranchProtection(c.ctx, owner, name, protection.Pattern)
+		_, err := c.client.Repositories.RemoveBranchProtection(ctx, owner, name, protection.Pattern)
 		if err != nil {
 			return err
 		}
@@ -385,12 +649,13 @@ func (c *Client) UpdateBranchProtection(owner, name string, protection *BranchPr
 
 	req := buildProtectionRequest(protection)
 
-	_, _, err := c.client.Repositories.UpdateBranchProtection(c.ctx, owner, name, protection.Pattern, req)
+	_, _, err := c.client.Repositories.UpdateBranchProtection(ctx, owner, name, protection.Pattern, req)
 	if err != nil {
 		return fmt.Errorf("failed to update branch protection: %w", err)
 	}
 
 	if sigErr := c.updateRequiredSignatures(
+		ctx,
 		owner,
 		name,
 		protection.Pattern,
@@ -475,9 +740,9 @@ func buildProtectionRequest(protection *BranchProtectionInfo) *github.Protection
 	}
 }
 
-func (c *Client) updateRequiredSignatures(owner, name, pattern string, required bool) error {
+func (c *Client) updateRequiredSignatures(ctx context.Context, owner, name, pattern string, required bool) error {
 	if required {
-		_, _, err := c.client.Repositories.RequireSignaturesOnProtectedBranch(c.ctx, owner, name, pattern)
+		_, _, err := c.client.Repositories.RequireSignaturesOnProtectedBranch(ctx, owner, name, pattern)
 		if err != nil {
 			return fmt.Errorf(
 				"failed to require signatures on protected branch %s/%s:%s: %w",
@@ -489,7 +754,7 @@ func (c *Client) updateRequiredSignatures(owner, name, pattern string, required
 		}
 		return nil
 	}
-	_, err := c.client.Repositories.OptionalSignaturesOnProtectedBranch(c.ctx, owner, name, pattern)
+	_, err := c.client.Repositories.OptionalSignaturesOnProtectedBranch(ctx, owner, name, pattern)
 	if err != nil {
 		return fmt.Errorf(
 			"failed to make signatures optional on protected branch %s/%s:%s: %w",
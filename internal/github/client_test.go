@@ -1,6 +1,45 @@
 package github //nolint:testpackage // Tests internal implementation details
 
-import "testing"
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// newTestClient returns a Client that talks to the given test server.
+// Retries sleep through the returned recorder instead of real time.
+func newTestClient(t *testing.T, handler http.Handler) (*Client, *sleepRecorder) {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	baseURL, err := url.Parse(server.URL + "/")
+	if err != nil {
+		t.Fatalf("parse server URL: %v", err)
+	}
+
+	sleeps := &sleepRecorder{}
+	transport := newRetryTransport(nil)
+	transport.sleep = sleeps.sleep
+
+	client := newGitHubClient(&http.Client{Transport: transport})
+	client.BaseURL = baseURL
+	return &Client{client: client, transport: transport}, sleeps
+}
+
+// sleepRecorder records retry delays without waiting.
+type sleepRecorder struct {
+	delays []time.Duration
+}
+
+func (s *sleepRecorder) sleep(_ context.Context, d time.Duration) error {
+	s.delays = append(s.delays, d)
+	return nil
+}
 
 func TestBuildProtectionRequest_StatusChecks(t *testing.T) {
 	p := &BranchProtectionInfo{
@@ -55,3 +94,33 @@ func TestBuildProtectionRequest_StatusChecks(t *testing.T) {
 		t.Fatalf("RequiredConversationResolution = %v; want true", req.RequiredConversationResolution)
 	}
 }
+
+func TestListRepositories_FollowsPagination(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/orgs/acme/repos", func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("page") {
+		case "", "1":
+			w.Header().Set("Link", fmt.Sprintf(`<%s?page=2>; rel="next"`, "http://"+r.Host+r.URL.Path))
+			fmt.Fprint(w, `[{"name":"a","owner":{"login":"acme"},"language":"Go","topics":["svc"]}]`)
+		case "2":
+			fmt.Fprint(w, `[{"name":"b","owner":{"login":"acme"},"fork":true,"archived":true,"private":true}]`)
+		default:
+			t.Errorf("unexpected page %q", r.URL.Query().Get("page"))
+		}
+	})
+
+	c, _ := newTestClient(t, mux)
+	repos, err := c.ListRepositories(t.Context(), OwnerTypeOrg, "acme")
+	if err != nil {
+		t.Fatalf("ListRepositories() error = %v", err)
+	}
+	if len(repos) != 2 {
+		t.Fatalf("len(repos) = %d; want 2", len(repos))
+	}
+	if repos[0].Name != "a" || repos[0].Owner != "acme" || repos[0].Language != "Go" || repos[0].Topics[0] != "svc" {
+		t.Fatalf("repos[0] = %+v; want acme/a (Go, [svc])", repos[0])
+	}
+	if repos[1].Name != "b" || !repos[1].Fork || !repos[1].Archived || !repos[1].Private {
+		t.Fatalf("repos[1] = %+v; want private archived fork acme/b", repos[1])
+	}
+}
@@ -0,0 +1,66 @@
+package github
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-github/v82/github"
+)
+
+// DeployKeyInfo holds a repository deploy key.
+type DeployKeyInfo struct {
+	// ID identifies an existing deploy key; it is zero for keys not yet created.
+	ID int64 `json:"-"`
+
+	Title string `json:"title"`
+
+	// Key is the public key type and material, e.g. "ssh-ed25519 AAAA...".
+	Key      string `json:"key"`
+	ReadOnly bool   `json:"read_only"`
+}
+
+// ListDeployKeys fetches the deploy keys of a repository.
+func (c *Client) ListDeployKeys(ctx context.Context, owner, name string) ([]DeployKeyInfo, error) {
+	var keys []DeployKeyInfo
+	opts := &github.ListOptions{PerPage: listPageSize}
+	for {
+		page, resp, err := c.client.Repositories.ListKeys(ctx, owner, name, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list deploy keys for %s/%s: %w", owner, name, err)
+		}
+		for _, key := range page {
+			keys = append(keys, DeployKeyInfo{
+				ID:       key.GetID(),
+				Title:    key.GetTitle(),
+				Key:      key.GetKey(),
+				ReadOnly: key.GetReadOnly(),
+			})
+		}
+		if resp == nil || resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return keys, nil
+}
+
+// CreateDeployKey adds a deploy key to a repository.
+func (c *Client) CreateDeployKey(ctx context.Context, owner, name string, key *DeployKeyInfo) error {
+	payload := &github.Key{
+		Title:    github.Ptr(key.Title),
+		Key:      github.Ptr(key.Key),
+		ReadOnly: github.Ptr(key.ReadOnly),
+	}
+	if _, _, err := c.client.Repositories.CreateKey(ctx, owner, name, payload); err != nil {
+		return fmt.Errorf("failed to create deploy key %s: %w", key.Title, err)
+	}
+	return nil
+}
+
+// DeleteDeployKey removes the deploy key with the given ID from a repository.
+func (c *Client) DeleteDeployKey(ctx context.Context, owner, name string, id int64) error {
+	if _, err := c.client.Repositories.DeleteKey(ctx, owner, name, id); err != nil {
+		return fmt.Errorf("failed to delete deploy key %d: %w", id, err)
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package github //nolint:testpackage // Tests internal implementation details
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestListDeployKeys(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/o/r/keys", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `[{"id":4,"title":"mirror","key":"ssh-ed25519 AAAA","read_only":true}]`)
+	})
+	c, _ := newTestClient(t, mux)
+
+	keys, err := c.ListDeployKeys(t.Context(), "o", "r")
+	if err != nil {
+		t.Fatalf("ListDeployKeys() error = %v", err)
+	}
+	want := []DeployKeyInfo{{ID: 4, Title: "mirror", Key: "ssh-ed25519 AAAA", ReadOnly: true}}
+	if !reflect.DeepEqual(keys, want) {
+		t.Fatalf("ListDeployKeys() = %+v; want %+v", keys, want)
+	}
+}
+
+func TestCreateDeployKey_SendsReadOnly(t *testing.T) {
+	var body map[string]any
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /repos/o/r/keys", func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decode body: %v", err)
+		}
+		fmt.Fprint(w, `{"id":5}`)
+	})
+	c, _ := newTestClient(t, mux)
+
+	key := &DeployKeyInfo{Title: "release-bot", Key: "ssh-ed25519 AAAA", ReadOnly: false}
+	if err := c.CreateDeployKey(t.Context(), "o", "r", key); err != nil {
+		t.Fatalf("CreateDeployKey() error = %v", err)
+	}
+	if body["read_only"] != false || body["title"] != "release-bot" {
+		t.Fatalf("body = %v; want title release-bot and read_only false", body)
+	}
+}
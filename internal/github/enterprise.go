@@ -0,0 +1,47 @@
+package github
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Public GitHub hosts; API URLs pointing at these use the default client.
+const (
+	publicHost    = "github.com"
+	publicAPIHost = "api.github.com"
+
+	enterpriseAPIPath = "/api/v3"
+)
+
+// hostname returns the GitHub host for an API URL, as used by
+// 'gh auth token --hostname'. It returns "" for github.com and an empty URL.
+func hostname(apiURL string) (string, error) {
+	if apiURL == "" {
+		return "", nil
+	}
+
+	u, err := url.Parse(apiURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid API URL %q: %w", apiURL, err)
+	}
+	if (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return "", fmt.Errorf("invalid API URL %q: must be an absolute http(s) URL", apiURL)
+	}
+
+	host := u.Host
+	if host == publicAPIHost || host == publicHost {
+		return "", nil
+	}
+	// Hosts with a dedicated API subdomain (e.g. api.acme.ghe.com) are
+	// logged in to gh under the web host.
+	return strings.TrimPrefix(host, "api."), nil
+}
+
+// enterpriseURLs returns the REST and upload base URLs for an API URL.
+// Both /api/v3 paths and bare server URLs are accepted.
+func enterpriseURLs(apiURL string) (string, string) {
+	base := strings.TrimSuffix(apiURL, "/")
+	server := strings.TrimSuffix(base, enterpriseAPIPath)
+	return base, server
+}
@@ -0,0 +1,77 @@
+package github //nolint:testpackage // Tests internal implementation details
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNewClient_EnterpriseServer(t *testing.T) {
+	var gotPath, gotAuth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotAuth = r.Header.Get("Authorization")
+		fmt.Fprint(w, `{"name":"r","has_wiki":true}`)
+	}))
+	t.Cleanup(server.Close)
+
+	for _, apiURL := range []string{server.URL, server.URL + "/api/v3", server.URL + "/api/v3/"} {
+		c, err := NewClient(t.Context(), Options{Token: "secret", APIURL: apiURL})
+		if err != nil {
+			t.Fatalf("NewClient(%q) error = %v", apiURL, err)
+		}
+
+		if got := c.APIURL(); got != server.URL+"/api/v3/" {
+			t.Fatalf("APIURL() via %q = %q; want %s/api/v3/", apiURL, got, server.URL)
+		}
+
+		info, err := c.GetRepository(t.Context(), "o", "r")
+		if err != nil {
+			t.Fatalf("GetRepository() via %q error = %v", apiURL, err)
+		}
+		if !info.HasWiki {
+			t.Fatalf("HasWiki = false via %q; want true", apiURL)
+		}
+		if gotPath != "/api/v3/repos/o/r" {
+			t.Fatalf("path via %q = %q; want /api/v3/repos/o/r", apiURL, gotPath)
+		}
+		if gotAuth != "Bearer secret" {
+			t.Fatalf("Authorization via %q = %q; want Bearer secret", apiURL, gotAuth)
+		}
+	}
+}
+
+func TestNewClient_GitHubComHasNoAPIURL(t *testing.T) {
+	c, err := NewClient(t.Context(), Options{Token: "secret"})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	if got := c.APIURL(); got != "" {
+		t.Fatalf("APIURL() = %q; want empty for github.com", got)
+	}
+}
+
+func TestHostname(t *testing.T) {
+	tests := []struct {
+		apiURL  string
+		want    string
+		wantErr bool
+	}{
+		{"", "", false},
+		{"https://api.github.com", "", false},
+		{"https://github.example.com/api/v3", "github.example.com", false},
+		{"https://api.acme.ghe.com", "acme.ghe.com", false},
+		{"http://localhost:8080", "localhost:8080", false},
+		{"github.example.com", "", true},
+	}
+	for _, tt := range tests {
+		got, err := hostname(tt.apiURL)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("hostname(%q) error = %v; want error: %v", tt.apiURL, err, tt.wantErr)
+		}
+		if got != tt.want {
+			t.Fatalf("hostname(%q) = %q; want %q", tt.apiURL, got, tt.want)
+		}
+	}
+}
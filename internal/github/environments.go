@@ -0,0 +1,211 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+
+	"github.com/google/go-github/v82/github"
+)
+
+// Protection rule and deployment branch policy types used by the API.
+const (
+	protectionRuleWaitTimer = "wait_timer"
+	protectionRuleReviewers = "required_reviewers"
+	reviewerTypeUser        = "User"
+	reviewerTypeTeam        = "Team"
+	branchPolicyTypeBranch  = "branch"
+)
+
+// EnvironmentInfo holds a deployment environment and its protection rules.
+type EnvironmentInfo struct {
+	Name      string `json:"name"`
+	WaitTimer int    `json:"wait_timer"`
+
+	// ReviewerUsers and ReviewerTeams are the required reviewers, as user
+	// logins and team slugs.
+	ReviewerUsers []string `json:"reviewer_users,omitempty"`
+	ReviewerTeams []string `json:"reviewer_teams,omitempty"`
+
+	// ProtectedBranches and CustomBranches are the deployment branch policy.
+	// When neither is set any branch may deploy.
+	ProtectedBranches bool     `json:"protected_branches"`
+	CustomBranches    []string `json:"custom_branches,omitempty"`
+
+	// CanAdminsBypass and PreventSelfReview are not configured, but are kept
+	// when the environment is updated.
+	CanAdminsBypass   bool `json:"can_admins_bypass"`
+	PreventSelfReview bool `json:"prevent_self_review"`
+}
+
+// ListEnvironments fetches the deployment environments of a repository,
+// including the branch name patterns of environments with custom policies.
+func (c *Client) ListEnvironments(ctx context.Context, owner, name string) ([]EnvironmentInfo, error) {
+	var environments []EnvironmentInfo
+	opts := &github.EnvironmentListOptions{ListOptions: github.ListOptions{PerPage: listPageSize}}
+	for {
+		page, resp, err := c.client.Repositories.ListEnvironments(ctx, owner, name, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list environments for %s/%s: %w", owner, name, err)
+		}
+		for _, env := range page.Environments {
+			info := environmentInfo(env)
+			if policy := env.GetDeploymentBranchPolicy(); policy.GetCustomBranchPolicies() {
+				patterns, listErr := c.listBranchPolicies(ctx, owner, name, info.Name)
+				if listErr != nil {
+					return nil, listErr
+				}
+				for _, policy := range patterns {
+					info.CustomBranches = append(info.CustomBranches, policy.GetName())
+				}
+				slices.Sort(info.CustomBranches)
+			}
+			environments = append(environments, info)
+		}
+		if resp == nil || resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return environments, nil
+}
+
+// environmentInfo converts an API environment, without its custom branch patterns.
+func environmentInfo(env *github.Environment) EnvironmentInfo {
+	info := EnvironmentInfo{
+		Name:              env.GetName(),
+		ProtectedBranches: env.GetDeploymentBranchPolicy().GetProtectedBranches(),
+		CanAdminsBypass:   env.GetCanAdminsBypass(),
+	}
+	for _, rule := range env.ProtectionRules {
+		switch rule.GetType() {
+		case protectionRuleWaitTimer:
+			info.WaitTimer = rule.GetWaitTimer()
+		case protectionRuleReviewers:
+			info.PreventSelfReview = rule.GetPreventSelfReview()
+			for _, reviewer := range rule.Reviewers {
+				switch r := reviewer.Reviewer.(type) {
+				case *github.User:
+					info.ReviewerUsers = append(info.ReviewerUsers, r.GetLogin())
+				case *github.Team:
+					info.ReviewerTeams = append(info.ReviewerTeams, r.GetSlug())
+				}
+			}
+		}
+	}
+	slices.Sort(info.ReviewerUsers)
+	slices.Sort(info.ReviewerTeams)
+	return info
+}
+
+// listBranchPolicies fetches the branch name patterns allowed to deploy to an
+// environment. Tag patterns are left out.
+func (c *Client) listBranchPolicies(
+	ctx context.Context,
+	owner, name, env string,
+) ([]*github.DeploymentBranchPolicy, error) {
+	resp, _, err := c.client.Repositories.ListDeploymentBranchPolicies(ctx, owner, name, url.PathEscape(env))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deployment branch policies for environment %s: %w", env, err)
+	}
+	var policies []*github.DeploymentBranchPolicy
+	for _, policy := range resp.BranchPolicies {
+		if policy.GetType() == "" || policy.GetType() == branchPolicyTypeBranch {
+			policies = append(policies, policy)
+		}
+	}
+	return policies, nil
+}
+
+// SetEnvironment creates or updates a deployment environment, resolving its
+// reviewers to IDs and reconciling its custom branch name patterns.
+func (c *Client) SetEnvironment(ctx context.Context, owner, name string, env *EnvironmentInfo) error {
+	reviewers, err := c.environmentReviewers(ctx, owner, env)
+	if err != nil {
+		return err
+	}
+	payload := &github.CreateUpdateEnvironment{
+		WaitTimer:         github.Ptr(env.WaitTimer),
+		Reviewers:         reviewers,
+		CanAdminsBypass:   github.Ptr(env.CanAdminsBypass),
+		PreventSelfReview: github.Ptr(env.PreventSelfReview),
+	}
+	if env.ProtectedBranches || len(env.CustomBranches) > 0 {
+		payload.DeploymentBranchPolicy = &github.BranchPolicy{
+			ProtectedBranches:    github.Ptr(env.ProtectedBranches),
+			CustomBranchPolicies: github.Ptr(len(env.CustomBranches) > 0),
+		}
+	}
+	escaped := url.PathEscape(env.Name)
+	if _, _, updateErr := c.client.Repositories.CreateUpdateEnvironment(
+		ctx, owner, name, escaped, payload,
+	); updateErr != nil {
+		return fmt.Errorf("failed to update environment %s: %w", env.Name, updateErr)
+	}
+	if len(env.CustomBranches) == 0 {
+		return nil
+	}
+
+	current, err := c.listBranchPolicies(ctx, owner, name, env.Name)
+	if err != nil {
+		return err
+	}
+	for _, policy := range current {
+		if slices.Contains(env.CustomBranches, policy.GetName()) {
+			continue
+		}
+		if _, deleteErr := c.client.Repositories.DeleteDeploymentBranchPolicy(
+			ctx, owner, name, escaped, policy.GetID(),
+		); deleteErr != nil {
+			return fmt.Errorf("failed to delete deployment branch policy %s: %w", policy.GetName(), deleteErr)
+		}
+	}
+	for _, pattern := range env.CustomBranches {
+		if slices.ContainsFunc(current, func(p *github.DeploymentBranchPolicy) bool { return p.GetName() == pattern }) {
+			continue
+		}
+		request := &github.DeploymentBranchPolicyRequest{
+			Name: github.Ptr(pattern),
+			Type: github.Ptr(branchPolicyTypeBranch),
+		}
+		if _, _, createErr := c.client.Repositories.CreateDeploymentBranchPolicy(
+			ctx, owner, name, escaped, request,
+		); createErr != nil {
+			return fmt.Errorf("failed to create deployment branch policy %s: %w", pattern, createErr)
+		}
+	}
+	return nil
+}
+
+// environmentReviewers looks up the IDs of an environment's reviewers.
+func (c *Client) environmentReviewers(
+	ctx context.Context,
+	owner string,
+	env *EnvironmentInfo,
+) ([]*github.EnvReviewers, error) {
+	var reviewers []*github.EnvReviewers
+	for _, login := range env.ReviewerUsers {
+		user, _, err := c.client.Users.Get(ctx, login)
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up reviewer %s: %w", login, err)
+		}
+		reviewers = append(reviewers, &github.EnvReviewers{Type: github.Ptr(reviewerTypeUser), ID: user.ID})
+	}
+	for _, slug := range env.ReviewerTeams {
+		team, _, err := c.client.Teams.GetTeamBySlug(ctx, owner, slug)
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up reviewer team %s: %w", slug, err)
+		}
+		reviewers = append(reviewers, &github.EnvReviewers{Type: github.Ptr(reviewerTypeTeam), ID: team.ID})
+	}
+	return reviewers, nil
+}
+
+// DeleteEnvironment deletes a deployment environment.
+func (c *Client) DeleteEnvironment(ctx context.Context, owner, name, env string) error {
+	if _, err := c.client.Repositories.DeleteEnvironment(ctx, owner, name, url.PathEscape(env)); err != nil {
+		return fmt.Errorf("failed to delete environment %s: %w", env, err)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package github //nolint:testpackage // Tests internal implementation details
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestListEnvironments(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/o/r/environments", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `{"total_count":1,"environments":[{"name":"production","can_admins_bypass":false,
+			"deployment_branch_policy":{"protected_branches":false,"custom_branch_policies":true},
+			"protection_rules":[
+				{"type":"wait_timer","wait_timer":30},
+				{"type":"required_reviewers","prevent_self_review":true,"reviewers":[
+					{"type":"User","reviewer":{"login":"octocat","id":1}},
+					{"type":"Team","reviewer":{"slug":"release","id":2}}]}]}]}`)
+	})
+	mux.HandleFunc("GET /repos/o/r/environments/production/deployment-branch-policies",
+		func(w http.ResponseWriter, _ *http.Request) {
+			fmt.Fprint(w, `{"total_count":2,"branch_policies":[
+				{"id":1,"name":"release/*","type":"branch"},{"id":2,"name":"v*","type":"tag"}]}`)
+		})
+	c, _ := newTestClient(t, mux)
+
+	envs, err := c.ListEnvironments(t.Context(), "o", "r")
+	if err != nil {
+		t.Fatalf("ListEnvironments() error = %v", err)
+	}
+	want := []EnvironmentInfo{{
+		Name: "production", WaitTimer: 30,
+		ReviewerUsers: []string{"octocat"}, ReviewerTeams: []string{"release"},
+		CustomBranches: []string{"release/*"}, PreventSelfReview: true,
+	}}
+	if !reflect.DeepEqual(envs, want) {
+		t.Fatalf("ListEnvironments() = %+v; want %+v", envs, want)
+	}
+}
+
+func TestSetEnvironment_ReconcilesBranchPolicies(t *testing.T) {
+	var body map[string]any
+	var calls []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /users/octocat", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `{"login":"octocat","id":7}`)
+	})
+	mux.HandleFunc("PUT /repos/o/r/environments/prod env", func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decode body: %v", err)
+		}
+		fmt.Fprint(w, `{"name":"prod env"}`)
+	})
+	mux.HandleFunc("GET /repos/o/r/environments/prod env/deployment-branch-policies",
+		func(w http.ResponseWriter, _ *http.Request) {
+			fmt.Fprint(w, `{"branch_policies":[{"id":4,"name":"old","type":"branch"},{"id":5,"name":"main","type":"branch"}]}`)
+		})
+	mux.HandleFunc("DELETE /repos/o/r/environments/prod env/deployment-branch-policies/4",
+		func(w http.ResponseWriter, _ *http.Request) {
+			calls = append(calls, "delete old")
+			w.WriteHeader(http.StatusNoContent)
+		})
+	mux.HandleFunc("POST /repos/o/r/environments/prod env/deployment-branch-policies",
+		func(w http.ResponseWriter, r *http.Request) {
+			var policy map[string]string
+			if err := json.NewDecoder(r.Body).Decode(&policy); err != nil {
+				t.Errorf("decode body: %v", err)
+			}
+			calls = append(calls, "create "+policy["name"])
+			fmt.Fprint(w, `{}`)
+		})
+	c, _ := newTestClient(t, mux)
+
+	env := &EnvironmentInfo{
+		Name: "prod env", WaitTimer: 10, ReviewerUsers: []string{"octocat"},
+		CustomBranches: []string{"main", "release/*"}, CanAdminsBypass: true,
+	}
+	if err := c.SetEnvironment(t.Context(), "o", "r", env); err != nil {
+		t.Fatalf("SetEnvironment() error = %v", err)
+	}
+	reviewers, _ := body["reviewers"].([]any)
+	if body["wait_timer"] != float64(10) || len(reviewers) != 1 {
+		t.Fatalf("body = %v; want wait_timer 10 and one reviewer", body)
+	}
+	if want := []string{"delete old", "create release/*"}; !reflect.DeepEqual(calls, want) {
+		t.Fatalf("calls = %v; want %v", calls, want)
+	}
+}
@@ -0,0 +1,114 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/google/go-github/v82/github"
+)
+
+// fileMode is the git mode of a regular, non-executable file.
+const fileMode = "100644"
+
+// FileInfo is a file in a repository.
+type FileInfo struct {
+	Path    string `json:"path"`
+	Exists  bool   `json:"exists"`
+	SHA     string `json:"sha"`
+	Content string `json:"-"`
+}
+
+// FileContent is a file to write in a commit.
+type FileContent struct {
+	Path    string `json:"path"`
+	Content string `json:"content"`
+}
+
+// GetFile returns the file at path on ref. Exists is false if there is no
+// such file.
+func (c *Client) GetFile(ctx context.Context, owner, name, path, ref string) (*FileInfo, error) {
+	file, _, resp, err := c.client.Repositories.GetContents(ctx, owner, name, path, &github.RepositoryContentGetOptions{
+		Ref: ref,
+	})
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return &FileInfo{Path: path, Exists: false}, nil
+		}
+		return nil, fmt.Errorf("failed to get %s in %s/%s: %w", path, owner, name, err)
+	}
+	if file == nil {
+		return nil, fmt.Errorf("failed to get %s in %s/%s: not a file", path, owner, name)
+	}
+	content, err := file.GetContent()
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s in %s/%s: %w", path, owner, name, err)
+	}
+	return &FileInfo{Path: path, Exists: true, SHA: file.GetSHA(), Content: content}, nil
+}
+
+// CommitFiles writes files in a single commit on top of the head of base
+// and points branch at the new commit. When branch is base, base is
+// fast-forwarded; otherwise branch is created or reset to the new commit.
+func (c *Client) CommitFiles(
+	ctx context.Context,
+	owner, name, base, branch, message string,
+	files []FileContent,
+) error {
+	head, _, err := c.client.Git.GetRef(ctx, owner, name, "heads/"+base)
+	if err != nil {
+		return fmt.Errorf("failed to get branch %s: %w", base, err)
+	}
+	parent, _, err := c.client.Git.GetCommit(ctx, owner, name, head.GetObject().GetSHA())
+	if err != nil {
+		return fmt.Errorf("failed to get head of %s: %w", base, err)
+	}
+
+	entries := make([]*github.TreeEntry, 0, len(files))
+	for _, file := range files {
+		entries = append(entries, &github.TreeEntry{
+			Path:    github.Ptr(file.Path),
+			Mode:    github.Ptr(fileMode),
+			Type:    github.Ptr("blob"),
+			Content: github.Ptr(file.Content),
+		})
+	}
+	tree, _, err := c.client.Git.CreateTree(ctx, owner, name, parent.GetTree().GetSHA(), entries)
+	if err != nil {
+		return fmt.Errorf("failed to create tree: %w", err)
+	}
+	commit, _, err := c.client.Git.CreateCommit(ctx, owner, name, github.Commit{
+		Message: github.Ptr(message),
+		Tree:    &github.Tree{SHA: tree.SHA},
+		Parents: []*github.Commit{{SHA: parent.SHA}},
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create commit: %w", err)
+	}
+
+	if branch == base {
+		_, _, err = c.client.Git.UpdateRef(ctx, owner, name, "heads/"+base, github.UpdateRef{SHA: commit.GetSHA()})
+		if err != nil {
+			return fmt.Errorf("failed to update branch %s: %w", base, err)
+		}
+		return nil
+	}
+
+	_, resp, err := c.client.Git.GetRef(ctx, owner, name, "heads/"+branch)
+	switch {
+	case err == nil:
+		_, _, err = c.client.Git.UpdateRef(ctx, owner, name, "heads/"+branch, github.UpdateRef{
+			SHA:   commit.GetSHA(),
+			Force: github.Ptr(true),
+		})
+	case resp != nil && resp.StatusCode == http.StatusNotFound:
+		_, _, err = c.client.Git.CreateRef(ctx, owner, name, github.CreateRef{
+			Ref: "refs/heads/" + branch,
+			SHA: commit.GetSHA(),
+		})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update branch %s: %w", branch, err)
+	}
+	return nil
+}
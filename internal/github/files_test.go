@@ -0,0 +1,88 @@
+package github //nolint:testpackage // Tests internal implementation details
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestGetFile(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/o/r/contents/LICENSE", func(w http.ResponseWriter, r *http.Request) {
+		if ref := r.URL.Query().Get("ref"); ref != "main" {
+			t.Errorf("ref = %q; want main", ref)
+		}
+		fmt.Fprint(w, `{"type":"file","path":"LICENSE","sha":"abc123","encoding":"base64","content":"TUlUCg=="}`)
+	})
+	mux.HandleFunc("GET /repos/o/r/contents/SECURITY.md", func(w http.ResponseWriter, _ *http.Request) {
+		http.NotFound(w, nil)
+	})
+	c, _ := newTestClient(t, mux)
+
+	file, err := c.GetFile(t.Context(), "o", "r", "LICENSE", "main")
+	if err != nil {
+		t.Fatalf("GetFile() error = %v", err)
+	}
+	want := &FileInfo{Path: "LICENSE", Exists: true, SHA: "abc123", Content: "MIT\n"}
+	if !reflect.DeepEqual(file, want) {
+		t.Fatalf("GetFile() = %+v; want %+v", file, want)
+	}
+
+	missing, err := c.GetFile(t.Context(), "o", "r", "SECURITY.md", "main")
+	if err != nil {
+		t.Fatalf("GetFile(missing) error = %v", err)
+	}
+	if missing.Exists {
+		t.Fatalf("GetFile(missing) = %+v; want Exists false", missing)
+	}
+}
+
+func TestCommitFiles_CreatesBranch(t *testing.T) {
+	var tree, ref map[string]any
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/o/r/git/ref/heads/main", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `{"ref":"refs/heads/main","object":{"sha":"head"}}`)
+	})
+	mux.HandleFunc("GET /repos/o/r/git/ref/heads/github-janitor/files", func(w http.ResponseWriter, _ *http.Request) {
+		http.NotFound(w, nil)
+	})
+	mux.HandleFunc("GET /repos/o/r/git/commits/head", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `{"sha":"head","tree":{"sha":"base-tree"}}`)
+	})
+	mux.HandleFunc("POST /repos/o/r/git/trees", func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&tree); err != nil {
+			t.Errorf("decode body: %v", err)
+		}
+		fmt.Fprint(w, `{"sha":"new-tree"}`)
+	})
+	mux.HandleFunc("POST /repos/o/r/git/commits", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `{"sha":"new-commit"}`)
+	})
+	mux.HandleFunc("POST /repos/o/r/git/refs", func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&ref); err != nil {
+			t.Errorf("decode body: %v", err)
+		}
+		fmt.Fprint(w, `{}`)
+	})
+	c, _ := newTestClient(t, mux)
+
+	files := []FileContent{{Path: "LICENSE", Content: "MIT\n"}}
+	if err := c.CommitFiles(t.Context(), "o", "r", "main", "github-janitor/files", "Sync", files); err != nil {
+		t.Fatalf("CommitFiles() error = %v", err)
+	}
+
+	if tree["base_tree"] != "base-tree" {
+		t.Fatalf("base_tree = %v; want base-tree", tree["base_tree"])
+	}
+	entries, _ := tree["tree"].([]any)
+	wantEntries := []any{map[string]any{"path": "LICENSE", "mode": "100644", "type": "blob", "content": "MIT\n"}}
+	if !reflect.DeepEqual(entries, wantEntries) {
+		t.Fatalf("tree entries = %v; want %v", entries, wantEntries)
+	}
+	wantRef := map[string]any{"ref": "refs/heads/github-janitor/files", "sha": "new-commit"}
+	if !reflect.DeepEqual(ref, wantRef) {
+		t.Fatalf("ref = %v; want %v", ref, wantRef)
+	}
+}
@@ -0,0 +1,88 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/google/go-github/v82/github"
+)
+
+// LabelInfo holds an issue label.
+type LabelInfo struct {
+	Name        string `json:"name"`
+	Color       string `json:"color"`
+	Description string `json:"description"`
+}
+
+// ListLabels fetches every issue label defined on a repository.
+func (c *Client) ListLabels(ctx context.Context, owner, name string) ([]LabelInfo, error) {
+	var labels []LabelInfo
+	opts := &github.ListOptions{PerPage: listPageSize}
+	for {
+		page, resp, err := c.client.Issues.ListLabels(ctx, owner, name, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list labels for %s/%s: %w", owner, name, err)
+		}
+		for _, label := range page {
+			labels = append(labels, LabelInfo{
+				Name:        label.GetName(),
+				Color:       label.GetColor(),
+				Description: label.GetDescription(),
+			})
+		}
+		if resp == nil || resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return labels, nil
+}
+
+// CreateLabel creates an issue label.
+func (c *Client) CreateLabel(ctx context.Context, owner, name string, label *LabelInfo) error {
+	_, _, err := c.client.Issues.CreateLabel(ctx, owner, name, &github.Label{
+		Name:        github.Ptr(label.Name),
+		Color:       github.Ptr(label.Color),
+		Description: github.Ptr(label.Description),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create label %q: %w", label.Name, err)
+	}
+	return nil
+}
+
+// labelPatch is the request body of the update label endpoint, which takes
+// the new name of a renamed label as new_name.
+type labelPatch struct {
+	NewName     string `json:"new_name"`
+	Color       string `json:"color"`
+	Description string `json:"description"`
+}
+
+// UpdateLabel updates the label currently named current. Renaming a label
+// keeps it on the issues and pull requests it is applied to.
+func (c *Client) UpdateLabel(ctx context.Context, owner, name, current string, label *LabelInfo) error {
+	u := fmt.Sprintf("repos/%s/%s/labels/%s", owner, name, url.PathEscape(current))
+	req, err := c.client.NewRequest(http.MethodPatch, u, &labelPatch{
+		NewName:     label.Name,
+		Color:       label.Color,
+		Description: label.Description,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update label %q: %w", current, err)
+	}
+	if _, doErr := c.client.Do(ctx, req, nil); doErr != nil {
+		return fmt.Errorf("failed to update label %q: %w", current, doErr)
+	}
+	return nil
+}
+
+// DeleteLabel deletes an issue label, removing it from every issue and pull request.
+func (c *Client) DeleteLabel(ctx context.Context, owner, name, label string) error {
+	if _, err := c.client.Issues.DeleteLabel(ctx, owner, name, url.PathEscape(label)); err != nil {
+		return fmt.Errorf("failed to delete label %q: %w", label, err)
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package github //nolint:testpackage // Tests internal implementation details
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestListLabels(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/o/r/labels", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `[{"name":"bug","color":"d73a4a","description":"Something isn't working"},{"name":"wip"}]`)
+	})
+	c, _ := newTestClient(t, mux)
+
+	labels, err := c.ListLabels(t.Context(), "o", "r")
+	if err != nil {
+		t.Fatalf("ListLabels() error = %v", err)
+	}
+	want := []LabelInfo{{Name: "bug", Color: "d73a4a", Description: "Something isn't working"}, {Name: "wip"}}
+	if !reflect.DeepEqual(labels, want) {
+		t.Fatalf("ListLabels() = %+v; want %+v", labels, want)
+	}
+}
+
+func TestUpdateLabel_Renames(t *testing.T) {
+	var body map[string]any
+	mux := http.NewServeMux()
+	mux.HandleFunc("PATCH /repos/o/r/labels/{name}", func(w http.ResponseWriter, r *http.Request) {
+		if got := r.PathValue("name"); got != "good first issue" {
+			t.Errorf("label = %q; want good first issue", got)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decode body: %v", err)
+		}
+		fmt.Fprint(w, `{"name":"starter"}`)
+	})
+	c, _ := newTestClient(t, mux)
+
+	err := c.UpdateLabel(t.Context(), "o", "r", "good first issue", &LabelInfo{Name: "starter", Color: "7057ff"})
+	if err != nil {
+		t.Fatalf("UpdateLabel() error = %v", err)
+	}
+	want := map[string]any{"new_name": "starter", "color": "7057ff", "description": ""}
+	if !reflect.DeepEqual(body, want) {
+		t.Fatalf("body = %v; want %v", body, want)
+	}
+}
@@ -0,0 +1,149 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-github/v82/github"
+)
+
+// graphQLPath is the GraphQL endpoint relative to the REST base URL. It
+// resolves to /graphql on github.com and to /api/graphql on GitHub
+// Enterprise Server, whose REST API lives under /api/v3.
+const graphQLPath = "../graphql"
+
+// enableAutoMergeMutation turns on auto-merge for a pull request.
+const enableAutoMergeMutation = `mutation($id: ID!, $method: PullRequestMergeMethod!) {
+  enablePullRequestAutoMerge(input: {pullRequestId: $id, mergeMethod: $method}) {
+    clientMutationId
+  }
+}`
+
+// PullRequestInfo is a pull request opened from a branch of the repository.
+type PullRequestInfo struct {
+	Number    int    `json:"number,omitempty"`
+	NodeID    string `json:"-"`
+	URL       string `json:"url,omitempty"`
+	Title     string `json:"title"`
+	Body      string `json:"body"`
+	Head      string `json:"head"`
+	Base      string `json:"base"`
+	AutoMerge bool   `json:"auto_merge,omitempty"`
+}
+
+// FindPullRequest returns the open pull request into base whose head is a
+// branch of the repository starting with headPrefix. found is false if
+// there is none.
+func (c *Client) FindPullRequest(
+	ctx context.Context,
+	owner, name, headPrefix, base string,
+) (pr *PullRequestInfo, found bool, err error) {
+	opts := &github.PullRequestListOptions{
+		State:       "open",
+		Base:        base,
+		ListOptions: github.ListOptions{PerPage: listPageSize},
+	}
+	for {
+		pulls, resp, listErr := c.client.PullRequests.List(ctx, owner, name, opts)
+		if listErr != nil {
+			return nil, false, fmt.Errorf("failed to list pull requests for %s/%s: %w", owner, name, listErr)
+		}
+		for _, pull := range pulls {
+			head := pull.GetHead()
+			// Branches of forks share names with the repository's own branches.
+			if strings.HasPrefix(head.GetRef(), headPrefix) && strings.EqualFold(head.GetLabel(), owner+":"+head.GetRef()) {
+				return pullRequestInfo(pull), true, nil
+			}
+		}
+		if resp.NextPage == 0 {
+			return nil, false, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// CreatePullRequest opens a pull request and returns it with its number and URL.
+func (c *Client) CreatePullRequest(
+	ctx context.Context,
+	owner, name string,
+	pr *PullRequestInfo,
+) (*PullRequestInfo, error) {
+	created, _, err := c.client.PullRequests.Create(ctx, owner, name, &github.NewPullRequest{
+		Title: github.Ptr(pr.Title),
+		Body:  github.Ptr(pr.Body),
+		Head:  github.Ptr(pr.Head),
+		Base:  github.Ptr(pr.Base),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create pull request: %w", err)
+	}
+	return pullRequestInfo(created), nil
+}
+
+// UpdatePullRequest replaces the title and body of a pull request.
+func (c *Client) UpdatePullRequest(ctx context.Context, owner, name string, pr *PullRequestInfo) error {
+	_, _, err := c.client.PullRequests.Edit(ctx, owner, name, pr.Number, &github.PullRequest{
+		Title: github.Ptr(pr.Title),
+		Body:  github.Ptr(pr.Body),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update pull request #%d: %w", pr.Number, err)
+	}
+	return nil
+}
+
+// graphQLRequest is the body of a GraphQL request.
+type graphQLRequest struct {
+	Query     string         `json:"query"`
+	Variables map[string]any `json:"variables"`
+}
+
+// graphQLResponse holds the errors of a GraphQL response, which is sent
+// with status 200 even when the request fails.
+type graphQLResponse struct {
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// EnableAutoMerge turns on auto-merge for a pull request, merging it with
+// method (merge, squash or rebase) once its requirements are met. GitHub
+// only offers this through GraphQL, whose path does not name the
+// repository, so it is passed along for GitHub App authentication.
+func (c *Client) EnableAutoMerge(ctx context.Context, owner, name string, pr *PullRequestInfo, method string) error {
+	req, err := c.client.NewRequest(http.MethodPost, graphQLPath, &graphQLRequest{
+		Query:     enableAutoMergeMutation,
+		Variables: map[string]any{"id": pr.NodeID, "method": strings.ToUpper(method)},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to enable auto-merge for pull request #%d: %w", pr.Number, err)
+	}
+	var resp graphQLResponse
+	if _, doErr := c.client.Do(withRepository(ctx, owner, name), req, &resp); doErr != nil {
+		return fmt.Errorf("failed to enable auto-merge for pull request #%d: %w", pr.Number, doErr)
+	}
+	if len(resp.Errors) > 0 {
+		messages := make([]string, 0, len(resp.Errors))
+		for _, e := range resp.Errors {
+			messages = append(messages, e.Message)
+		}
+		return fmt.Errorf("failed to enable auto-merge for pull request #%d: %s", pr.Number, strings.Join(messages, "; "))
+	}
+	return nil
+}
+
+// pullRequestInfo converts a pull request.
+func pullRequestInfo(pr *github.PullRequest) *PullRequestInfo {
+	return &PullRequestInfo{
+		Number:    pr.GetNumber(),
+		NodeID:    pr.GetNodeID(),
+		URL:       pr.GetHTMLURL(),
+		Title:     pr.GetTitle(),
+		Body:      pr.GetBody(),
+		Head:      pr.GetHead().GetRef(),
+		Base:      pr.GetBase().GetRef(),
+		AutoMerge: pr.AutoMerge != nil,
+	}
+}
@@ -0,0 +1,94 @@
+package github //nolint:testpackage // Tests internal implementation details
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestFindPullRequest_SkipsForks(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/o/r/pulls", func(w http.ResponseWriter, r *http.Request) {
+		if base := r.URL.Query().Get("base"); base != "main" {
+			t.Errorf("base = %q; want main", base)
+		}
+		fmt.Fprint(w, `[
+			{"number":6,"head":{"ref":"github-janitor/sync-aaa","label":"fork:github-janitor/sync-aaa"}},
+			{"number":7,"node_id":"PR_7","html_url":"https://github.com/o/r/pull/7","auto_merge":{"merge_method":"squash"},
+			 "head":{"ref":"github-janitor/sync-bbb","label":"o:github-janitor/sync-bbb"},"base":{"ref":"main"}}
+		]`)
+	})
+	c, _ := newTestClient(t, mux)
+
+	pr, found, err := c.FindPullRequest(t.Context(), "o", "r", "github-janitor/sync-", "main")
+	if err != nil {
+		t.Fatalf("FindPullRequest() error = %v", err)
+	}
+	want := &PullRequestInfo{
+		Number:    7,
+		NodeID:    "PR_7",
+		URL:       "https://github.com/o/r/pull/7",
+		Head:      "github-janitor/sync-bbb",
+		Base:      "main",
+		AutoMerge: true,
+	}
+	if !found || !reflect.DeepEqual(pr, want) {
+		t.Fatalf("FindPullRequest() = %+v, %v; want %+v", pr, found, want)
+	}
+}
+
+func TestEnableAutoMerge(t *testing.T) {
+	var body graphQLRequest
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /graphql", func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decode body: %v", err)
+		}
+		fmt.Fprint(w, `{"data":{"enablePullRequestAutoMerge":{"clientMutationId":null}}}`)
+	})
+	c, _ := newTestClient(t, mux)
+
+	if err := c.EnableAutoMerge(t.Context(), "o", "r", &PullRequestInfo{Number: 7, NodeID: "PR_7"}, "squash"); err != nil {
+		t.Fatalf("EnableAutoMerge() error = %v", err)
+	}
+	want := map[string]any{"id": "PR_7", "method": "SQUASH"}
+	if !reflect.DeepEqual(body.Variables, want) {
+		t.Fatalf("variables = %v; want %v", body.Variables, want)
+	}
+}
+
+func TestEnableAutoMerge_GraphQLError(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /graphql", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `{"errors":[{"message":"Auto merge is not allowed for this repository"}]}`)
+	})
+	c, _ := newTestClient(t, mux)
+
+	err := c.EnableAutoMerge(t.Context(), "o", "r", &PullRequestInfo{Number: 7, NodeID: "PR_7"}, "merge")
+	if err == nil {
+		t.Fatal("EnableAutoMerge() error = nil; want GraphQL error")
+	}
+}
+
+func TestEnableAutoMerge_EnterpriseServer(t *testing.T) {
+	var gotPath string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		fmt.Fprint(w, `{"data":{}}`)
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := NewClient(t.Context(), Options{Token: "secret", APIURL: server.URL + "/api/v3"})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	if enableErr := c.EnableAutoMerge(t.Context(), "o", "r", &PullRequestInfo{NodeID: "PR_7"}, "merge"); enableErr != nil {
+		t.Fatalf("EnableAutoMerge() error = %v", enableErr)
+	}
+	if gotPath != "/api/graphql" {
+		t.Fatalf("path = %q; want /api/graphql", gotPath)
+	}
+}
@@ -0,0 +1,268 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Retry defaults for the GitHub API transport.
+const (
+	defaultMaxRetries = 5
+	defaultBaseDelay  = time.Second
+	defaultMaxDelay   = time.Minute
+
+	// rateLimitSlack is added to the reset time so a retry does not race the
+	// quota refresh.
+	rateLimitSlack = time.Second
+
+	// secondaryRateLimitDelay is the minimum wait GitHub asks for after a
+	// secondary rate limit response that carries no Retry-After header.
+	secondaryRateLimitDelay = time.Minute
+
+	// maxErrorBodySize bounds how much of an error response is buffered to
+	// look for a secondary rate limit message.
+	maxErrorBodySize = 64 << 10
+)
+
+// Rate limit response headers.
+const (
+	headerRateLimit     = "X-RateLimit-Limit"
+	headerRateRemaining = "X-RateLimit-Remaining"
+	headerRateReset     = "X-RateLimit-Reset"
+	headerRetryAfter    = "Retry-After"
+)
+
+var errBodyNotReplayable = errors.New("request body cannot be replayed")
+
+// RateLimit is the API quota reported by the most recent response.
+type RateLimit struct {
+	Limit     int
+	Remaining int
+	Reset     time.Time
+}
+
+// retryTransport retries requests rejected by primary or secondary rate
+// limits, and idempotent requests that hit transient server errors.
+//
+// Rate limited requests were not processed by GitHub, so they are retried
+// whatever their method once the limit resets. Server errors and network
+// failures are only retried for idempotent methods, with jittered exponential
+// backoff.
+type retryTransport struct {
+	base       http.RoundTripper
+	maxRetries int
+	baseDelay  time.Duration
+	maxDelay   time.Duration
+
+	now   func() time.Time
+	sleep func(ctx context.Context, d time.Duration) error
+
+	mu        sync.Mutex
+	rateLimit *RateLimit
+}
+
+func newRetryTransport(base http.RoundTripper) *retryTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &retryTransport{
+		base:       base,
+		maxRetries: defaultMaxRetries,
+		baseDelay:  defaultBaseDelay,
+		maxDelay:   defaultMaxDelay,
+		now:        time.Now,
+		sleep:      sleepContext,
+	}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	attemptReq := req
+	for attempt := 0; ; attempt++ {
+		resp, err := t.base.RoundTrip(attemptReq)
+		if err == nil {
+			t.record(resp.Header)
+		}
+
+		delay, retry := t.retryDelay(req, resp, err, attempt)
+		if !retry || attempt >= t.maxRetries || req.Context().Err() != nil {
+			return resp, err
+		}
+
+		next, rewindErr := rewind(req)
+		if rewindErr != nil {
+			return resp, err
+		}
+
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			_ = resp.Body.Close()
+		}
+		if sleepErr := t.sleep(req.Context(), delay); sleepErr != nil {
+			return nil, sleepErr
+		}
+		attemptReq = next
+	}
+}
+
+// retryDelay decides whether an attempt should be retried and how long to
+// wait before doing so.
+func (t *retryTransport) retryDelay(
+	req *http.Request,
+	resp *http.Response,
+	err error,
+	attempt int,
+) (time.Duration, bool) {
+	if err != nil {
+		return t.backoff(attempt), isIdempotent(req.Method)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusForbidden, http.StatusTooManyRequests:
+		if after, ok := parseRetryAfter(resp.Header); ok {
+			return after, true
+		}
+		if resp.Header.Get(headerRateRemaining) == "0" {
+			if reset, ok := parseReset(resp.Header); ok {
+				return max(reset.Sub(t.now()), 0) + rateLimitSlack, true
+			}
+		}
+		if isSecondaryRateLimit(resp) {
+			return secondaryRateLimitDelay + t.backoff(attempt), true
+		}
+		if resp.StatusCode == http.StatusTooManyRequests {
+			return t.backoff(attempt), true
+		}
+		// A plain 403 is a permission error, not a rate limit.
+		return 0, false
+	case http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return t.backoff(attempt), isIdempotent(req.Method)
+	}
+
+	return 0, false
+}
+
+// backoff returns the jittered exponential delay for a retry attempt.
+func (t *retryTransport) backoff(attempt int) time.Duration {
+	delay := t.maxDelay
+	if attempt < 32 && t.baseDelay<<attempt < t.maxDelay { //nolint:mnd // Guards the shift against overflow
+		delay = t.baseDelay << attempt
+	}
+	half := delay / 2 //nolint:mnd // Equal jitter keeps at least half the delay
+	if half <= 0 {
+		return delay
+	}
+	return half + rand.N(half) //nolint:gosec // Jitter does not need a secure source
+}
+
+// record stores the quota reported by a response, if any.
+func (t *retryTransport) record(header http.Header) {
+	remaining, err := strconv.Atoi(header.Get(headerRateRemaining))
+	if err != nil {
+		return
+	}
+	limit, _ := strconv.Atoi(header.Get(headerRateLimit))
+	reset, _ := parseReset(header)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.rateLimit = &RateLimit{Limit: limit, Remaining: remaining, Reset: reset}
+}
+
+// RateLimit returns the most recently observed quota, or nil if no response
+// has reported one yet.
+func (t *retryTransport) RateLimit() *RateLimit {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.rateLimit == nil {
+		return nil
+	}
+	rate := *t.rateLimit
+	return &rate
+}
+
+// rewind returns a copy of req with a fresh body for another attempt.
+func rewind(req *http.Request) (*http.Request, error) {
+	next := req.Clone(req.Context())
+	if req.Body == nil || req.Body == http.NoBody {
+		return next, nil
+	}
+	if req.GetBody == nil {
+		return nil, errBodyNotReplayable
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	next.Body = body
+	return next, nil
+}
+
+// isSecondaryRateLimit reports whether resp is a secondary (abuse) rate limit
+// rejection, which GitHub may send without any rate limit headers. It matches
+// the message and documentation URL go-github uses to build an
+// AbuseRateLimitError. The body is buffered and restored for the caller.
+func isSecondaryRateLimit(resp *http.Response) bool {
+	if resp.Body == nil || resp.Body == http.NoBody {
+		return false
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+	if err != nil {
+		return false
+	}
+
+	text := strings.ToLower(string(body))
+	return strings.Contains(text, "secondary rate limit") ||
+		strings.Contains(text, "secondary-rate-limits") ||
+		strings.Contains(text, "abuse-rate-limits") ||
+		strings.Contains(text, "abuse detection")
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func parseRetryAfter(header http.Header) (time.Duration, bool) {
+	seconds, err := strconv.Atoi(header.Get(headerRetryAfter))
+	if err != nil || seconds < 0 {
+		return 0, false
+	}
+	return time.Duration(seconds) * time.Second, true
+}
+
+func parseReset(header http.Header) (time.Time, bool) {
+	epoch, err := strconv.ParseInt(header.Get(headerRateReset), 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(epoch, 0), true
+}
+
+// sleepContext waits for d or until ctx is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,230 @@
+package github //nolint:testpackage // Tests internal implementation details
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v82/github"
+)
+
+func TestRetry_WaitsForPrimaryRateLimitReset(t *testing.T) {
+	reset := time.Now().Add(30 * time.Second).Truncate(time.Second)
+	calls := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/o/r", func(w http.ResponseWriter, _ *http.Request) {
+		calls++
+		w.Header().Set(headerRateLimit, "5000")
+		w.Header().Set(headerRateReset, strconv.FormatInt(reset.Unix(), 10))
+		if calls == 1 {
+			w.Header().Set(headerRateRemaining, "0")
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"message":"API rate limit exceeded"}`)
+			return
+		}
+		w.Header().Set(headerRateRemaining, "4999")
+		fmt.Fprint(w, `{"name":"r"}`)
+	})
+
+	c, sleeps := newTestClient(t, mux)
+	now := reset.Add(-30 * time.Second)
+	c.transport.now = func() time.Time { return now }
+
+	info, err := c.GetRepository(t.Context(), "o", "r")
+	if err != nil {
+		t.Fatalf("GetRepository() error = %v", err)
+	}
+	if !info.Exists {
+		t.Fatal("Exists = false; want true")
+	}
+	if calls != 2 {
+		t.Fatalf("calls = %d; want 2", calls)
+	}
+	if want := []time.Duration{30*time.Second + rateLimitSlack}; fmt.Sprint(sleeps.delays) != fmt.Sprint(want) {
+		t.Fatalf("delays = %v; want %v", sleeps.delays, want)
+	}
+
+	rate := c.RateLimit()
+	if rate == nil || rate.Remaining != 4999 || rate.Limit != 5000 || !rate.Reset.Equal(reset) {
+		t.Fatalf("RateLimit() = %+v; want 4999/5000 resetting at %v", rate, reset)
+	}
+}
+
+func TestRetry_WaitsAfterResponseExhaustsQuota(t *testing.T) {
+	reset := time.Now().Add(time.Hour).Truncate(time.Second)
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls++
+		w.Header().Set(headerRateLimit, "5000")
+		w.Header().Set(headerRateReset, strconv.FormatInt(reset.Unix(), 10))
+		w.Header().Set(headerRateRemaining, "0")
+		if calls == 2 {
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"message":"API rate limit exceeded"}`)
+			return
+		}
+		fmt.Fprint(w, `{"name":"r"}`)
+	}))
+	t.Cleanup(server.Close)
+
+	// The first response uses up the quota. go-github must still send the
+	// second request, so that the transport waits for the reset and retries.
+	c, err := NewClient(t.Context(), Options{Token: "secret", APIURL: server.URL})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	sleeps := &sleepRecorder{}
+	c.transport.sleep = sleeps.sleep
+	now := reset.Add(-time.Minute)
+	c.transport.now = func() time.Time { return now }
+
+	for range 2 {
+		if _, getErr := c.GetRepository(t.Context(), "o", "r"); getErr != nil {
+			t.Fatalf("GetRepository() error = %v", getErr)
+		}
+	}
+	if calls != 3 {
+		t.Fatalf("calls = %d; want 3", calls)
+	}
+	if want := []time.Duration{time.Minute + rateLimitSlack}; fmt.Sprint(sleeps.delays) != fmt.Sprint(want) {
+		t.Fatalf("delays = %v; want %v", sleeps.delays, want)
+	}
+}
+
+func TestRetry_HonorsRetryAfterForNonIdempotentRequests(t *testing.T) {
+	calls := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/o/r", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPatch {
+			t.Errorf("method = %s; want PATCH", r.Method)
+		}
+		calls++
+		if calls == 1 {
+			w.Header().Set(headerRetryAfter, "7")
+			w.WriteHeader(http.StatusTooManyRequests)
+			fmt.Fprint(w, `{"message":"You have exceeded a secondary rate limit"}`)
+			return
+		}
+		fmt.Fprint(w, `{"name":"r"}`)
+	})
+
+	c, sleeps := newTestClient(t, mux)
+	if err := c.UpdateRepositorySettings(t.Context(), "o", "r", &github.Repository{HasWiki: github.Ptr(false)}); err != nil {
+		t.Fatalf("UpdateRepositorySettings() error = %v", err)
+	}
+	if calls != 2 {
+		t.Fatalf("calls = %d; want 2", calls)
+	}
+	if len(sleeps.delays) != 1 || sleeps.delays[0] != 7*time.Second {
+		t.Fatalf("delays = %v; want [7s]", sleeps.delays)
+	}
+}
+
+func TestRetry_WaitsAfterSecondaryRateLimitWithoutHeaders(t *testing.T) {
+	calls := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/o/r", func(w http.ResponseWriter, _ *http.Request) {
+		calls++
+		if calls == 1 {
+			w.Header().Set(headerRateRemaining, "4000")
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"message":"You have exceeded a secondary rate limit. Please wait a few minutes `+
+				`before you try again.","documentation_url":"https://docs.github.com/rest/overview/`+
+				`rate-limits-for-the-rest-api#about-secondary-rate-limits"}`)
+			return
+		}
+		fmt.Fprint(w, `{"name":"r"}`)
+	})
+
+	c, sleeps := newTestClient(t, mux)
+	if err := c.UpdateRepositorySettings(t.Context(), "o", "r", &github.Repository{HasWiki: github.Ptr(false)}); err != nil {
+		t.Fatalf("UpdateRepositorySettings() error = %v", err)
+	}
+	if calls != 2 {
+		t.Fatalf("calls = %d; want 2", calls)
+	}
+	if len(sleeps.delays) != 1 || sleeps.delays[0] < secondaryRateLimitDelay {
+		t.Fatalf("delays = %v; want one delay of at least %v", sleeps.delays, secondaryRateLimitDelay)
+	}
+}
+
+func TestRetry_BacksOffOnServerErrorsForIdempotentRequestsOnly(t *testing.T) {
+	var gets, patches int
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/o/r", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			gets++
+			if gets < 3 {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			fmt.Fprint(w, `{"name":"r"}`)
+			return
+		}
+		patches++
+		w.WriteHeader(http.StatusBadGateway)
+	})
+
+	c, sleeps := newTestClient(t, mux)
+	if _, err := c.GetRepository(t.Context(), "o", "r"); err != nil {
+		t.Fatalf("GetRepository() error = %v", err)
+	}
+	if gets != 3 {
+		t.Fatalf("gets = %d; want 3", gets)
+	}
+	if len(sleeps.delays) != 2 {
+		t.Fatalf("delays = %v; want 2 backoffs", sleeps.delays)
+	}
+	for i, d := range sleeps.delays {
+		lo, hi := defaultBaseDelay<<i/2, defaultBaseDelay<<i
+		if d < lo || d >= hi {
+			t.Fatalf("delays[%d] = %v; want in [%v, %v)", i, d, lo, hi)
+		}
+	}
+
+	if err := c.UpdateRepositorySettings(t.Context(), "o", "r", &github.Repository{}); err == nil {
+		t.Fatal("UpdateRepositorySettings() error = nil; want error")
+	}
+	if patches != 1 {
+		t.Fatalf("patches = %d; want 1", patches)
+	}
+}
+
+func TestRetry_DoesNotRetryPermissionErrors(t *testing.T) {
+	calls := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/o/r", func(w http.ResponseWriter, _ *http.Request) {
+		calls++
+		w.Header().Set(headerRateRemaining, "4000")
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `{"message":"Resource not accessible by integration"}`)
+	})
+
+	c, sleeps := newTestClient(t, mux)
+	if _, err := c.GetRepository(t.Context(), "o", "r"); err == nil {
+		t.Fatal("GetRepository() error = nil; want error")
+	}
+	if calls != 1 || len(sleeps.delays) != 0 {
+		t.Fatalf("calls = %d, delays = %v; want 1 call and no retries", calls, sleeps.delays)
+	}
+}
+
+func TestRetry_GivesUpAfterMaxRetries(t *testing.T) {
+	calls := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/o/r", func(w http.ResponseWriter, _ *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	c, _ := newTestClient(t, mux)
+	if _, err := c.GetRepository(t.Context(), "o", "r"); err == nil {
+		t.Fatal("GetRepository() error = nil; want error")
+	}
+	if calls != defaultMaxRetries+1 {
+		t.Fatalf("calls = %d; want %d", calls, defaultMaxRetries+1)
+	}
+}
@@ -0,0 +1,348 @@
+package github
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-github/v82/github"
+)
+
+// RulesetInfo holds a repository ruleset.
+type RulesetInfo struct {
+	// ID identifies an existing ruleset; it is zero for rulesets not yet created.
+	ID int64 `json:"-"`
+
+	Name        string `json:"name"`
+	Target      string `json:"target"`
+	Enforcement string `json:"enforcement"`
+
+	Include []string `json:"include,omitempty"`
+	Exclude []string `json:"exclude,omitempty"`
+
+	BypassActors []RulesetBypassActor `json:"bypass_actors,omitempty"`
+	Rules        RulesetRules         `json:"rules"`
+}
+
+// RulesetBypassActor may bypass a ruleset.
+type RulesetBypassActor struct {
+	ActorType  string `json:"actor_type"`
+	ActorID    int64  `json:"actor_id"`
+	BypassMode string `json:"bypass_mode"`
+}
+
+// RulesetRules lists the rules a ruleset enforces.
+type RulesetRules struct {
+	Creation              bool `json:"creation"`
+	Update                bool `json:"update"`
+	Deletion              bool `json:"deletion"`
+	RequiredLinearHistory bool `json:"required_linear_history"`
+	RequiredSignatures    bool `json:"required_signatures"`
+	NonFastForward        bool `json:"non_fast_forward"`
+
+	PullRequest              *RulesetPullRequest  `json:"pull_request,omitempty"`
+	RequiredStatusChecks     *RulesetStatusChecks `json:"required_status_checks,omitempty"`
+	RequiredDeployments      []string             `json:"required_deployments,omitempty"`
+	CommitMessagePattern     *RulesetPatternRule  `json:"commit_message_pattern,omitempty"`
+	CommitAuthorEmailPattern *RulesetPatternRule  `json:"commit_author_email_pattern,omitempty"`
+	CommitterEmailPattern    *RulesetPatternRule  `json:"committer_email_pattern,omitempty"`
+	BranchNamePattern        *RulesetPatternRule  `json:"branch_name_pattern,omitempty"`
+	TagNamePattern           *RulesetPatternRule  `json:"tag_name_pattern,omitempty"`
+
+	FilePathRestriction      []string `json:"file_path_restriction,omitempty"`
+	FileExtensionRestriction []string `json:"file_extension_restriction,omitempty"`
+	MaxFilePathLength        int      `json:"max_file_path_length,omitempty"`
+	MaxFileSize              int64    `json:"max_file_size,omitempty"`
+}
+
+// RulesetPullRequest holds the pull_request rule parameters.
+type RulesetPullRequest struct {
+	RequiredApprovingReviewCount   int  `json:"required_approving_review_count"`
+	DismissStaleReviewsOnPush      bool `json:"dismiss_stale_reviews_on_push"`
+	RequireCodeOwnerReview         bool `json:"require_code_owner_review"`
+	RequireLastPushApproval        bool `json:"require_last_push_approval"`
+	RequiredReviewThreadResolution bool `json:"required_review_thread_resolution"`
+}
+
+// RulesetStatusChecks holds the required_status_checks rule parameters.
+type RulesetStatusChecks struct {
+	Contexts []string `json:"contexts"`
+	Strict   bool     `json:"strict"`
+}
+
+// RulesetPatternRule holds the parameters of a pattern rule.
+type RulesetPatternRule struct {
+	Name     string `json:"name,omitempty"`
+	Operator string `json:"operator"`
+	Pattern  string `json:"pattern"`
+	Negate   bool   `json:"negate"`
+}
+
+// ListRulesets fetches the rulesets defined on a repository. Rulesets
+// inherited from the organization or enterprise are not included.
+func (c *Client) ListRulesets(ctx context.Context, owner, name string) ([]RulesetInfo, error) {
+	var summaries []*github.RepositoryRuleset
+	opts := &github.RepositoryListRulesetsOptions{
+		IncludesParents: github.Ptr(false),
+		ListOptions:     github.ListOptions{PerPage: listPageSize},
+	}
+	for {
+		page, resp, err := c.client.Repositories.GetAllRulesets(ctx, owner, name, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list rulesets for %s/%s: %w", owner, name, err)
+		}
+		summaries = append(summaries, page...)
+		if resp == nil || resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	// The list endpoint omits rules and conditions, so fetch each ruleset.
+	rulesets := make([]RulesetInfo, 0, len(summaries))
+	for _, summary := range summaries {
+		ruleset, _, err := c.client.Repositories.GetRuleset(ctx, owner, name, summary.GetID(), false)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get ruleset %q for %s/%s: %w", summary.Name, owner, name, err)
+		}
+		rulesets = append(rulesets, rulesetInfoFromAPI(ruleset))
+	}
+
+	return rulesets, nil
+}
+
+// CreateRuleset creates a repository ruleset.
+func (c *Client) CreateRuleset(ctx context.Context, owner, name string, ruleset *RulesetInfo) error {
+	if _, _, err := c.client.Repositories.CreateRuleset(ctx, owner, name, buildRuleset(ruleset)); err != nil {
+		return fmt.Errorf("failed to create ruleset %q: %w", ruleset.Name, err)
+	}
+	return nil
+}
+
+// UpdateRuleset replaces the repository ruleset with the given ID.
+func (c *Client) UpdateRuleset(ctx context.Context, owner, name string, id int64, ruleset *RulesetInfo) error {
+	if _, _, err := c.client.Repositories.UpdateRuleset(ctx, owner, name, id, buildRuleset(ruleset)); err != nil {
+		return fmt.Errorf("failed to update ruleset %q: %w", ruleset.Name, err)
+	}
+	return nil
+}
+
+// DeleteRuleset deletes the repository ruleset with the given ID.
+func (c *Client) DeleteRuleset(ctx context.Context, owner, name string, id int64) error {
+	if _, err := c.client.Repositories.DeleteRuleset(ctx, owner, name, id); err != nil {
+		return fmt.Errorf("failed to delete ruleset %d: %w", id, err)
+	}
+	return nil
+}
+
+// rulesetInfoFromAPI maps an API ruleset onto RulesetInfo.
+func rulesetInfoFromAPI( //nolint:gocognit // Field mapping is straightforward
+	ruleset *github.RepositoryRuleset,
+) RulesetInfo {
+	info := RulesetInfo{
+		ID:          ruleset.GetID(),
+		Name:        ruleset.Name,
+		Enforcement: string(ruleset.Enforcement),
+	}
+	if ruleset.Target != nil {
+		info.Target = string(*ruleset.Target)
+	}
+	if ruleset.Conditions != nil && ruleset.Conditions.RefName != nil {
+		info.Include = ruleset.Conditions.RefName.Include
+		info.Exclude = ruleset.Conditions.RefName.Exclude
+	}
+	for _, actor := range ruleset.BypassActors {
+		if actor == nil {
+			continue
+		}
+		bypass := RulesetBypassActor{ActorID: actor.GetActorID()}
+		if actor.ActorType != nil {
+			bypass.ActorType = string(*actor.ActorType)
+		}
+		if actor.BypassMode != nil {
+			bypass.BypassMode = string(*actor.BypassMode)
+		}
+		info.BypassActors = append(info.BypassActors, bypass)
+	}
+
+	rules := ruleset.Rules
+	if rules == nil {
+		return info
+	}
+	info.Rules.Creation = rules.Creation != nil
+	info.Rules.Update = rules.Update != nil
+	info.Rules.Deletion = rules.Deletion != nil
+	info.Rules.RequiredLinearHistory = rules.RequiredLinearHistory != nil
+	info.Rules.RequiredSignatures = rules.RequiredSignatures != nil
+	info.Rules.NonFastForward = rules.NonFastForward != nil
+
+	if pr := rules.PullRequest; pr != nil {
+		info.Rules.PullRequest = &RulesetPullRequest{
+			RequiredApprovingReviewCount:   pr.RequiredApprovingReviewCount,
+			DismissStaleReviewsOnPush:      pr.DismissStaleReviewsOnPush,
+			RequireCodeOwnerReview:         pr.RequireCodeOwnerReview,
+			RequireLastPushApproval:        pr.RequireLastPushApproval,
+			RequiredReviewThreadResolution: pr.RequiredReviewThreadResolution,
+		}
+	}
+	if checks := rules.RequiredStatusChecks; checks != nil {
+		info.Rules.RequiredStatusChecks = &RulesetStatusChecks{Strict: checks.StrictRequiredStatusChecksPolicy}
+		for _, check := range checks.RequiredStatusChecks {
+			if check != nil {
+				info.Rules.RequiredStatusChecks.Contexts = append(info.Rules.RequiredStatusChecks.Contexts, check.Context)
+			}
+		}
+	}
+	if rules.RequiredDeployments != nil {
+		info.Rules.RequiredDeployments = rules.RequiredDeployments.RequiredDeploymentEnvironments
+	}
+	info.Rules.CommitMessagePattern = patternRuleFromAPI(rules.CommitMessagePattern)
+	info.Rules.CommitAuthorEmailPattern = patternRuleFromAPI(rules.CommitAuthorEmailPattern)
+	info.Rules.CommitterEmailPattern = patternRuleFromAPI(rules.CommitterEmailPattern)
+	info.Rules.BranchNamePattern = patternRuleFromAPI(rules.BranchNamePattern)
+	info.Rules.TagNamePattern = patternRuleFromAPI(rules.TagNamePattern)
+
+	if rules.FilePathRestriction != nil {
+		info.Rules.FilePathRestriction = rules.FilePathRestriction.RestrictedFilePaths
+	}
+	if rules.FileExtensionRestriction != nil {
+		info.Rules.FileExtensionRestriction = rules.FileExtensionRestriction.RestrictedFileExtensions
+	}
+	if rules.MaxFilePathLength != nil {
+		info.Rules.MaxFilePathLength = rules.MaxFilePathLength.MaxFilePathLength
+	}
+	if rules.MaxFileSize != nil {
+		info.Rules.MaxFileSize = rules.MaxFileSize.MaxFileSize
+	}
+
+	return info
+}
+
+func patternRuleFromAPI(params *github.PatternRuleParameters) *RulesetPatternRule {
+	if params == nil {
+		return nil
+	}
+	return &RulesetPatternRule{
+		Name:     params.GetName(),
+		Operator: string(params.Operator),
+		Pattern:  params.Pattern,
+		Negate:   params.GetNegate(),
+	}
+}
+
+// buildRuleset maps a RulesetInfo onto the API request body.
+func buildRuleset(info *RulesetInfo) github.RepositoryRuleset { //nolint:gocognit // Field mapping is straightforward
+	target := github.RulesetTarget(info.Target)
+	ruleset := github.RepositoryRuleset{
+		Name:         info.Name,
+		Target:       &target,
+		Enforcement:  github.RulesetEnforcement(info.Enforcement),
+		BypassActors: make([]*github.BypassActor, 0, len(info.BypassActors)),
+		Rules:        &github.RepositoryRulesetRules{},
+	}
+	if target != github.RulesetTargetPush {
+		include, exclude := info.Include, info.Exclude
+		if include == nil {
+			include = []string{}
+		}
+		if exclude == nil {
+			exclude = []string{}
+		}
+		ruleset.Conditions = &github.RepositoryRulesetConditions{
+			RefName: &github.RepositoryRulesetRefConditionParameters{Include: include, Exclude: exclude},
+		}
+	}
+	for _, actor := range info.BypassActors {
+		actorType := github.BypassActorType(actor.ActorType)
+		mode := github.BypassMode(actor.BypassMode)
+		bypass := &github.BypassActor{ActorType: &actorType, BypassMode: &mode}
+		if actor.ActorID != 0 {
+			bypass.ActorID = github.Ptr(actor.ActorID)
+		}
+		ruleset.BypassActors = append(ruleset.BypassActors, bypass)
+	}
+
+	rules := ruleset.Rules
+	src := info.Rules
+	if src.Creation {
+		rules.Creation = &github.EmptyRuleParameters{}
+	}
+	if src.Update {
+		rules.Update = &github.UpdateRuleParameters{}
+	}
+	if src.Deletion {
+		rules.Deletion = &github.EmptyRuleParameters{}
+	}
+	if src.RequiredLinearHistory {
+		rules.RequiredLinearHistory = &github.EmptyRuleParameters{}
+	}
+	if src.RequiredSignatures {
+		rules.RequiredSignatures = &github.EmptyRuleParameters{}
+	}
+	if src.NonFastForward {
+		rules.NonFastForward = &github.EmptyRuleParameters{}
+	}
+
+	if pr := src.PullRequest; pr != nil {
+		rules.PullRequest = &github.PullRequestRuleParameters{
+			RequiredApprovingReviewCount:   pr.RequiredApprovingReviewCount,
+			DismissStaleReviewsOnPush:      pr.DismissStaleReviewsOnPush,
+			RequireCodeOwnerReview:         pr.RequireCodeOwnerReview,
+			RequireLastPushApproval:        pr.RequireLastPushApproval,
+			RequiredReviewThreadResolution: pr.RequiredReviewThreadResolution,
+		}
+	}
+	if checks := src.RequiredStatusChecks; checks != nil {
+		params := &github.RequiredStatusChecksRuleParameters{
+			RequiredStatusChecks:             make([]*github.RuleStatusCheck, 0, len(checks.Contexts)),
+			StrictRequiredStatusChecksPolicy: checks.Strict,
+		}
+		for _, check := range checks.Contexts {
+			params.RequiredStatusChecks = append(params.RequiredStatusChecks, &github.RuleStatusCheck{Context: check})
+		}
+		rules.RequiredStatusChecks = params
+	}
+	if len(src.RequiredDeployments) > 0 {
+		rules.RequiredDeployments = &github.RequiredDeploymentsRuleParameters{
+			RequiredDeploymentEnvironments: src.RequiredDeployments,
+		}
+	}
+	rules.CommitMessagePattern = buildPatternRule(src.CommitMessagePattern)
+	rules.CommitAuthorEmailPattern = buildPatternRule(src.CommitAuthorEmailPattern)
+	rules.CommitterEmailPattern = buildPatternRule(src.CommitterEmailPattern)
+	rules.BranchNamePattern = buildPatternRule(src.BranchNamePattern)
+	rules.TagNamePattern = buildPatternRule(src.TagNamePattern)
+
+	if len(src.FilePathRestriction) > 0 {
+		rules.FilePathRestriction = &github.FilePathRestrictionRuleParameters{
+			RestrictedFilePaths: src.FilePathRestriction,
+		}
+	}
+	if len(src.FileExtensionRestriction) > 0 {
+		rules.FileExtensionRestriction = &github.FileExtensionRestrictionRuleParameters{
+			RestrictedFileExtensions: src.FileExtensionRestriction,
+		}
+	}
+	if src.MaxFilePathLength > 0 {
+		rules.MaxFilePathLength = &github.MaxFilePathLengthRuleParameters{MaxFilePathLength: src.MaxFilePathLength}
+	}
+	if src.MaxFileSize > 0 {
+		rules.MaxFileSize = &github.MaxFileSizeRuleParameters{MaxFileSize: src.MaxFileSize}
+	}
+
+	return ruleset
+}
+
+func buildPatternRule(rule *RulesetPatternRule) *github.PatternRuleParameters {
+	if rule == nil {
+		return nil
+	}
+	params := &github.PatternRuleParameters{
+		Operator: github.PatternRuleOperator(rule.Operator),
+		Pattern:  rule.Pattern,
+		Negate:   github.Ptr(rule.Negate),
+	}
+	if rule.Name != "" {
+		params.Name = github.Ptr(rule.Name)
+	}
+	return params
+}
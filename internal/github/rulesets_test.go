@@ -0,0 +1,94 @@
+package github //nolint:testpackage // Tests internal implementation details
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestListRulesets(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/o/r/rulesets", func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("includes_parents"); got != "false" {
+			t.Errorf("includes_parents = %q; want false", got)
+		}
+		fmt.Fprint(w, `[{"id":7,"name":"main","enforcement":"active"}]`)
+	})
+	mux.HandleFunc("GET /repos/o/r/rulesets/7", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `{
+			"id": 7, "name": "main", "target": "branch", "enforcement": "active",
+			"bypass_actors": [{"actor_id": 5, "actor_type": "Team", "bypass_mode": "pull_request"}],
+			"conditions": {"ref_name": {"include": ["~DEFAULT_BRANCH"], "exclude": []}},
+			"rules": [
+				{"type": "deletion"},
+				{"type": "pull_request", "parameters": {"required_approving_review_count": 2,
+					"dismiss_stale_reviews_on_push": true, "require_code_owner_review": false,
+					"require_last_push_approval": false, "required_review_thread_resolution": true}},
+				{"type": "commit_message_pattern", "parameters": {"operator": "regex", "pattern": "^feat"}},
+				{"type": "required_deployments", "parameters": {"required_deployment_environments": ["staging"]}}
+			]
+		}`)
+	})
+	c, _ := newTestClient(t, mux)
+
+	rulesets, err := c.ListRulesets(t.Context(), "o", "r")
+	if err != nil {
+		t.Fatalf("ListRulesets() error = %v", err)
+	}
+
+	want := []RulesetInfo{{
+		ID: 7, Name: "main", Target: "branch", Enforcement: "active",
+		Include:      []string{"~DEFAULT_BRANCH"},
+		Exclude:      []string{},
+		BypassActors: []RulesetBypassActor{{ActorType: "Team", ActorID: 5, BypassMode: "pull_request"}},
+		Rules: RulesetRules{
+			Deletion: true,
+			PullRequest: &RulesetPullRequest{
+				RequiredApprovingReviewCount:   2,
+				DismissStaleReviewsOnPush:      true,
+				RequiredReviewThreadResolution: true,
+			},
+			CommitMessagePattern: &RulesetPatternRule{Operator: "regex", Pattern: "^feat"},
+			RequiredDeployments:  []string{"staging"},
+		},
+	}}
+	if !reflect.DeepEqual(rulesets, want) {
+		t.Fatalf("ListRulesets() = %+v; want %+v", rulesets, want)
+	}
+}
+
+func TestCreateRuleset_PushTarget(t *testing.T) {
+	var body map[string]any
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /repos/o/r/rulesets", func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decode body: %v", err)
+		}
+		fmt.Fprint(w, `{"id":1,"name":"push"}`)
+	})
+	c, _ := newTestClient(t, mux)
+
+	err := c.CreateRuleset(t.Context(), "o", "r", &RulesetInfo{
+		Name:        "push",
+		Target:      "push",
+		Enforcement: "active",
+		Rules:       RulesetRules{FilePathRestriction: []string{"secrets/**"}, MaxFileSize: 10},
+	})
+	if err != nil {
+		t.Fatalf("CreateRuleset() error = %v", err)
+	}
+
+	if _, ok := body["conditions"]; ok {
+		t.Fatalf("conditions = %v; want none for push rulesets", body["conditions"])
+	}
+	rules, _ := body["rules"].([]any)
+	types := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		types = append(types, rule.(map[string]any)["type"].(string)) //nolint:forcetypeassert // Test fixture
+	}
+	if want := []string{"file_path_restriction", "max_file_size"}; !reflect.DeepEqual(types, want) {
+		t.Fatalf("rule types = %v; want %v", types, want)
+	}
+}
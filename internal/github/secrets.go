@@ -0,0 +1,82 @@
+package github
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/go-github/v82/github"
+	"golang.org/x/crypto/nacl/box"
+)
+
+// publicKeySize is the size of a repository's Curve25519 public key.
+const publicKeySize = 32
+
+// SecretInfo holds a repository Actions secret. GitHub never returns the
+// secret value.
+type SecretInfo struct {
+	Name      string    `json:"name"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ListSecrets fetches the Actions secrets defined on a repository.
+func (c *Client) ListSecrets(ctx context.Context, owner, name string) ([]SecretInfo, error) {
+	var secrets []SecretInfo
+	opts := &github.ListOptions{PerPage: listPageSize}
+	for {
+		page, resp, err := c.client.Actions.ListRepoSecrets(ctx, owner, name, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list secrets for %s/%s: %w", owner, name, err)
+		}
+		for _, secret := range page.Secrets {
+			secrets = append(secrets, SecretInfo{Name: secret.Name, UpdatedAt: secret.UpdatedAt.Time})
+		}
+		if resp == nil || resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return secrets, nil
+}
+
+// SetSecret creates or updates a repository Actions secret. The value is
+// encrypted with the repository's public key before it is sent.
+func (c *Client) SetSecret(ctx context.Context, owner, name, secret, value string) error {
+	key, _, err := c.client.Actions.GetRepoPublicKey(ctx, owner, name)
+	if err != nil {
+		return fmt.Errorf("failed to get public key for %s/%s: %w", owner, name, err)
+	}
+	encrypted, err := sealSecret(key.GetKey(), value)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt secret %s: %w", secret, err)
+	}
+
+	payload := &github.EncryptedSecret{Name: secret, KeyID: key.GetKeyID(), EncryptedValue: encrypted}
+	if _, putErr := c.client.Actions.CreateOrUpdateRepoSecret(ctx, owner, name, payload); putErr != nil {
+		return fmt.Errorf("failed to set secret %s: %w", secret, putErr)
+	}
+	return nil
+}
+
+// sealSecret encrypts value as a libsodium sealed box for the base64-encoded
+// public key, returning the base64-encoded ciphertext GitHub expects.
+func sealSecret(publicKey, value string) (string, error) {
+	decoded, err := base64.StdEncoding.DecodeString(publicKey)
+	if err != nil {
+		return "", fmt.Errorf("invalid public key: %w", err)
+	}
+	if len(decoded) != publicKeySize {
+		return "", errors.New("invalid public key: wrong length")
+	}
+
+	var key [publicKeySize]byte
+	copy(key[:], decoded)
+	sealed, err := box.SealAnonymous(nil, []byte(value), &key, rand.Reader)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
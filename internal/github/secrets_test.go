@@ -0,0 +1,67 @@
+package github //nolint:testpackage // Tests internal implementation details
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"golang.org/x/crypto/nacl/box"
+)
+
+func TestSetSecret_SealsValueWithRepositoryKey(t *testing.T) {
+	publicKey, privateKey, err := box.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+
+	var body struct {
+		KeyID          string `json:"key_id"`
+		EncryptedValue string `json:"encrypted_value"`
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/o/r/actions/secrets/public-key", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprintf(w, `{"key_id":"k1","key":%q}`, base64.StdEncoding.EncodeToString(publicKey[:]))
+	})
+	mux.HandleFunc("PUT /repos/o/r/actions/secrets/NPM_TOKEN", func(w http.ResponseWriter, r *http.Request) {
+		if decodeErr := json.NewDecoder(r.Body).Decode(&body); decodeErr != nil {
+			t.Errorf("decode body: %v", decodeErr)
+		}
+		w.WriteHeader(http.StatusCreated)
+	})
+	c, _ := newTestClient(t, mux)
+
+	if setErr := c.SetSecret(t.Context(), "o", "r", "NPM_TOKEN", "s3cret"); setErr != nil {
+		t.Fatalf("SetSecret() error = %v", setErr)
+	}
+	if body.KeyID != "k1" {
+		t.Fatalf("key_id = %q; want k1", body.KeyID)
+	}
+	sealed, err := base64.StdEncoding.DecodeString(body.EncryptedValue)
+	if err != nil {
+		t.Fatalf("DecodeString() error = %v", err)
+	}
+	opened, ok := box.OpenAnonymous(nil, sealed, publicKey, privateKey)
+	if !ok || string(opened) != "s3cret" {
+		t.Fatalf("OpenAnonymous() = %q, %v; want s3cret, true", opened, ok)
+	}
+}
+
+func TestSealSecret_InvalidKey(t *testing.T) {
+	tests := []struct {
+		name string
+		key  string
+	}{
+		{name: "not base64", key: "!!!"},
+		{name: "wrong length", key: base64.StdEncoding.EncodeToString([]byte("short"))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := sealSecret(tt.key, "value"); err == nil {
+				t.Fatal("sealSecret() error = nil; want error")
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package github
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-github/v82/github"
+)
+
+// Security features that can be enabled or disabled on a repository.
+const (
+	FeatureDependabotAlerts              = "dependabot_alerts"
+	FeatureDependabotSecurityUpdates     = "dependabot_security_updates"
+	FeatureSecretScanning                = "secret_scanning"
+	FeatureSecretScanningPushProtection  = "secret_scanning_push_protection"
+	FeaturePrivateVulnerabilityReporting = "private_vulnerability_reporting"
+	FeatureCodeScanningDefaultSetup      = "code_scanning_default_setup"
+)
+
+// Status values used by the security_and_analysis settings and code
+// scanning default setup.
+const (
+	statusEnabled             = "enabled"
+	statusDisabled            = "disabled"
+	defaultSetupConfigured    = "configured"
+	defaultSetupNotConfigured = "not-configured"
+)
+
+// SecurityAndAnalysis holds the security features GitHub reports with a
+// repository.
+type SecurityAndAnalysis struct {
+	DependabotSecurityUpdates    bool `json:"dependabot_security_updates"`
+	SecretScanning               bool `json:"secret_scanning"`
+	SecretScanningPushProtection bool `json:"secret_scanning_push_protection"`
+}
+
+// securityAndAnalysis converts the security_and_analysis settings of a repository.
+func securityAndAnalysis(settings *github.SecurityAndAnalysis) SecurityAndAnalysis {
+	return SecurityAndAnalysis{
+		DependabotSecurityUpdates:    settings.GetDependabotSecurityUpdates().GetStatus() == statusEnabled,
+		SecretScanning:               settings.GetSecretScanning().GetStatus() == statusEnabled,
+		SecretScanningPushProtection: settings.GetSecretScanningPushProtection().GetStatus() == statusEnabled,
+	}
+}
+
+// GetSecurityFeature reports whether a security feature with its own
+// endpoint is enabled: FeatureDependabotAlerts,
+// FeaturePrivateVulnerabilityReporting or FeatureCodeScanningDefaultSetup.
+// The other features are read by GetRepository.
+func (c *Client) GetSecurityFeature(ctx context.Context, owner, name, feature string) (bool, error) {
+	var (
+		enabled bool
+		err     error
+	)
+	switch feature {
+	case FeatureDependabotAlerts:
+		enabled, _, err = c.client.Repositories.GetVulnerabilityAlerts(ctx, owner, name)
+	case FeaturePrivateVulnerabilityReporting:
+		enabled, _, err = c.client.Repositories.IsPrivateReportingEnabled(ctx, owner, name)
+	case FeatureCodeScanningDefaultSetup:
+		var setup *github.DefaultSetupConfiguration
+		setup, _, err = c.client.CodeScanning.GetDefaultSetupConfiguration(ctx, owner, name)
+		enabled = setup.GetState() == defaultSetupConfigured
+	default:
+		return false, fmt.Errorf("unknown security feature %q", feature)
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get %s for %s/%s: %w", feature, owner, name, err)
+	}
+	return enabled, nil
+}
+
+// SetSecurityFeature enables or disables a security feature.
+func (c *Client) SetSecurityFeature(ctx context.Context, owner, name, feature string, enabled bool) error {
+	var err error
+	switch feature {
+	case FeatureDependabotAlerts:
+		if enabled {
+			_, err = c.client.Repositories.EnableVulnerabilityAlerts(ctx, owner, name)
+		} else {
+			_, err = c.client.Repositories.DisableVulnerabilityAlerts(ctx, owner, name)
+		}
+	case FeaturePrivateVulnerabilityReporting:
+		if enabled {
+			_, err = c.client.Repositories.EnablePrivateReporting(ctx, owner, name)
+		} else {
+			_, err = c.client.Repositories.DisablePrivateReporting(ctx, owner, name)
+		}
+	case FeatureCodeScanningDefaultSetup:
+		state := defaultSetupNotConfigured
+		if enabled {
+			state = defaultSetupConfigured
+		}
+		options := &github.UpdateDefaultSetupConfigurationOptions{State: state}
+		_, _, err = c.client.CodeScanning.UpdateDefaultSetupConfiguration(ctx, owner, name, options)
+	case FeatureDependabotSecurityUpdates, FeatureSecretScanning, FeatureSecretScanningPushProtection:
+		_, _, err = c.client.Repositories.Edit(ctx, owner, name, &github.Repository{
+			SecurityAndAnalysis: securityAndAnalysisPatch(feature, enabled),
+		})
+	default:
+		return fmt.Errorf("unknown security feature %q", feature)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update %s: %w", feature, err)
+	}
+	return nil
+}
+
+// securityAndAnalysisPatch returns a security_and_analysis patch that sets
+// a single feature.
+func securityAndAnalysisPatch(feature string, enabled bool) *github.SecurityAndAnalysis {
+	status := github.Ptr(statusDisabled)
+	if enabled {
+		status = github.Ptr(statusEnabled)
+	}
+	patch := &github.SecurityAndAnalysis{}
+	switch feature {
+	case FeatureDependabotSecurityUpdates:
+		patch.DependabotSecurityUpdates = &github.DependabotSecurityUpdates{Status: status}
+	case FeatureSecretScanning:
+		patch.SecretScanning = &github.SecretScanning{Status: status}
+	case FeatureSecretScanningPushProtection:
+		patch.SecretScanningPushProtection = &github.SecretScanningPushProtection{Status: status}
+	}
+	return patch
+}
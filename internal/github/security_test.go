@@ -0,0 +1,107 @@
+package github //nolint:testpackage // Tests internal implementation details
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestGetRepository_SecurityAndAnalysis(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/o/r", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `{"name":"r","security_and_analysis":{
+			"dependabot_security_updates":{"status":"enabled"},
+			"secret_scanning":{"status":"enabled"},
+			"secret_scanning_push_protection":{"status":"disabled"}}}`)
+	})
+	c, _ := newTestClient(t, mux)
+
+	info, err := c.GetRepository(t.Context(), "o", "r")
+	if err != nil {
+		t.Fatalf("GetRepository() error = %v", err)
+	}
+	want := SecurityAndAnalysis{DependabotSecurityUpdates: true, SecretScanning: true}
+	if info.SecurityAndAnalysis != want {
+		t.Fatalf("SecurityAndAnalysis = %+v; want %+v", info.SecurityAndAnalysis, want)
+	}
+}
+
+func TestGetSecurityFeature(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/o/r/vulnerability-alerts", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("GET /repos/o/r/private-vulnerability-reporting", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `{"enabled":false}`)
+	})
+	mux.HandleFunc("GET /repos/o/r/code-scanning/default-setup", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `{"state":"configured"}`)
+	})
+	c, _ := newTestClient(t, mux)
+
+	want := map[string]bool{
+		FeatureDependabotAlerts:              true,
+		FeaturePrivateVulnerabilityReporting: false,
+		FeatureCodeScanningDefaultSetup:      true,
+	}
+	for feature, wantEnabled := range want {
+		enabled, err := c.GetSecurityFeature(t.Context(), "o", "r", feature)
+		if err != nil {
+			t.Fatalf("GetSecurityFeature(%s) error = %v", feature, err)
+		}
+		if enabled != wantEnabled {
+			t.Fatalf("GetSecurityFeature(%s) = %v; want %v", feature, enabled, wantEnabled)
+		}
+	}
+
+	if _, err := c.GetSecurityFeature(t.Context(), "o", "r", FeatureSecretScanning); err == nil {
+		t.Fatal("GetSecurityFeature(secret_scanning) error = nil; want error")
+	}
+}
+
+func TestSetSecurityFeature(t *testing.T) {
+	var calls []string
+	var patch map[string]any
+	mux := http.NewServeMux()
+	mux.HandleFunc("PUT /repos/o/r/vulnerability-alerts", func(w http.ResponseWriter, _ *http.Request) {
+		calls = append(calls, "enable alerts")
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("DELETE /repos/o/r/private-vulnerability-reporting", func(w http.ResponseWriter, _ *http.Request) {
+		calls = append(calls, "disable private reporting")
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("PATCH /repos/o/r", func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, "edit repository")
+		if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+			t.Errorf("decode body: %v", err)
+		}
+		fmt.Fprint(w, `{"name":"r"}`)
+	})
+	c, _ := newTestClient(t, mux)
+
+	if err := c.SetSecurityFeature(t.Context(), "o", "r", FeatureDependabotAlerts, true); err != nil {
+		t.Fatalf("SetSecurityFeature(alerts) error = %v", err)
+	}
+	if err := c.SetSecurityFeature(t.Context(), "o", "r", FeaturePrivateVulnerabilityReporting, false); err != nil {
+		t.Fatalf("SetSecurityFeature(private reporting) error = %v", err)
+	}
+	if err := c.SetSecurityFeature(t.Context(), "o", "r", FeatureSecretScanningPushProtection, true); err != nil {
+		t.Fatalf("SetSecurityFeature(push protection) error = %v", err)
+	}
+
+	wantCalls := []string{"enable alerts", "disable private reporting", "edit repository"}
+	if !reflect.DeepEqual(calls, wantCalls) {
+		t.Fatalf("calls = %v; want %v", calls, wantCalls)
+	}
+	wantPatch := map[string]any{
+		"security_and_analysis": map[string]any{
+			"secret_scanning_push_protection": map[string]any{"status": "enabled"},
+		},
+	}
+	if !reflect.DeepEqual(patch, wantPatch) {
+		t.Fatalf("patch = %v; want %v", patch, wantPatch)
+	}
+}
@@ -0,0 +1,94 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/google/go-github/v82/github"
+)
+
+// VariableInfo holds a repository Actions variable.
+type VariableInfo struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// ListVariables fetches the Actions variables defined on a repository.
+func (c *Client) ListVariables(ctx context.Context, owner, name string) ([]VariableInfo, error) {
+	var variables []VariableInfo
+	opts := &github.ListOptions{PerPage: listPageSize}
+	for {
+		page, resp, err := c.client.Actions.ListRepoVariables(ctx, owner, name, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list variables for %s/%s: %w", owner, name, err)
+		}
+		for _, variable := range page.Variables {
+			variables = append(variables, VariableInfo{Name: variable.Name, Value: variable.Value})
+		}
+		if resp == nil || resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return variables, nil
+}
+
+// CreateVariable creates a repository Actions variable.
+func (c *Client) CreateVariable(ctx context.Context, owner, name string, variable *VariableInfo) error {
+	payload := &github.ActionsVariable{Name: variable.Name, Value: variable.Value}
+	if _, err := c.client.Actions.CreateRepoVariable(ctx, owner, name, payload); err != nil {
+		return fmt.Errorf("failed to create variable %s: %w", variable.Name, err)
+	}
+	return nil
+}
+
+// UpdateVariable updates the value of a repository Actions variable.
+func (c *Client) UpdateVariable(ctx context.Context, owner, name string, variable *VariableInfo) error {
+	payload := &github.ActionsVariable{Name: variable.Name, Value: variable.Value}
+	if _, err := c.client.Actions.UpdateRepoVariable(ctx, owner, name, payload); err != nil {
+		return fmt.Errorf("failed to update variable %s: %w", variable.Name, err)
+	}
+	return nil
+}
+
+// ListEnvironmentVariables fetches the Actions variables scoped to a
+// deployment environment.
+func (c *Client) ListEnvironmentVariables(ctx context.Context, owner, name, env string) ([]VariableInfo, error) {
+	var variables []VariableInfo
+	opts := &github.ListOptions{PerPage: listPageSize}
+	for {
+		page, resp, err := c.client.Actions.ListEnvVariables(ctx, owner, name, url.PathEscape(env), opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list variables for environment %s: %w", env, err)
+		}
+		for _, variable := range page.Variables {
+			variables = append(variables, VariableInfo{Name: variable.Name, Value: variable.Value})
+		}
+		if resp == nil || resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return variables, nil
+}
+
+// CreateEnvironmentVariable creates an Actions variable scoped to a
+// deployment environment.
+func (c *Client) CreateEnvironmentVariable(ctx context.Context, owner, name, env string, variable *VariableInfo) error {
+	payload := &github.ActionsVariable{Name: variable.Name, Value: variable.Value}
+	if _, err := c.client.Actions.CreateEnvVariable(ctx, owner, name, url.PathEscape(env), payload); err != nil {
+		return fmt.Errorf("failed to create variable %s in environment %s: %w", variable.Name, env, err)
+	}
+	return nil
+}
+
+// UpdateEnvironmentVariable updates the value of an Actions variable scoped
+// to a deployment environment.
+func (c *Client) UpdateEnvironmentVariable(ctx context.Context, owner, name, env string, variable *VariableInfo) error {
+	payload := &github.ActionsVariable{Name: variable.Name, Value: variable.Value}
+	if _, err := c.client.Actions.UpdateEnvVariable(ctx, owner, name, url.PathEscape(env), payload); err != nil {
+		return fmt.Errorf("failed to update variable %s in environment %s: %w", variable.Name, env, err)
+	}
+	return nil
+}
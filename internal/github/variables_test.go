@@ -0,0 +1,30 @@
+package github //nolint:testpackage // Tests internal implementation details
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestListVariables_Paginates(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/o/r/actions/variables", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "2" {
+			fmt.Fprint(w, `{"total_count":2,"variables":[{"name":"REGION","value":"us-east-1"}]}`)
+			return
+		}
+		w.Header().Set("Link", `<https://api.github.com/repos/o/r/actions/variables?page=2>; rel="next"`)
+		fmt.Fprint(w, `{"total_count":2,"variables":[{"name":"STAGE","value":"prod"}]}`)
+	})
+	c, _ := newTestClient(t, mux)
+
+	variables, err := c.ListVariables(t.Context(), "o", "r")
+	if err != nil {
+		t.Fatalf("ListVariables() error = %v", err)
+	}
+	want := []VariableInfo{{Name: "STAGE", Value: "prod"}, {Name: "REGION", Value: "us-east-1"}}
+	if !reflect.DeepEqual(variables, want) {
+		t.Fatalf("ListVariables() = %+v; want %+v", variables, want)
+	}
+}
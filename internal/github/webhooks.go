@@ -0,0 +1,102 @@
+package github
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-github/v82/github"
+)
+
+// WebhookInfo holds a repository webhook.
+type WebhookInfo struct {
+	// ID identifies an existing webhook; it is zero for webhooks not yet created.
+	ID int64 `json:"-"`
+
+	URL         string   `json:"url"`
+	ContentType string   `json:"content_type"`
+	Events      []string `json:"events"`
+	Active      bool     `json:"active"`
+
+	// HasSecret reports whether the webhook has a secret. GitHub never
+	// returns the secret itself.
+	HasSecret bool `json:"has_secret"`
+
+	// Secret is sent when creating or updating a webhook. It is never read
+	// from GitHub and never serialized.
+	Secret string `json:"-"`
+}
+
+// ListWebhooks fetches the webhooks defined on a repository.
+func (c *Client) ListWebhooks(ctx context.Context, owner, name string) ([]WebhookInfo, error) {
+	var webhooks []WebhookInfo
+	opts := &github.ListOptions{PerPage: listPageSize}
+	for {
+		hooks, resp, err := c.client.Repositories.ListHooks(ctx, owner, name, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list webhooks for %s/%s: %w", owner, name, err)
+		}
+		for _, hook := range hooks {
+			config := hook.GetConfig()
+			webhooks = append(webhooks, WebhookInfo{
+				ID:          hook.GetID(),
+				URL:         config.GetURL(),
+				ContentType: config.GetContentType(),
+				Events:      hook.Events,
+				Active:      hook.GetActive(),
+				HasSecret:   config.GetSecret() != "",
+			})
+		}
+		if resp == nil || resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return webhooks, nil
+}
+
+// CreateWebhook creates a repository webhook.
+func (c *Client) CreateWebhook(ctx context.Context, owner, name string, webhook *WebhookInfo) error {
+	hook := &github.Hook{
+		Config: hookConfig(webhook),
+		Events: webhook.Events,
+		Active: github.Ptr(webhook.Active),
+	}
+	if _, _, err := c.client.Repositories.CreateHook(ctx, owner, name, hook); err != nil {
+		return fmt.Errorf("failed to create webhook %s: %w", webhook.URL, err)
+	}
+	return nil
+}
+
+// UpdateWebhook updates the repository webhook with the given ID. The secret
+// is only changed when webhook.Secret is set.
+func (c *Client) UpdateWebhook(ctx context.Context, owner, name string, id int64, webhook *WebhookInfo) error {
+	hook := &github.Hook{Events: webhook.Events, Active: github.Ptr(webhook.Active)}
+	if _, _, err := c.client.Repositories.EditHook(ctx, owner, name, id, hook); err != nil {
+		return fmt.Errorf("failed to update webhook %s: %w", webhook.URL, err)
+	}
+	// The configuration endpoint updates only the fields it is given, so an
+	// existing secret is kept unless a new one is sent.
+	if _, _, err := c.client.Repositories.EditHookConfiguration(ctx, owner, name, id, hookConfig(webhook)); err != nil {
+		return fmt.Errorf("failed to update webhook %s: %w", webhook.URL, err)
+	}
+	return nil
+}
+
+// DeleteWebhook deletes the repository webhook with the given ID.
+func (c *Client) DeleteWebhook(ctx context.Context, owner, name string, id int64) error {
+	if _, err := c.client.Repositories.DeleteHook(ctx, owner, name, id); err != nil {
+		return fmt.Errorf("failed to delete webhook %d: %w", id, err)
+	}
+	return nil
+}
+
+func hookConfig(webhook *WebhookInfo) *github.HookConfig {
+	config := &github.HookConfig{
+		URL:         github.Ptr(webhook.URL),
+		ContentType: github.Ptr(webhook.ContentType),
+	}
+	if webhook.Secret != "" {
+		config.Secret = github.Ptr(webhook.Secret)
+	}
+	return config
+}
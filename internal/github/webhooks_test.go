@@ -0,0 +1,61 @@
+package github //nolint:testpackage // Tests internal implementation details
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestListWebhooks(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/o/r/hooks", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `[{"id":3,"active":true,"events":["push"],
+			"config":{"url":"https://ci.example.com/hook","content_type":"json","secret":"********"}}]`)
+	})
+	c, _ := newTestClient(t, mux)
+
+	hooks, err := c.ListWebhooks(t.Context(), "o", "r")
+	if err != nil {
+		t.Fatalf("ListWebhooks() error = %v", err)
+	}
+	want := []WebhookInfo{{
+		ID: 3, URL: "https://ci.example.com/hook", ContentType: "json",
+		Events: []string{"push"}, Active: true, HasSecret: true,
+	}}
+	if !reflect.DeepEqual(hooks, want) {
+		t.Fatalf("ListWebhooks() = %+v; want %+v", hooks, want)
+	}
+}
+
+func TestUpdateWebhook_KeepsSecretWhenUnset(t *testing.T) {
+	var config map[string]any
+	mux := http.NewServeMux()
+	mux.HandleFunc("PATCH /repos/o/r/hooks/3", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `{"id":3}`)
+	})
+	mux.HandleFunc("PATCH /repos/o/r/hooks/3/config", func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&config); err != nil {
+			t.Errorf("decode body: %v", err)
+		}
+		fmt.Fprint(w, `{}`)
+	})
+	c, _ := newTestClient(t, mux)
+
+	webhook := &WebhookInfo{URL: "https://ci.example.com/hook", ContentType: "json", Events: []string{"push"}}
+	if err := c.UpdateWebhook(t.Context(), "o", "r", 3, webhook); err != nil {
+		t.Fatalf("UpdateWebhook() error = %v", err)
+	}
+	if _, ok := config["secret"]; ok {
+		t.Fatalf("config = %v; want no secret", config)
+	}
+
+	data, err := json.Marshal(WebhookInfo{Secret: "s3cret"})
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if string(data) != `{"url":"","content_type":"","events":null,"active":false,"has_secret":false}` {
+		t.Fatalf("Marshal() = %s; want no secret", data)
+	}
+}
@@ -0,0 +1,171 @@
+// Package importer builds a configuration from the live state of existing repositories.
+package importer
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mholtzscher/github-janitor/internal/config"
+	"github.com/mholtzscher/github-janitor/internal/github"
+)
+
+// Importer reads repository settings and turns them into configuration.
+type Importer struct {
+	client githubAPI
+}
+
+type githubAPI interface {
+	GetRepository(ctx context.Context, owner, name string) (*github.RepositoryInfo, error)
+	GetBranchProtection(ctx context.Context, owner, name, pattern string) (*github.BranchProtectionInfo, error)
+}
+
+// Options controls how repositories are imported.
+type Options struct {
+	// Branch is the branch whose protection is imported.
+	// When empty, each repository's default branch is used.
+	Branch string
+
+	// Factor moves settings shared by every repository into the global
+	// settings block, leaving only differences as per-repository overrides.
+	Factor bool
+}
+
+// NewImporter creates a new importer instance.
+func NewImporter(client *github.Client) *Importer {
+	return &Importer{client: client}
+}
+
+// Import reads the live settings of each repository and returns a config
+// that reproduces them.
+func (i *Importer) Import(ctx context.Context, repos []config.Repository, opts Options) (*config.Config, error) {
+	cfg := &config.Config{Repositories: make([]config.Repository, 0, len(repos))}
+
+	for _, repo := range repos {
+		info, err := i.client.GetRepository(ctx, repo.Owner, repo.Name)
+		if err != nil {
+			return nil, err
+		}
+		if !info.Exists {
+			return nil, fmt.Errorf("repository %s does not exist", repo.FullName())
+		}
+
+		branch := opts.Branch
+		if branch == "" {
+			branch = info.DefaultBranch
+		}
+
+		var protection *github.BranchProtectionInfo
+		if branch != "" {
+			protection, err = i.client.GetBranchProtection(ctx, repo.Owner, repo.Name, branch)
+			if err != nil {
+				return nil, fmt.Errorf("repository %s: %w", repo.FullName(), err)
+			}
+		}
+
+		settings := SettingsFromState(info, protection)
+		cfg.Repositories = append(cfg.Repositories, config.Repository{
+			Owner:    repo.Owner,
+			Name:     repo.Name,
+			Settings: &settings,
+		})
+	}
+
+	if opts.Factor {
+		cfg.Settings = config.FactorSettings(cfg.Repositories)
+	}
+
+	return cfg, nil
+}
+
+// SettingsFromState converts live repository state into a settings block.
+// Empty metadata (description, homepage, topics) is left unset.
+func SettingsFromState(info *github.RepositoryInfo, protection *github.BranchProtectionInfo) config.Settings {
+	visibility := config.VisibilityPublic
+	if info.Private {
+		visibility = config.VisibilityPrivate
+	}
+
+	settings := config.Settings{
+		AllowMergeCommit:    ptr(info.AllowMergeCommit),
+		AllowSquashMerge:    ptr(info.AllowSquashMerge),
+		AllowRebaseMerge:    ptr(info.AllowRebaseMerge),
+		DeleteBranchOnMerge: ptr(info.DeleteBranchOnMerge),
+
+		SquashMergeCommitTitle:   nonEmpty(info.SquashMergeCommitTitle),
+		SquashMergeCommitMessage: nonEmpty(info.SquashMergeCommitMessage),
+		MergeCommitTitle:         nonEmpty(info.MergeCommitTitle),
+		MergeCommitMessage:       nonEmpty(info.MergeCommitMessage),
+
+		Visibility:     &visibility,
+		HasIssues:      ptr(info.HasIssues),
+		HasProjects:    ptr(info.HasProjects),
+		HasWiki:        ptr(info.HasWiki),
+		HasDiscussions: ptr(info.HasDiscussions),
+		Archived:       ptr(info.Archived),
+
+		AllowUpdateBranch:        ptr(info.AllowUpdateBranch),
+		WebCommitSignoffRequired: ptr(info.WebCommitSignoffRequired),
+		AllowForking:             ptr(info.AllowForking),
+
+		Description: nonEmpty(info.Description),
+		Homepage:    nonEmpty(info.Homepage),
+
+		DefaultBranch:  nonEmpty(info.DefaultBranch),
+		AllowAutoMerge: ptr(info.AllowAutoMerge),
+
+		GitHubPages: &config.GitHubPages{Enabled: ptr(info.GitHubPagesEnabled)},
+	}
+	if len(info.Topics) > 0 {
+		settings.Topics = append([]string(nil), info.Topics...)
+	}
+
+	if protection != nil {
+		settings.BranchProtection = config.BranchProtectionRules{branchProtectionFromState(protection)}
+	}
+
+	return settings
+}
+
+func branchProtectionFromState(protection *github.BranchProtectionInfo) config.BranchProtection {
+	bp := config.BranchProtection{
+		Enabled: ptr(protection.Enabled),
+		Pattern: protection.Pattern,
+	}
+	if !protection.Enabled {
+		return bp
+	}
+
+	if protection.PullRequestReviewsEnabled {
+		bp.RequiredReviews = ptr(protection.RequiredReviews)
+		bp.DismissStaleReviews = ptr(protection.DismissStaleReviews)
+		bp.RequireCodeOwnerReviews = ptr(protection.RequireCodeOwnerReviews)
+	}
+
+	bp.RequireStatusChecks = ptr(protection.StatusChecksEnabled)
+	if protection.StatusChecksEnabled {
+		bp.RequireBranchesUpToDate = ptr(protection.RequireBranchesUpToDate)
+		if len(protection.StatusCheckContexts) > 0 {
+			bp.StatusCheckContexts = append([]string(nil), protection.StatusCheckContexts...)
+		}
+	}
+
+	bp.IncludeAdmins = ptr(protection.IncludeAdmins)
+	bp.RequireLinearHistory = ptr(protection.RequireLinearHistory)
+	bp.RequireSignedCommits = ptr(protection.RequireSignedCommits)
+	bp.RequireConversationResolution = ptr(protection.RequireConversationResolution)
+	bp.AllowForcePushes = ptr(protection.AllowForcePushes)
+	bp.AllowDeletions = ptr(protection.AllowDeletions)
+
+	return bp
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func nonEmpty(v string) *string {
+	if v == "" {
+		return nil
+	}
+	return &v
+}
@@ -0,0 +1,104 @@
+package importer //nolint:testpackage // Tests internal implementation details
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/mholtzscher/github-janitor/internal/config"
+	"github.com/mholtzscher/github-janitor/internal/github"
+)
+
+type fakeGitHubClient struct {
+	repos      map[string]*github.RepositoryInfo
+	protection map[string]*github.BranchProtectionInfo
+}
+
+func (f *fakeGitHubClient) GetRepository(_ context.Context, owner, name string) (*github.RepositoryInfo, error) {
+	if info, ok := f.repos[owner+"/"+name]; ok {
+		return info, nil
+	}
+	return &github.RepositoryInfo{Owner: owner, Name: name, Exists: false}, nil
+}
+
+func (f *fakeGitHubClient) GetBranchProtection(_ context.Context, owner, name, pattern string) (*github.BranchProtectionInfo, error) {
+	if info, ok := f.protection[owner+"/"+name+":"+pattern]; ok {
+		return info, nil
+	}
+	return &github.BranchProtectionInfo{Enabled: false, Pattern: pattern}, nil
+}
+
+func TestImport_FactorsSharedSettings(t *testing.T) {
+	fake := &fakeGitHubClient{
+		repos: map[string]*github.RepositoryInfo{
+			"o/api": {
+				Exists: true, AllowSquashMerge: true, HasIssues: true, HasWiki: false,
+				DefaultBranch: "main", Description: "API", Topics: []string{"go"},
+			},
+			"o/docs": {
+				Exists: true, AllowSquashMerge: true, HasIssues: true, HasWiki: true,
+				DefaultBranch: "main", Description: "Docs", Topics: []string{"go"},
+			},
+		},
+		protection: map[string]*github.BranchProtectionInfo{
+			"o/api:main": {
+				Enabled: true, Pattern: "main", PullRequestReviewsEnabled: true, RequiredReviews: 2,
+			},
+			"o/docs:main": {
+				Enabled: true, Pattern: "main", PullRequestReviewsEnabled: true, RequiredReviews: 1,
+			},
+		},
+	}
+
+	i := &Importer{client: fake}
+	cfg, err := i.Import(t.Context(),
+		[]config.Repository{{Owner: "o", Name: "api"}, {Owner: "o", Name: "docs"}},
+		Options{Factor: true},
+	)
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	global := cfg.Settings
+	if global.AllowSquashMerge == nil || !*global.AllowSquashMerge {
+		t.Fatalf("global AllowSquashMerge = %v; want true", global.AllowSquashMerge)
+	}
+	if global.HasWiki != nil {
+		t.Fatalf("global HasWiki = %v; want unset (differs per repo)", *global.HasWiki)
+	}
+	if !reflect.DeepEqual(global.Topics, []string{"go"}) {
+		t.Fatalf("global Topics = %v; want [go]", global.Topics)
+	}
+	if len(global.BranchProtection) != 1 || global.BranchProtection[0].Pattern != "main" ||
+		global.BranchProtection[0].RequiredReviews != nil {
+		t.Fatalf("global BranchProtection = %+v; want shared pattern only", global.BranchProtection)
+	}
+
+	docs := cfg.Repositories[1].Settings
+	if docs == nil || docs.HasWiki == nil || !*docs.HasWiki || docs.Description == nil || *docs.Description != "Docs" {
+		t.Fatalf("docs override = %+v; want has_wiki true and description Docs", docs)
+	}
+	if docs.AllowSquashMerge != nil {
+		t.Fatal("docs override repeats a shared setting")
+	}
+
+	// Resolving the factored config must reproduce each repository's live state.
+	for _, repo := range cfg.Repositories {
+		got, _ := cfg.SettingsFor(repo)
+		info, _ := fake.GetRepository(t.Context(), repo.Owner, repo.Name)
+		protection, _ := fake.GetBranchProtection(t.Context(), repo.Owner, repo.Name, "main")
+		if want := SettingsFromState(info, protection); !reflect.DeepEqual(got, want) {
+			t.Fatalf("SettingsFor(%s) = %+v; want %+v", repo.FullName(), got, want)
+		}
+	}
+}
+
+func TestImport_MissingRepository(t *testing.T) {
+	i := &Importer{client: &fakeGitHubClient{}}
+	if _, err := i.Import(t.Context(), []config.Repository{{Owner: "o", Name: "nope"}}, Options{}); err == nil {
+		t.Fatal("Import() error = nil; want error")
+	}
+}
@@ -0,0 +1,140 @@
+// Package report serializes sync results into machine-readable documents.
+//
+// The document schema is versioned by SchemaVersion. Fields are only ever
+// added within a schema version; renaming or removing a field, or changing
+// its meaning, bumps the version.
+//
+// Schema (version 1):
+//
+//	schema_version: int     always 1 for this layout
+//	dry_run:        bool    true for plan, false when changes were applied
+//	results:        list
+//	  - repository: string  "owner/name"
+//	    branch:     string  protected branch the result covers (omitted for the
+//	                         repository itself)
+//	    exists:     bool    false when the repository was not found
+//	    skipped:    string  reason the repository was not synced (omitted if synced)
+//	    not_processed: bool  true if the run stopped before reaching the repository
+//	                         (omitted otherwise)
+//	    error:      string  error message (omitted on success)
+//	    changes:    list
+//	      - field:   string  setting name, e.g. "allow_merge_commit"
+//	        current: any     value currently set on GitHub
+//	        desired: any     value from the configuration
+//	        source:  string  config layer of the desired value (omitted if unknown)
+//	        removal: bool    true if the change deletes something, such as a
+//	                         team's access (omitted otherwise)
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/mholtzscher/github-janitor/internal/sync"
+)
+
+// SchemaVersion is the version of the report document layout.
+const SchemaVersion = 1
+
+// Output formats.
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+	FormatYAML = "yaml"
+)
+
+// Formats lists the supported output formats.
+func Formats() []string {
+	return []string{FormatText, FormatJSON, FormatYAML}
+}
+
+// Report is the top-level machine-readable document.
+type Report struct {
+	SchemaVersion int                `json:"schema_version" yaml:"schema_version"`
+	DryRun        bool               `json:"dry_run"        yaml:"dry_run"`
+	Results       []RepositoryResult `json:"results"        yaml:"results"`
+}
+
+// RepositoryResult is the serialized form of a sync.Result.
+type RepositoryResult struct {
+	Repository   string   `json:"repository"              yaml:"repository"`
+	Branch       string   `json:"branch,omitempty"        yaml:"branch,omitempty"`
+	Exists       bool     `json:"exists"                  yaml:"exists"`
+	Skipped      string   `json:"skipped,omitempty"       yaml:"skipped,omitempty"`
+	NotProcessed bool     `json:"not_processed,omitempty" yaml:"not_processed,omitempty"`
+	Error        string   `json:"error,omitempty"         yaml:"error,omitempty"`
+	Changes      []Change `json:"changes"                 yaml:"changes"`
+}
+
+// Change is the serialized form of a sync.Change.
+type Change struct {
+	Field   string `json:"field"             yaml:"field"`
+	Current any    `json:"current"           yaml:"current"`
+	Desired any    `json:"desired"           yaml:"desired"`
+	Source  string `json:"source,omitempty"  yaml:"source,omitempty"`
+	Removal bool   `json:"removal,omitempty" yaml:"removal,omitempty"`
+	Diff    string `json:"diff,omitempty"    yaml:"diff,omitempty"`
+}
+
+// New builds a report from sync results.
+func New(results []sync.Result, dryRun bool) Report {
+	report := Report{
+		SchemaVersion: SchemaVersion,
+		DryRun:        dryRun,
+		Results:       make([]RepositoryResult, 0, len(results)),
+	}
+
+	for _, result := range results {
+		entry := RepositoryResult{
+			Repository:   result.RepositoryName(),
+			Branch:       result.Branch,
+			Exists:       result.Exists,
+			Skipped:      result.Skipped,
+			NotProcessed: result.NotProcessed,
+			Changes:      make([]Change, 0, len(result.Changes)),
+		}
+		if result.Error != nil {
+			entry.Error = result.Error.Error()
+		}
+		for _, change := range result.Changes {
+			entry.Changes = append(entry.Changes, Change{
+				Field:   change.Field,
+				Current: change.Current,
+				Desired: change.Desired,
+				Source:  change.Source,
+				Removal: change.Removal,
+				Diff:    change.Diff,
+			})
+		}
+		report.Results = append(report.Results, entry)
+	}
+
+	return report
+}
+
+// Write encodes the report to w in the given machine-readable format.
+func Write(w io.Writer, format string, report Report) error {
+	switch format {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			return fmt.Errorf("failed to encode JSON report: %w", err)
+		}
+	case FormatYAML:
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2) //nolint:mnd // Two-space indentation matches the config file
+		if err := enc.Encode(report); err != nil {
+			return fmt.Errorf("failed to encode YAML report: %w", err)
+		}
+		if err := enc.Close(); err != nil {
+			return fmt.Errorf("failed to encode YAML report: %w", err)
+		}
+	default:
+		return fmt.Errorf("unsupported output format: %s", format)
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package report //nolint:testpackage // Tests internal implementation details
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/mholtzscher/github-janitor/internal/sync"
+)
+
+func sampleResults() []sync.Result {
+	return []sync.Result{
+		{
+			Repository: "o/r",
+			Exists:     true,
+			Changes: []sync.Change{
+				{Field: "allow_merge_commit", Current: false, Desired: true, Source: "global"},
+				{Field: "topics", Current: []string{}, Desired: []string{"go"}},
+				{Field: "access.teams.legacy", Current: "admin", Desired: "none", Removal: true},
+			},
+		},
+		{Repository: "o/missing"},
+		{Repository: "o/broken", Exists: true, Error: errors.New("boom")},
+		{Repository: "o/old", Skipped: "excluded: retired"},
+	}
+}
+
+func TestWrite_JSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, FormatJSON, New(sampleResults(), true)); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	var got map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("output is not valid JSON: %v\n%s", err, buf.String())
+	}
+	if got["schema_version"] != float64(SchemaVersion) {
+		t.Fatalf("schema_version = %v; want %d", got["schema_version"], SchemaVersion)
+	}
+	if got["dry_run"] != true {
+		t.Fatalf("dry_run = %v; want true", got["dry_run"])
+	}
+
+	results, _ := got["results"].([]any)
+	if len(results) != 4 {
+		t.Fatalf("len(results) = %d; want 4", len(results))
+	}
+	first, _ := results[0].(map[string]any)
+	changes, _ := first["changes"].([]any)
+	change, _ := changes[0].(map[string]any)
+	if change["field"] != "allow_merge_commit" || change["current"] != false || change["desired"] != true ||
+		change["source"] != "global" {
+		t.Fatalf("changes[0] = %v; want allow_merge_commit false -> true from global", change)
+	}
+	if _, ok := change["removal"]; ok {
+		t.Fatal("removal key present for change that removes nothing")
+	}
+	if removal, _ := changes[2].(map[string]any); removal["removal"] != true {
+		t.Fatalf("changes[2] = %v; want removal", removal)
+	}
+	broken, _ := results[2].(map[string]any)
+	if broken["error"] != "boom" {
+		t.Fatalf("error = %v; want boom", broken["error"])
+	}
+	old, _ := results[3].(map[string]any)
+	if old["skipped"] != "excluded: retired" {
+		t.Fatalf("skipped = %v; want excluded: retired", old["skipped"])
+	}
+	if _, ok := old["error"]; ok {
+		t.Fatal("error key present for result without error")
+	}
+}
+
+func TestWrite_YAML(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, FormatYAML, New(sampleResults(), false)); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	var got Report
+	if err := yaml.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("output is not valid YAML: %v\n%s", err, buf.String())
+	}
+	if got.SchemaVersion != SchemaVersion || got.DryRun {
+		t.Fatalf("header = %d/%v; want %d/false", got.SchemaVersion, got.DryRun, SchemaVersion)
+	}
+	if len(got.Results) != 4 || got.Results[1].Repository != "o/missing" || got.Results[1].Exists {
+		t.Fatalf("results = %+v; want o/missing not existing at index 1", got.Results)
+	}
+}
+
+func TestWrite_UnsupportedFormat(t *testing.T) {
+	err := Write(&bytes.Buffer{}, FormatText, New(nil, true))
+	if err == nil || !strings.Contains(err.Error(), "unsupported") {
+		t.Fatalf("Write() error = %v; want unsupported format error", err)
+	}
+}
+
+func TestNew_BranchResult(t *testing.T) {
+	results := []sync.Result{{Repository: "o/r (branch: main)", Branch: "main", Exists: true}}
+
+	got := New(results, true).Results[0]
+	if got.Repository != "o/r" || got.Branch != "main" {
+		t.Fatalf("result = %+v; want repository o/r, branch main", got)
+	}
+}
@@ -0,0 +1,176 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/mholtzscher/github-janitor/internal/config"
+	"github.com/mholtzscher/github-janitor/internal/github"
+)
+
+// Kinds of access grant.
+const (
+	AccessTeam         = "team"
+	AccessCollaborator = "collaborator"
+)
+
+// AccessOperation is a planned grant, change or removal of access.
+type AccessOperation struct {
+	Action string `json:"action"`
+
+	// Kind is AccessTeam or AccessCollaborator.
+	Kind string `json:"kind"`
+
+	Grant github.AccessGrant `json:"grant"`
+}
+
+// syncAccess reconciles the configured team and collaborator permissions with
+// the grants on the repository. Removing a grant is reported as a removal.
+func (s *Syncer) syncAccess(
+	ctx context.Context,
+	repo config.Repository,
+	settings config.Settings,
+	origins config.Origins,
+	dryRun bool,
+) Result {
+
+	result := Result{
+		Repository: repo.FullName(),
+		Changes:    make([]Change, 0),
+	}
+
+	planned := &PlannedRepository{Owner: repo.Owner, Name: repo.Name}
+	result.Planned = planned
+
+	if settings.Access.Teams != nil {
+		current, err := s.client.ListTeamAccess(ctx, repo.Owner, repo.Name)
+		if err != nil {
+			result.Error = err
+			return result
+		}
+		changes, ops := diffAccess(AccessTeam, "access.teams", current, settings.Access.Teams, origins)
+		result.Changes = append(result.Changes, changes...)
+		if len(ops) > 0 {
+			planned.Access = append(planned.Access, ops...)
+			planned.observed.Teams = current
+		}
+	}
+	if settings.Access.Collaborators != nil {
+		current, err := s.client.ListCollaborators(ctx, repo.Owner, repo.Name)
+		if err != nil {
+			result.Error = err
+			return result
+		}
+		changes, ops := diffAccess(
+			AccessCollaborator,
+			"access.collaborators",
+			current,
+			settings.Access.Collaborators,
+			origins,
+		)
+		result.Changes = append(result.Changes, changes...)
+		if len(ops) > 0 {
+			planned.Access = append(planned.Access, ops...)
+			planned.observed.Collaborators = current
+		}
+	}
+
+	if !dryRun {
+		for _, op := range planned.Access {
+			if applyErr := s.applyAccessOperation(ctx, repo.Owner, repo.Name, op); applyErr != nil {
+				result.Error = applyErr
+				return result
+			}
+		}
+	}
+
+	return result
+}
+
+// diffAccess compares the current grants of one kind with the configured
+// ones, matching names ignoring case. Grants that are not configured, or are
+// configured as "none", are removed.
+func diffAccess(
+	kind, prefix string,
+	current []github.AccessGrant,
+	configured map[string]string,
+	origins config.Origins,
+) ([]Change, []AccessOperation) {
+	existing := make(map[string]github.AccessGrant, len(current))
+	for _, grant := range current {
+		existing[strings.ToLower(grant.Name)] = grant
+	}
+
+	changes := make([]Change, 0)
+	var ops []AccessOperation
+	desired := make(map[string]bool, len(configured))
+	for _, name := range slices.Sorted(maps.Keys(configured)) {
+		permission := github.NormalizePermission(configured[name])
+		if permission == config.PermissionNone {
+			continue
+		}
+		desired[strings.ToLower(name)] = true
+		found, exists := existing[strings.ToLower(name)]
+		if exists && found.Permission == permission {
+			continue
+		}
+
+		change := Change{
+			Field:   prefix + "." + name,
+			Current: config.PermissionNone,
+			Desired: permission,
+			Source:  origins.Of(prefix + "." + name),
+		}
+		action := ActionCreate
+		if exists {
+			change.Current = found.Permission
+			action = ActionUpdate
+		}
+		changes = append(changes, change)
+		ops = append(ops, AccessOperation{
+			Action: action,
+			Kind:   kind,
+			Grant:  github.AccessGrant{Name: name, Permission: permission, InvitationID: found.InvitationID},
+		})
+	}
+
+	for _, grant := range current {
+		if desired[strings.ToLower(grant.Name)] {
+			continue
+		}
+		field := prefix + "." + grant.Name
+		source := origins.Of(field)
+		if source == "" {
+			source = origins.Of(prefix)
+		}
+		changes = append(changes, Change{
+			Field:   field,
+			Current: grant.Permission,
+			Desired: config.PermissionNone,
+			Source:  source,
+			Removal: true,
+		})
+		ops = append(ops, AccessOperation{Action: ActionDelete, Kind: kind, Grant: grant})
+	}
+
+	return changes, ops
+}
+
+// applyAccessOperation performs a single planned access operation.
+func (s *Syncer) applyAccessOperation(ctx context.Context, owner, name string, op AccessOperation) error {
+	grant := op.Grant
+	switch {
+	case op.Kind == AccessTeam && op.Action == ActionDelete:
+		return s.client.RemoveTeamAccess(ctx, owner, name, grant.Name)
+	case op.Kind == AccessTeam:
+		return s.client.SetTeamAccess(ctx, owner, name, grant.Name, grant.Permission)
+	case op.Kind == AccessCollaborator && op.Action == ActionDelete:
+		return s.client.RemoveCollaborator(ctx, owner, name, &grant)
+	case op.Kind == AccessCollaborator:
+		return s.client.SetCollaboratorAccess(ctx, owner, name, &grant)
+	}
+	return fmt.Errorf("unknown access operation %s %s", op.Action, op.Kind)
+}
@@ -0,0 +1,110 @@
+package sync //nolint:testpackage // Tests internal implementation details
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/mholtzscher/github-janitor/internal/config"
+	"github.com/mholtzscher/github-janitor/internal/github"
+)
+
+func accessFixture() (*fakeGitHubClient, *config.Config) {
+	fake, cfg := repoFixture(config.Settings{
+		Access: &config.Access{
+			Teams:         map[string]string{"platform": "admin", "docs": "triage"},
+			Collaborators: map[string]string{"alice": "read", "bob": config.PermissionNone},
+		},
+	})
+	fake.teams = []github.AccessGrant{
+		{Name: "platform", Permission: "push"},
+		{Name: "legacy", Permission: "admin"},
+	}
+	fake.collaborators = []github.AccessGrant{
+		{Name: "Alice", Permission: "pull"},
+		{Name: "bob", Permission: "push", InvitationID: 9},
+	}
+	return fake, cfg
+}
+
+func TestSyncAll_Access(t *testing.T) {
+	fake, cfg := accessFixture()
+	s := &Syncer{client: fake, config: cfg}
+
+	results, err := s.SyncAll(t.Context(), true)
+	if err != nil {
+		t.Fatalf("SyncAll() error = %v", err)
+	}
+	if results[0].Error != nil {
+		t.Fatalf("Error = %v; want nil", results[0].Error)
+	}
+
+	changes := changeByField(t, results[0].Changes)
+	if c := changes["access.teams.platform"]; c.Current != "push" || c.Desired != "admin" || c.Removal {
+		t.Fatalf("platform change = %+v; want push → admin", c)
+	}
+	if c := changes["access.teams.docs"]; c.Current != config.PermissionNone || c.Desired != "triage" {
+		t.Fatalf("docs change = %+v; want none → triage", c)
+	}
+	if c := changes["access.teams.legacy"]; c.Desired != config.PermissionNone || !c.Removal {
+		t.Fatalf("legacy change = %+v; want removal", c)
+	}
+	if c := changes["access.collaborators.bob"]; !c.Removal {
+		t.Fatalf("bob change = %+v; want removal", c)
+	}
+	if _, ok := changes["access.collaborators.alice"]; ok {
+		t.Fatal("unexpected change for alice; read is pull")
+	}
+	if len(fake.accessCalls) != 0 {
+		t.Fatalf("access calls = %v; want none in dry-run", fake.accessCalls)
+	}
+
+	if _, syncErr := s.SyncAll(t.Context(), false); syncErr != nil {
+		t.Fatalf("SyncAll() error = %v", syncErr)
+	}
+	want := []string{
+		"set team docs triage",
+		"set team platform admin",
+		"remove team legacy",
+		"remove collaborator bob",
+	}
+	if !reflect.DeepEqual(fake.accessCalls, want) {
+		t.Fatalf("access calls = %v; want %v", fake.accessCalls, want)
+	}
+}
+
+func TestSyncAll_AccessLeavesUnconfiguredKindAlone(t *testing.T) {
+	fake, cfg := accessFixture()
+	cfg.Settings.Access.Collaborators = nil
+	cfg.Settings.Access.Teams = map[string]string{"platform": "push", "legacy": "admin"}
+	s := &Syncer{client: fake, config: cfg}
+
+	results, err := s.SyncAll(t.Context(), true)
+	if err != nil {
+		t.Fatalf("SyncAll() error = %v", err)
+	}
+	if len(results[0].Changes) != 0 {
+		t.Fatalf("Changes = %+v; want none", results[0].Changes)
+	}
+}
+
+func TestApplyPlan_AccessDrift(t *testing.T) {
+	fake, cfg := accessFixture()
+	s := &Syncer{client: fake, config: cfg}
+
+	results, err := s.SyncAll(t.Context(), true)
+	if err != nil {
+		t.Fatalf("SyncAll() error = %v", err)
+	}
+	plan := NewPlan(results)
+
+	fake.collaborators = fake.collaborators[:1]
+
+	applied := s.ApplyPlan(t.Context(), plan)
+	if !errors.Is(applied[0].Error, ErrDrift) {
+		t.Fatalf("Error = %v; want %v", applied[0].Error, ErrDrift)
+	}
+	if len(fake.accessCalls) != 0 {
+		t.Fatalf("access calls = %v; want none", fake.accessCalls)
+	}
+}
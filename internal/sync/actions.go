@@ -0,0 +1,117 @@
+package sync
+
+import (
+	"context"
+	"slices"
+
+	"github.com/mholtzscher/github-janitor/internal/config"
+	"github.com/mholtzscher/github-janitor/internal/github"
+)
+
+// syncActions reconciles the repository's GitHub Actions policy. Fields that
+// are not configured keep their current values.
+func (s *Syncer) syncActions(
+	ctx context.Context,
+	repo config.Repository,
+	settings config.Settings,
+	origins config.Origins,
+	dryRun bool,
+) Result {
+
+	result := Result{
+		Repository: repo.FullName(),
+		Changes:    make([]Change, 0),
+	}
+
+	current, err := s.client.GetActionsPermissions(ctx, repo.Owner, repo.Name)
+	if err != nil {
+		result.Error = err
+		return result
+	}
+
+	planned := &PlannedRepository{Owner: repo.Owner, Name: repo.Name}
+	result.Planned = planned
+
+	desired := desiredActions(*current, settings.Actions)
+	for _, change := range diffActions(*current, desired) {
+		change.Source = origins.Of(change.Field)
+		result.Changes = append(result.Changes, change)
+	}
+	if len(result.Changes) == 0 {
+		return result
+	}
+
+	planned.Actions = &desired
+	planned.observed.Actions = current
+
+	if !dryRun {
+		if applyErr := s.client.SetActionsPermissions(ctx, repo.Owner, repo.Name, planned.Actions); applyErr != nil {
+			result.Error = applyErr
+		}
+	}
+
+	return result
+}
+
+// desiredActions overlays the configured policy on the current one. The
+// allow list is cleared unless the selected policy is in use.
+func desiredActions(current github.ActionsPermissions, configured *config.Actions) github.ActionsPermissions {
+	desired := current
+	if configured.Enabled != nil {
+		desired.Enabled = *configured.Enabled
+	}
+	if configured.AllowedActions != nil {
+		desired.AllowedActions = *configured.AllowedActions
+	}
+	if selected := configured.SelectedActions; selected != nil {
+		desired.GithubOwnedAllowed = selected.GithubOwned
+		desired.VerifiedAllowed = selected.Verified
+		desired.PatternsAllowed = sortedStrings(selected.Patterns)
+	}
+	if !desired.Enabled {
+		desired.AllowedActions = ""
+	}
+	if desired.AllowedActions != config.AllowedActionsSelected {
+		desired.GithubOwnedAllowed = false
+		desired.VerifiedAllowed = false
+		desired.PatternsAllowed = nil
+	}
+	if configured.DefaultWorkflowPermissions != nil {
+		desired.DefaultWorkflowPermissions = *configured.DefaultWorkflowPermissions
+	}
+	if configured.CanApprovePullRequests != nil {
+		desired.CanApprovePullRequests = *configured.CanApprovePullRequests
+	}
+	return desired
+}
+
+// diffActions reports the differences between two Actions policies as
+// changes to fields such as "actions.allowed_actions".
+func diffActions(current, desired github.ActionsPermissions) []Change {
+	changes := make([]Change, 0)
+	add := func(field string, currentValue, desiredValue any) {
+		changes = append(changes, Change{Field: "actions." + field, Current: currentValue, Desired: desiredValue})
+	}
+	if current.Enabled != desired.Enabled {
+		add("enabled", current.Enabled, desired.Enabled)
+	}
+	if current.AllowedActions != desired.AllowedActions {
+		add("allowed_actions", current.AllowedActions, desired.AllowedActions)
+	}
+	if current.GithubOwnedAllowed != desired.GithubOwnedAllowed {
+		add("selected_actions.github_owned", current.GithubOwnedAllowed, desired.GithubOwnedAllowed)
+	}
+	if current.VerifiedAllowed != desired.VerifiedAllowed {
+		add("selected_actions.verified", current.VerifiedAllowed, desired.VerifiedAllowed)
+	}
+	if !slices.Equal(sortedStrings(current.PatternsAllowed), desired.PatternsAllowed) {
+		add("selected_actions.patterns", current.PatternsAllowed, desired.PatternsAllowed)
+	}
+	if current.DefaultWorkflowPermissions != desired.DefaultWorkflowPermissions {
+		add("default_workflow_permissions", current.DefaultWorkflowPermissions, desired.DefaultWorkflowPermissions)
+	}
+	if current.CanApprovePullRequests != desired.CanApprovePullRequests {
+		add("can_approve_pull_requests", current.CanApprovePullRequests, desired.CanApprovePullRequests)
+	}
+	return changes
+}
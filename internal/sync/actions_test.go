@@ -0,0 +1,72 @@
+package sync //nolint:testpackage // Tests internal implementation details
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mholtzscher/github-janitor/internal/config"
+	"github.com/mholtzscher/github-janitor/internal/github"
+)
+
+func TestSyncAll_Actions(t *testing.T) {
+	fake, cfg := repoFixture(config.Settings{
+		Actions: &config.Actions{
+			AllowedActions:             stringPtr(config.AllowedActionsSelected),
+			SelectedActions:            &config.SelectedActions{GithubOwned: true, Patterns: []string{"docker/*"}},
+			DefaultWorkflowPermissions: stringPtr(config.WorkflowPermissionsRead),
+		},
+	})
+	fake.actions = &github.ActionsPermissions{
+		Enabled:                    true,
+		AllowedActions:             "all",
+		DefaultWorkflowPermissions: "write",
+		CanApprovePullRequests:     true,
+	}
+	s := &Syncer{client: fake, config: cfg}
+
+	results, err := s.SyncAll(t.Context(), false)
+	if err != nil {
+		t.Fatalf("SyncAll() error = %v", err)
+	}
+	if results[0].Error != nil {
+		t.Fatalf("Error = %v; want nil", results[0].Error)
+	}
+
+	changes := changeByField(t, results[0].Changes)
+	if c := changes["actions.allowed_actions"]; c.Current != "all" || c.Desired != "selected" {
+		t.Fatalf("allowed_actions change = %+v; want all → selected", c)
+	}
+	if c := changes["actions.default_workflow_permissions"]; c.Current != "write" || c.Desired != "read" {
+		t.Fatalf("default_workflow_permissions change = %+v; want write → read", c)
+	}
+	if _, ok := changes["actions.can_approve_pull_requests"]; ok {
+		t.Fatal("can_approve_pull_requests changed; want it left as it is")
+	}
+
+	want := []github.ActionsPermissions{{
+		Enabled:                    true,
+		AllowedActions:             "selected",
+		GithubOwnedAllowed:         true,
+		PatternsAllowed:            []string{"docker/*"},
+		DefaultWorkflowPermissions: "read",
+		CanApprovePullRequests:     true,
+	}}
+	if !reflect.DeepEqual(fake.actionsCalls, want) {
+		t.Fatalf("actions calls = %+v; want %+v", fake.actionsCalls, want)
+	}
+}
+
+func TestSyncAll_ActionsUnchanged(t *testing.T) {
+	fake, cfg := repoFixture(config.Settings{
+		Actions: &config.Actions{Enabled: boolPtr(true), AllowedActions: stringPtr(config.AllowedActionsAll)},
+	})
+	s := &Syncer{client: fake, config: cfg}
+
+	results, err := s.SyncAll(t.Context(), false)
+	if err != nil {
+		t.Fatalf("SyncAll() error = %v", err)
+	}
+	if len(results[0].Changes) != 0 || len(fake.actionsCalls) != 0 {
+		t.Fatalf("changes = %+v, calls = %+v; want none", results[0].Changes, fake.actionsCalls)
+	}
+}
@@ -0,0 +1,199 @@
+package sync
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/mholtzscher/github-janitor/internal/config"
+	"github.com/mholtzscher/github-janitor/internal/github"
+)
+
+// DeployKeyOperation is a planned create, replacement or delete of a deploy
+// key. GitHub cannot edit deploy keys, so an update deletes the existing key
+// and creates it again.
+type DeployKeyOperation struct {
+	Action string `json:"action"`
+
+	// ID is the existing deploy key to replace or delete.
+	ID int64 `json:"id,omitempty"`
+
+	// Rotate is set on updates that change the key material. The new key is
+	// then added before the old one is removed.
+	Rotate bool `json:"rotate,omitempty"`
+
+	Key *github.DeployKeyInfo `json:"key"`
+}
+
+// syncDeployKeys reconciles the configured deploy keys with those on the
+// repository, matching them by title. Keys whose material or access differ
+// are replaced. Keys that are not configured are kept, reported or deleted
+// according to unmanaged_deploy_keys.
+func (s *Syncer) syncDeployKeys(
+	ctx context.Context,
+	repo config.Repository,
+	settings config.Settings,
+	origins config.Origins,
+	dryRun bool,
+) Result {
+	source := origins.Of("deploy_keys")
+
+	result := Result{
+		Repository: repo.FullName(),
+		Changes:    make([]Change, 0),
+	}
+
+	current, err := s.client.ListDeployKeys(ctx, repo.Owner, repo.Name)
+	if err != nil {
+		result.Error = err
+		return result
+	}
+	existing := make(map[string]github.DeployKeyInfo, len(current))
+	for _, key := range current {
+		if _, seen := existing[key.Title]; !seen {
+			existing[key.Title] = key
+		}
+	}
+
+	planned := &PlannedRepository{Owner: repo.Owner, Name: repo.Name}
+	result.Planned = planned
+
+	managed := make(map[int64]bool, len(settings.DeployKeys))
+	for _, configured := range settings.DeployKeys {
+		material, keyErr := configured.PublicKey()
+		if keyErr != nil {
+			result.Error = keyErr
+			return result
+		}
+		desired := &github.DeployKeyInfo{
+			Title:    configured.Title,
+			Key:      material,
+			ReadOnly: configured.ReadOnlyOrDefault(),
+		}
+		field := "deploy_keys." + configured.Title
+
+		found, exists := existing[configured.Title]
+		if !exists {
+			result.Changes = append(result.Changes, Change{
+				Field:   field,
+				Current: resourceAbsent,
+				Desired: resourcePresent,
+				Source:  source,
+			})
+			planned.DeployKeys = append(planned.DeployKeys, DeployKeyOperation{Action: ActionCreate, Key: desired})
+			continue
+		}
+		managed[found.ID] = true
+
+		changes := diffDeployKey(field, found, *desired)
+		if len(changes) == 0 {
+			continue
+		}
+		result.Changes = append(result.Changes, withSource(changes, source)...)
+		planned.DeployKeys = append(planned.DeployKeys, DeployKeyOperation{
+			Action: ActionUpdate,
+			ID:     found.ID,
+			Rotate: keyFingerprint(found.Key) != keyFingerprint(desired.Key),
+			Key:    desired,
+		})
+	}
+
+	mode := settings.UnmanagedDeployKeysOrDefault()
+	for _, key := range current {
+		if managed[key.ID] || mode == config.UnmanagedKeep {
+			continue
+		}
+		change := Change{
+			Field:   "deploy_keys." + key.Title,
+			Current: resourcePresent,
+			Desired: resourceUnmanaged,
+			Source:  origins.Of("unmanaged_deploy_keys"),
+		}
+		if mode == config.UnmanagedDelete {
+			change.Desired = resourceAbsent
+			change.Removal = true
+			planned.DeployKeys = append(planned.DeployKeys, DeployKeyOperation{
+				Action: ActionDelete,
+				ID:     key.ID,
+				Key:    &github.DeployKeyInfo{Title: key.Title},
+			})
+		}
+		result.Changes = append(result.Changes, change)
+	}
+
+	if len(planned.DeployKeys) > 0 {
+		planned.observed.DeployKeys = current
+	}
+
+	if !dryRun {
+		for _, op := range planned.DeployKeys {
+			if applyErr := s.applyDeployKeyOperation(ctx, repo.Owner, repo.Name, op); applyErr != nil {
+				result.Error = applyErr
+				return result
+			}
+		}
+	}
+
+	return result
+}
+
+// diffDeployKey reports the differences between an existing deploy key and
+// the desired one as changes under prefix. Key material is shown as SHA256
+// fingerprints.
+func diffDeployKey(prefix string, current, desired github.DeployKeyInfo) []Change {
+	changes := make([]Change, 0)
+	if currentKey, desiredKey := keyFingerprint(current.Key), keyFingerprint(desired.Key); currentKey != desiredKey {
+		changes = append(changes, Change{Field: prefix + ".key", Current: currentKey, Desired: desiredKey})
+	}
+	if current.ReadOnly != desired.ReadOnly {
+		changes = append(changes, Change{
+			Field:   prefix + ".read_only",
+			Current: current.ReadOnly,
+			Desired: desired.ReadOnly,
+		})
+	}
+	return changes
+}
+
+// keyFingerprint returns the OpenSSH SHA256 fingerprint of a public key, or
+// the key itself if it cannot be decoded.
+func keyFingerprint(key string) string {
+	fields := strings.Fields(key)
+	if len(fields) < 2 {
+		return key
+	}
+	blob, err := base64.StdEncoding.DecodeString(fields[1])
+	if err != nil {
+		return key
+	}
+	sum := sha256.Sum256(blob)
+	return "SHA256:" + base64.RawStdEncoding.EncodeToString(sum[:])
+}
+
+// applyDeployKeyOperation performs a single planned deploy key operation.
+// Deploy keys cannot be edited, so updates replace the key. A rotated key is
+// created before the old one is deleted so access is never lost; a key whose
+// access alone changed must be deleted first, as GitHub rejects duplicate
+// key material.
+func (s *Syncer) applyDeployKeyOperation(ctx context.Context, owner, name string, op DeployKeyOperation) error {
+	switch op.Action {
+	case ActionCreate:
+		return s.client.CreateDeployKey(ctx, owner, name, op.Key)
+	case ActionUpdate:
+		if op.Rotate {
+			if err := s.client.CreateDeployKey(ctx, owner, name, op.Key); err != nil {
+				return err
+			}
+			return s.client.DeleteDeployKey(ctx, owner, name, op.ID)
+		}
+		if err := s.client.DeleteDeployKey(ctx, owner, name, op.ID); err != nil {
+			return err
+		}
+		return s.client.CreateDeployKey(ctx, owner, name, op.Key)
+	case ActionDelete:
+		return s.client.DeleteDeployKey(ctx, owner, name, op.ID)
+	}
+	return fmt.Errorf("unknown deploy key action %q", op.Action)
+}
@@ -0,0 +1,106 @@
+package sync //nolint:testpackage // Tests internal implementation details
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/mholtzscher/github-janitor/internal/config"
+	"github.com/mholtzscher/github-janitor/internal/github"
+)
+
+const (
+	testMirrorKey  = "ssh-ed25519 bWlycm9yLWtleS1tYXRlcmlhbC0wMTIzNDU2Nzg5YWI="
+	testRotatedKey = "ssh-ed25519 cm90YXRlZC1rZXktbWF0ZXJpYWwtMDEyMzQ1Njc4OWE="
+)
+
+func writeKeyFile(t *testing.T, key string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "key.pub")
+	if err := os.WriteFile(path, []byte(key+" bot@example.com\n"), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	return path
+}
+
+func TestSyncAll_DeployKeys(t *testing.T) {
+	fake, cfg := repoFixture(config.Settings{
+		DeployKeys: []config.DeployKey{
+			{Title: "mirror", KeyFile: writeKeyFile(t, testMirrorKey)},
+			{Title: "release-bot", KeyFile: writeKeyFile(t, testRotatedKey), ReadOnly: boolPtr(false)},
+			{Title: "new", KeyFile: writeKeyFile(t, testRotatedKey)},
+			{Title: "ci", KeyFile: writeKeyFile(t, testMirrorKey), ReadOnly: boolPtr(false)},
+		},
+		UnmanagedDeployKeys: stringPtr(config.UnmanagedDelete),
+	})
+	fake.deployKeys = []github.DeployKeyInfo{
+		{ID: 1, Title: "mirror", Key: testMirrorKey, ReadOnly: true},
+		{ID: 2, Title: "release-bot", Key: testMirrorKey, ReadOnly: true},
+		{ID: 3, Title: "old", Key: testMirrorKey, ReadOnly: true},
+		{ID: 4, Title: "ci", Key: testMirrorKey, ReadOnly: true},
+	}
+	s := &Syncer{client: fake, config: cfg}
+
+	results, err := s.SyncAll(t.Context(), false)
+	if err != nil {
+		t.Fatalf("SyncAll() error = %v", err)
+	}
+	if results[0].Error != nil {
+		t.Fatalf("Error = %v; want nil", results[0].Error)
+	}
+
+	changes := changeByField(t, results[0].Changes)
+	if _, ok := changes["deploy_keys.mirror.key"]; ok {
+		t.Fatal("mirror key changed; want key comments ignored")
+	}
+	c := changes["deploy_keys.release-bot.key"]
+	if c.Current != keyFingerprint(testMirrorKey) || c.Desired != keyFingerprint(testRotatedKey) {
+		t.Fatalf("release-bot key change = %+v; want fingerprints", c)
+	}
+	if c := changes["deploy_keys.release-bot.read_only"]; c.Current != true || c.Desired != false {
+		t.Fatalf("release-bot read_only change = %+v; want true → false", c)
+	}
+	if c := changes["deploy_keys.old"]; !c.Removal {
+		t.Fatalf("old change = %+v; want removal", c)
+	}
+
+	want := []string{
+		"create release-bot read_only=false",
+		"delete 2",
+		"create new read_only=true",
+		"delete 4",
+		"create ci read_only=false",
+		"delete 3",
+	}
+	if !reflect.DeepEqual(fake.deployKeyCalls, want) {
+		t.Fatalf("deploy key calls = %v; want %v", fake.deployKeyCalls, want)
+	}
+}
+
+func TestSyncAll_DeployKeysDeleteUndeclared(t *testing.T) {
+	fake, cfg := repoFixture(config.Settings{UnmanagedDeployKeys: stringPtr(config.UnmanagedDelete)})
+	fake.deployKeys = []github.DeployKeyInfo{{ID: 3, Title: "old", Key: testMirrorKey, ReadOnly: true}}
+	s := &Syncer{client: fake, config: cfg}
+
+	results, err := s.SyncAll(t.Context(), false)
+	if err != nil {
+		t.Fatalf("SyncAll() error = %v", err)
+	}
+	if c := changeByField(t, results[0].Changes)["deploy_keys.old"]; !c.Removal {
+		t.Fatalf("old change = %+v; want removal", c)
+	}
+	if want := []string{"delete 3"}; !reflect.DeepEqual(fake.deployKeyCalls, want) {
+		t.Fatalf("deploy key calls = %v; want %v", fake.deployKeyCalls, want)
+	}
+}
+
+func TestKeyFingerprint(t *testing.T) {
+	// "AAAA" is three zero bytes; the fingerprint is their unpadded base64 SHA-256.
+	if got := keyFingerprint("ssh-ed25519 AAAA"); got != "SHA256:cJ6AyISHokEeHuTfufIqhhSS0gxHZRUMDHlKvXD4FHw" {
+		t.Fatalf("keyFingerprint() = %q", got)
+	}
+	if got := keyFingerprint("garbage"); got != "garbage" {
+		t.Fatalf("keyFingerprint() = %q; want garbage", got)
+	}
+}
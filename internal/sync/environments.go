@@ -0,0 +1,254 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/mholtzscher/github-janitor/internal/config"
+	"github.com/mholtzscher/github-janitor/internal/github"
+)
+
+// EnvironmentOperation is a planned create, update or delete of a deployment
+// environment and its variables.
+type EnvironmentOperation struct {
+	Action string `json:"action"`
+	Name   string `json:"name"`
+
+	// Environment is the desired environment. It is nil when only the
+	// variables change or the environment is deleted.
+	Environment *github.EnvironmentInfo `json:"environment,omitempty"`
+
+	// Variables lists the environment variables to create or update.
+	Variables []VariableOperation `json:"variables,omitempty"`
+}
+
+// syncEnvironments reconciles the configured deployment environments with
+// those on the repository, matching them by name ignoring case. Environments
+// that are not configured are kept, reported or deleted according to
+// unmanaged_environments.
+func (s *Syncer) syncEnvironments(
+	ctx context.Context,
+	repo config.Repository,
+	settings config.Settings,
+	origins config.Origins,
+	dryRun bool,
+) Result {
+
+	result := Result{
+		Repository: repo.FullName(),
+		Changes:    make([]Change, 0),
+	}
+
+	current, err := s.client.ListEnvironments(ctx, repo.Owner, repo.Name)
+	if err != nil {
+		result.Error = err
+		return result
+	}
+	existing := make(map[string]github.EnvironmentInfo, len(current))
+	for _, env := range current {
+		existing[strings.ToLower(env.Name)] = env
+	}
+
+	planned := &PlannedRepository{Owner: repo.Owner, Name: repo.Name}
+	result.Planned = planned
+
+	configuredNames := slices.Sorted(maps.Keys(settings.Environments))
+	for _, name := range configuredNames {
+		configured := settings.Environments[name]
+		prefix := "environments." + name
+		source := origins.Of(prefix)
+
+		found, exists := existing[strings.ToLower(name)]
+		op := EnvironmentOperation{Action: ActionUpdate, Name: found.Name}
+		if !exists {
+			// New environments start from GitHub's defaults.
+			found = github.EnvironmentInfo{Name: name, CanAdminsBypass: true}
+			op = EnvironmentOperation{Action: ActionCreate, Name: name}
+			result.Changes = append(result.Changes, Change{
+				Field:   prefix,
+				Current: resourceAbsent,
+				Desired: resourcePresent,
+				Source:  source,
+			})
+		}
+
+		desired := desiredEnvironment(found, configured)
+		changes := diffEnvironment(prefix, found, desired)
+		result.Changes = append(result.Changes, withSource(changes, source)...)
+		if !exists || len(changes) > 0 {
+			op.Environment = &desired
+		}
+
+		if len(configured.Variables) > 0 {
+			var variables []github.VariableInfo
+			if exists {
+				variables, err = s.client.ListEnvironmentVariables(ctx, repo.Owner, repo.Name, found.Name)
+				if err != nil {
+					result.Error = err
+					return result
+				}
+			}
+			varChanges, varOps := diffVariables(prefix+".variables", variables, configured.Variables, func(string) string {
+				return source
+			})
+			result.Changes = append(result.Changes, varChanges...)
+			op.Variables = varOps
+			if exists && len(varOps) > 0 {
+				if planned.observed.EnvironmentVariables == nil {
+					planned.observed.EnvironmentVariables = make(map[string][]github.VariableInfo)
+				}
+				planned.observed.EnvironmentVariables[found.Name] = variables
+			}
+		}
+
+		if op.Environment != nil || len(op.Variables) > 0 {
+			planned.Environments = append(planned.Environments, op)
+		}
+	}
+
+	mode := settings.UnmanagedEnvironmentsOrDefault()
+	for _, env := range current {
+		if mode == config.UnmanagedKeep || slices.ContainsFunc(configuredNames, func(name string) bool {
+			return strings.EqualFold(name, env.Name)
+		}) {
+			continue
+		}
+		change := Change{
+			Field:   "environments." + env.Name,
+			Current: resourcePresent,
+			Desired: resourceUnmanaged,
+			Source:  origins.Of("unmanaged_environments"),
+		}
+		if mode == config.UnmanagedDelete {
+			change.Desired = resourceAbsent
+			change.Removal = true
+			planned.Environments = append(planned.Environments, EnvironmentOperation{
+				Action: ActionDelete,
+				Name:   env.Name,
+			})
+		}
+		result.Changes = append(result.Changes, change)
+	}
+
+	if len(planned.Environments) > 0 {
+		planned.observed.Environments = current
+	}
+
+	if !dryRun {
+		for _, op := range planned.Environments {
+			if applyErr := s.applyEnvironmentOperation(ctx, repo.Owner, repo.Name, op); applyErr != nil {
+				result.Error = applyErr
+				return result
+			}
+		}
+	}
+
+	return result
+}
+
+// desiredEnvironment overlays the configured fields on the current
+// environment. Reviewers that only differ in case from the current ones are kept.
+func desiredEnvironment(current github.EnvironmentInfo, configured config.Environment) github.EnvironmentInfo {
+	desired := current
+	if configured.WaitTimer != nil {
+		desired.WaitTimer = *configured.WaitTimer
+	}
+	if reviewers := configured.Reviewers; reviewers != nil {
+		if !sameNames(current.ReviewerUsers, reviewers.Users) {
+			desired.ReviewerUsers = sortedStrings(reviewers.Users)
+		}
+		if !sameNames(current.ReviewerTeams, reviewers.Teams) {
+			desired.ReviewerTeams = sortedStrings(reviewers.Teams)
+		}
+	}
+	if policy := configured.DeploymentBranchPolicy; policy != nil {
+		desired.ProtectedBranches = policy.ProtectedBranches
+		desired.CustomBranches = sortedStrings(policy.CustomBranches)
+	}
+	return desired
+}
+
+// sameNames reports whether two lists hold the same names, ignoring case and order.
+func sameNames(a, b []string) bool {
+	lower := func(names []string) []string {
+		out := make([]string, 0, len(names))
+		for _, name := range names {
+			out = append(out, strings.ToLower(name))
+		}
+		return sortedStrings(out)
+	}
+	return slices.Equal(lower(a), lower(b))
+}
+
+// diffEnvironment reports the differences between an environment and the
+// desired one as changes under prefix, e.g. "environments.production.wait_timer".
+func diffEnvironment(prefix string, current, desired github.EnvironmentInfo) []Change {
+	changes := make([]Change, 0)
+	if current.WaitTimer != desired.WaitTimer {
+		changes = append(changes, Change{
+			Field:   prefix + ".wait_timer",
+			Current: current.WaitTimer,
+			Desired: desired.WaitTimer,
+		})
+	}
+	if !slices.Equal(current.ReviewerUsers, desired.ReviewerUsers) {
+		changes = append(changes, Change{
+			Field:   prefix + ".reviewers.users",
+			Current: current.ReviewerUsers,
+			Desired: desired.ReviewerUsers,
+		})
+	}
+	if !slices.Equal(current.ReviewerTeams, desired.ReviewerTeams) {
+		changes = append(changes, Change{
+			Field:   prefix + ".reviewers.teams",
+			Current: current.ReviewerTeams,
+			Desired: desired.ReviewerTeams,
+		})
+	}
+	if current.ProtectedBranches != desired.ProtectedBranches {
+		changes = append(changes, Change{
+			Field:   prefix + ".deployment_branch_policy.protected_branches",
+			Current: current.ProtectedBranches,
+			Desired: desired.ProtectedBranches,
+		})
+	}
+	if !slices.Equal(current.CustomBranches, desired.CustomBranches) {
+		changes = append(changes, Change{
+			Field:   prefix + ".deployment_branch_policy.custom_branches",
+			Current: current.CustomBranches,
+			Desired: desired.CustomBranches,
+		})
+	}
+	return changes
+}
+
+// applyEnvironmentOperation performs a single planned environment operation,
+// creating or updating the environment before its variables.
+func (s *Syncer) applyEnvironmentOperation(ctx context.Context, owner, name string, op EnvironmentOperation) error {
+	if op.Action == ActionDelete {
+		return s.client.DeleteEnvironment(ctx, owner, name, op.Name)
+	}
+	if op.Environment != nil {
+		if err := s.client.SetEnvironment(ctx, owner, name, op.Environment); err != nil {
+			return err
+		}
+	}
+	for _, variable := range op.Variables {
+		var err error
+		switch variable.Action {
+		case ActionCreate:
+			err = s.client.CreateEnvironmentVariable(ctx, owner, name, op.Name, &variable.Variable)
+		case ActionUpdate:
+			err = s.client.UpdateEnvironmentVariable(ctx, owner, name, op.Name, &variable.Variable)
+		default:
+			err = fmt.Errorf("unknown variable action %q", variable.Action)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
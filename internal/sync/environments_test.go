@@ -0,0 +1,129 @@
+package sync //nolint:testpackage // Tests internal implementation details
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/mholtzscher/github-janitor/internal/config"
+	"github.com/mholtzscher/github-janitor/internal/github"
+)
+
+func environmentFixture() (*fakeGitHubClient, *config.Config) {
+	fake, cfg := repoFixture(config.Settings{
+		Environments: map[string]config.Environment{
+			"production": {
+				WaitTimer: intPtr(30),
+				Reviewers: &config.EnvironmentReviewers{Users: []string{"octocat"}, Teams: []string{"release"}},
+				DeploymentBranchPolicy: &config.DeploymentBranchPolicy{
+					CustomBranches: []string{"release/*", "main"},
+				},
+				Variables: map[string]string{"DEPLOY_URL": "https://example.com"},
+			},
+			"staging": {Variables: map[string]string{"DEPLOY_URL": "https://staging.example.com"}},
+		},
+		UnmanagedEnvironments: stringPtr(config.UnmanagedDelete),
+	})
+	fake.environments = []github.EnvironmentInfo{
+		{Name: "Production", WaitTimer: 5, ReviewerUsers: []string{"Octocat"}, CanAdminsBypass: true},
+		{Name: "legacy", CanAdminsBypass: true},
+	}
+	fake.envVariables = map[string][]github.VariableInfo{
+		"Production": {{Name: "DEPLOY_URL", Value: "https://old.example.com"}},
+	}
+	return fake, cfg
+}
+
+func TestSyncAll_Environments(t *testing.T) {
+	fake, cfg := environmentFixture()
+	s := &Syncer{client: fake, config: cfg}
+
+	results, err := s.SyncAll(t.Context(), false)
+	if err != nil {
+		t.Fatalf("SyncAll() error = %v", err)
+	}
+	if results[0].Error != nil {
+		t.Fatalf("Error = %v; want nil", results[0].Error)
+	}
+
+	changes := changeByField(t, results[0].Changes)
+	if c := changes["environments.production.wait_timer"]; c.Current != 5 || c.Desired != 30 {
+		t.Fatalf("wait_timer change = %+v; want 5 → 30", c)
+	}
+	if _, ok := changes["environments.production.reviewers.users"]; ok {
+		t.Fatal("reviewers.users changed; want users compared ignoring case")
+	}
+	c := changes["environments.production.deployment_branch_policy.custom_branches"]
+	if !reflect.DeepEqual(c.Desired, []string{"main", "release/*"}) {
+		t.Fatalf("custom_branches change = %+v; want [main release/*]", c)
+	}
+	if c := changes["environments.production.variables.DEPLOY_URL"]; c.Desired != "https://example.com" {
+		t.Fatalf("variable change = %+v; want https://example.com", c)
+	}
+	if c := changes["environments.staging"]; c.Desired != resourcePresent {
+		t.Fatalf("staging change = %+v; want creation", c)
+	}
+	if c := changes["environments.legacy"]; !c.Removal {
+		t.Fatalf("legacy change = %+v; want removal", c)
+	}
+
+	want := []string{
+		fmt.Sprintf("set %+v", github.EnvironmentInfo{
+			Name: "Production", WaitTimer: 30, ReviewerUsers: []string{"Octocat"},
+			ReviewerTeams: []string{"release"}, CustomBranches: []string{"main", "release/*"}, CanAdminsBypass: true,
+		}),
+		"update Production DEPLOY_URL=https://example.com",
+		fmt.Sprintf("set %+v", github.EnvironmentInfo{Name: "staging", CanAdminsBypass: true}),
+		"create staging DEPLOY_URL=https://staging.example.com",
+		"delete legacy",
+	}
+	if !reflect.DeepEqual(fake.environmentCalls, want) {
+		t.Fatalf("environment calls = %v; want %v", fake.environmentCalls, want)
+	}
+}
+
+func TestApplyPlan_EnvironmentVariableDrift(t *testing.T) {
+	fake, cfg := environmentFixture()
+	s := &Syncer{client: fake, config: cfg}
+
+	results, err := s.SyncAll(t.Context(), true)
+	if err != nil {
+		t.Fatalf("SyncAll() error = %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "plan.json")
+	if writeErr := WritePlan(path, NewPlan(results)); writeErr != nil {
+		t.Fatalf("WritePlan() error = %v", writeErr)
+	}
+	plan, err := ReadPlan(path)
+	if err != nil {
+		t.Fatalf("ReadPlan() error = %v", err)
+	}
+
+	fake.envVariables["Production"] = []github.VariableInfo{{Name: "DEPLOY_URL", Value: "https://changed.example.com"}}
+	applied := s.ApplyPlan(t.Context(), plan)
+	if !errors.Is(applied[0].Error, ErrDrift) {
+		t.Fatalf("Error = %v; want ErrDrift", applied[0].Error)
+	}
+	if len(fake.environmentCalls) != 0 {
+		t.Fatalf("environment calls = %v; want none", fake.environmentCalls)
+	}
+}
+
+func TestSyncAll_EnvironmentsDeleteUndeclared(t *testing.T) {
+	fake, cfg := repoFixture(config.Settings{UnmanagedEnvironments: stringPtr(config.UnmanagedDelete)})
+	fake.environments = []github.EnvironmentInfo{{Name: "staging"}}
+	s := &Syncer{client: fake, config: cfg}
+
+	results, err := s.SyncAll(t.Context(), false)
+	if err != nil {
+		t.Fatalf("SyncAll() error = %v", err)
+	}
+	if c := changeByField(t, results[0].Changes)["environments.staging"]; !c.Removal {
+		t.Fatalf("staging change = %+v; want removal", c)
+	}
+	if want := []string{"delete staging"}; !reflect.DeepEqual(fake.environmentCalls, want) {
+		t.Fatalf("environment calls = %v; want %v", fake.environmentCalls, want)
+	}
+}
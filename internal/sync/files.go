@@ -0,0 +1,273 @@
+package sync
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1" //nolint:gosec // Git identifies blobs by SHA-1
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/rogpeppe/go-internal/diff"
+
+	"github.com/mholtzscher/github-janitor/internal/config"
+	"github.com/mholtzscher/github-janitor/internal/github"
+)
+
+// File sync commits and pull requests. Pull request branches are named
+// syncBranchPrefix followed by a hash of the files they first carried.
+const (
+	syncBranchPrefix   = "github-janitor/sync-"
+	filesCommitMessage = "Sync repository files"
+)
+
+// Lengths of the blob SHAs shown in file changes and of the hash in pull
+// request branch names.
+const (
+	shortSHALength       = 7
+	syncBranchHashLength = 12
+)
+
+// FileSync is a planned commit of repository files, either straight to the
+// default branch or through a pull request into it.
+type FileSync struct {
+	Mode   string               `json:"mode"`
+	Branch string               `json:"branch"`
+	Files  []github.FileContent `json:"files"`
+
+	// Summary describes each file change, one line per file, for the pull
+	// request body.
+	Summary []string `json:"summary"`
+
+	// AutoMerge is the merge method to enable auto-merge with, if any.
+	AutoMerge string `json:"auto_merge,omitempty"`
+}
+
+// templateData is the data a file template is rendered with.
+type templateData struct {
+	Owner         string
+	Name          string
+	DefaultBranch string
+}
+
+// syncFiles compares the configured files with those on the repository's
+// default branch. Each file that is missing or differs is shown as a change
+// from its current to its desired blob SHA, with a unified diff.
+func (s *Syncer) syncFiles(
+	ctx context.Context,
+	repo config.Repository,
+	current *github.RepositoryInfo,
+	settings config.Settings,
+	origins config.Origins,
+	dryRun bool,
+) Result {
+	result := Result{
+		Repository: repo.FullName(),
+		Changes:    make([]Change, 0),
+	}
+
+	planned := &PlannedRepository{Owner: repo.Owner, Name: repo.Name}
+	result.Planned = planned
+
+	data := templateData{Owner: repo.Owner, Name: repo.Name, DefaultBranch: current.DefaultBranch}
+	fileSync := &FileSync{Mode: settings.FilesModeOrDefault(), Branch: current.DefaultBranch}
+	if fileSync.Mode == config.FilesModePullRequest && settings.FilesAutoMerge != nil {
+		fileSync.AutoMerge = *settings.FilesAutoMerge
+	}
+	observed := make(map[string]string)
+	for _, path := range slices.Sorted(maps.Keys(settings.Files)) {
+		content, err := renderFile(settings.Files[path], data)
+		if err != nil {
+			result.Error = fmt.Errorf("file %s: %w", path, err)
+			return result
+		}
+		existing, err := s.client.GetFile(ctx, repo.Owner, repo.Name, path, current.DefaultBranch)
+		if err != nil {
+			result.Error = err
+			return result
+		}
+
+		desiredSHA := blobSHA(content)
+		change := Change{
+			Field:   "files." + path,
+			Current: resourceAbsent,
+			Desired: desiredSHA[:shortSHALength],
+			Source:  origins.Of("files." + path),
+		}
+		oldName := "/dev/null"
+		summary := fmt.Sprintf("Add `%s`", path)
+		if existing.Exists {
+			if existing.SHA == desiredSHA {
+				continue
+			}
+			change.Current = existing.SHA[:min(shortSHALength, len(existing.SHA))]
+			oldName = "a/" + path
+			summary = fmt.Sprintf("Update `%s` (%v → %v)", path, change.Current, change.Desired)
+		}
+		change.Diff = unifiedDiff(oldName, existing.Content, "b/"+path, content)
+		result.Changes = append(result.Changes, change)
+		fileSync.Files = append(fileSync.Files, github.FileContent{Path: path, Content: content})
+		fileSync.Summary = append(fileSync.Summary, summary)
+		observed[path] = existing.SHA
+	}
+
+	if len(fileSync.Files) == 0 {
+		return result
+	}
+	planned.Files = fileSync
+	planned.observed.Files = observed
+
+	if !dryRun {
+		if applyErr := s.applyFileSync(ctx, repo.Owner, repo.Name, fileSync); applyErr != nil {
+			result.Error = applyErr
+		}
+	}
+
+	return result
+}
+
+// renderFile reads a file's source, rendering it as a template if configured.
+func renderFile(file config.File, data templateData) (string, error) {
+	source, err := os.ReadFile(file.Source)
+	if err != nil {
+		return "", fmt.Errorf("failed to read source: %w", err)
+	}
+	if !file.Template {
+		return string(source), nil
+	}
+	tmpl, err := template.New(file.Source).Option("missingkey=error").Parse(string(source))
+	if err != nil {
+		return "", fmt.Errorf("failed to parse template: %w", err)
+	}
+	var out bytes.Buffer
+	if execErr := tmpl.Execute(&out, data); execErr != nil {
+		return "", fmt.Errorf("failed to render template: %w", execErr)
+	}
+	return out.String(), nil
+}
+
+// blobSHA returns the SHA git gives a blob with the given content.
+func blobSHA(content string) string {
+	sum := sha1.Sum(fmt.Appendf(nil, "blob %d\x00%s", len(content), content)) //nolint:gosec // Git blob ID
+	return hex.EncodeToString(sum[:])
+}
+
+// unifiedDiff returns a unified diff between two file contents, without the
+// leading "diff" line.
+func unifiedDiff(oldName, oldContent, newName, newContent string) string {
+	out := string(diff.Diff(oldName, []byte(oldContent), newName, []byte(newContent)))
+	_, rest, _ := strings.Cut(out, "\n")
+	return rest
+}
+
+// syncBranch returns the name of a new pull request branch for files.
+func syncBranch(files []github.FileContent) string {
+	hash := sha256.New()
+	for _, file := range files {
+		fmt.Fprintf(hash, "%s\x00%s\x00", file.Path, file.Content)
+	}
+	return syncBranchPrefix + hex.EncodeToString(hash.Sum(nil))[:syncBranchHashLength]
+}
+
+// pullRequestBody lists the file changes of a pull request.
+func pullRequestBody(fileSync *FileSync) string {
+	var body strings.Builder
+	body.WriteString("Brings these files in line with the github-janitor configuration:\n\n")
+	for _, line := range fileSync.Summary {
+		fmt.Fprintf(&body, "- %s\n", line)
+	}
+	return body.String()
+}
+
+// applyFileSync commits the files to the default branch, or to a sync
+// branch with a pull request into the default branch. An open pull request
+// from an earlier sync is updated instead of opening another one: its branch
+// is reset to the default branch plus the new commit and its body replaced.
+func (s *Syncer) applyFileSync(ctx context.Context, owner, name string, fileSync *FileSync) error {
+	if fileSync.Mode != config.FilesModePullRequest {
+		return s.client.CommitFiles(ctx, owner, name, fileSync.Branch, fileSync.Branch, filesCommitMessage, fileSync.Files)
+	}
+
+	pr, found, err := s.client.FindPullRequest(ctx, owner, name, syncBranchPrefix, fileSync.Branch)
+	if err != nil {
+		return err
+	}
+	branch := syncBranch(fileSync.Files)
+	upToDate := false
+	if found {
+		branch = pr.Head
+		// Leave a branch that already has the desired content alone, so the
+		// pull request keeps its checks, approvals and any pushed commits.
+		upToDate, err = s.branchHasFiles(ctx, owner, name, branch, fileSync.Files)
+		if err != nil {
+			return err
+		}
+	}
+	if !upToDate {
+		err = s.client.CommitFiles(ctx, owner, name, fileSync.Branch, branch, filesCommitMessage, fileSync.Files)
+		if err != nil {
+			return err
+		}
+	}
+
+	desired := &github.PullRequestInfo{
+		Title: filesCommitMessage,
+		Body:  pullRequestBody(fileSync),
+		Head:  branch,
+		Base:  fileSync.Branch,
+	}
+	if found {
+		desired.Number = pr.Number
+		if pr.Title == desired.Title && pr.Body == desired.Body {
+			return s.enableAutoMerge(ctx, owner, name, pr, fileSync.AutoMerge)
+		}
+		if updateErr := s.client.UpdatePullRequest(ctx, owner, name, desired); updateErr != nil {
+			return updateErr
+		}
+	} else {
+		pr, err = s.client.CreatePullRequest(ctx, owner, name, desired)
+		if err != nil {
+			return err
+		}
+	}
+
+	return s.enableAutoMerge(ctx, owner, name, pr, fileSync.AutoMerge)
+}
+
+// enableAutoMerge turns on auto-merge for a pull request unless method is
+// empty or it is already enabled.
+func (s *Syncer) enableAutoMerge(
+	ctx context.Context,
+	owner, name string,
+	pr *github.PullRequestInfo,
+	method string,
+) error {
+	if method == "" || pr.AutoMerge {
+		return nil
+	}
+	return s.client.EnableAutoMerge(ctx, owner, name, pr, method)
+}
+
+// branchHasFiles reports whether every file already has the given content
+// on branch.
+func (s *Syncer) branchHasFiles(
+	ctx context.Context,
+	owner, name, branch string,
+	files []github.FileContent,
+) (bool, error) {
+	for _, file := range files {
+		existing, err := s.client.GetFile(ctx, owner, name, file.Path, branch)
+		if err != nil {
+			return false, err
+		}
+		if !existing.Exists || existing.SHA != blobSHA(file.Content) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,133 @@
+package sync //nolint:testpackage // Tests internal implementation details
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mholtzscher/github-janitor/internal/config"
+)
+
+func writeSourceFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "source")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	return path
+}
+
+func TestSyncAll_Files(t *testing.T) {
+	fake, cfg := repoFixture(config.Settings{
+		Files: map[string]config.File{
+			"LICENSE": {Source: writeSourceFile(t, "MIT\n")},
+			".github/CODEOWNERS": {
+				Source:   writeSourceFile(t, "* @{{ .Owner }}/{{ .Name }}-owners\n"),
+				Template: true,
+			},
+			"SECURITY.md": {Source: writeSourceFile(t, "Report issues to {{ .Owner }}.\n")},
+		},
+	})
+	fake.getRepoResp.DefaultBranch = "main"
+	fake.files = map[string]string{
+		"LICENSE":            "MIT\n",
+		".github/CODEOWNERS": "* @o/old\n",
+	}
+	s := &Syncer{client: fake, config: cfg}
+
+	results, err := s.SyncAll(t.Context(), false)
+	if err != nil {
+		t.Fatalf("SyncAll() error = %v", err)
+	}
+	if results[0].Error != nil {
+		t.Fatalf("Error = %v; want nil", results[0].Error)
+	}
+
+	changes := changeByField(t, results[0].Changes)
+	if _, ok := changes["files.LICENSE"]; ok {
+		t.Fatal("LICENSE changed; want it left as it is")
+	}
+	wantDiff := "--- a/.github/CODEOWNERS\n+++ b/.github/CODEOWNERS\n@@ -1,1 +1,1 @@\n-* @o/old\n+* @o/r-owners\n"
+	if c := changes["files..github/CODEOWNERS"]; c.Diff != wantDiff || c.Current != blobSHA("* @o/old\n")[:7] {
+		t.Fatalf("CODEOWNERS change = %+v; want diff %q", c, wantDiff)
+	}
+	// Files that are not templates are copied verbatim.
+	verbatim := blobSHA("Report issues to {{ .Owner }}.\n")[:7]
+	if c := changes["files.SECURITY.md"]; c.Current != resourceAbsent || c.Desired != verbatim {
+		t.Fatalf("SECURITY.md change = %+v; want new file", c)
+	}
+
+	want := []string{
+		"commit .github/CODEOWNERS to main from main",
+		"commit SECURITY.md to main from main",
+	}
+	if !reflect.DeepEqual(fake.fileCalls, want) {
+		t.Fatalf("file calls = %v; want %v", fake.fileCalls, want)
+	}
+}
+
+func TestSyncAll_FilesPullRequest(t *testing.T) {
+	license := writeSourceFile(t, "MIT\n")
+	fake, cfg := repoFixture(config.Settings{
+		Files: map[string]config.File{
+			"LICENSE":     {Source: license},
+			"SECURITY.md": {Source: writeSourceFile(t, "new\n")},
+		},
+		FilesMode:      stringPtr(config.FilesModePullRequest),
+		FilesAutoMerge: stringPtr(config.AutoMergeSquash),
+	})
+	fake.getRepoResp.DefaultBranch = "main"
+	fake.files = map[string]string{"SECURITY.md": "old\n"}
+	s := &Syncer{client: fake, config: cfg}
+
+	if _, err := s.SyncAll(t.Context(), false); err != nil {
+		t.Fatalf("SyncAll() error = %v", err)
+	}
+	if len(fake.pullRequests) != 1 {
+		t.Fatalf("pull requests = %+v; want one", fake.pullRequests)
+	}
+	pr := fake.pullRequests[0]
+	if !strings.HasPrefix(pr.Head, "github-janitor/sync-") || len(pr.Head) != len("github-janitor/sync-")+12 {
+		t.Fatalf("head = %q; want github-janitor/sync-<hash>", pr.Head)
+	}
+	update := "- Update `SECURITY.md` (" + blobSHA("old\n")[:7] + " → " + blobSHA("new\n")[:7] + ")"
+	if !strings.Contains(pr.Body, "- Add `LICENSE`\n") || !strings.Contains(pr.Body, update) {
+		t.Fatalf("body = %q; want every file change listed", pr.Body)
+	}
+
+	// A second run with different content updates the same pull request.
+	if err := os.WriteFile(license, []byte("Apache-2.0\n"), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if _, err := s.SyncAll(t.Context(), false); err != nil {
+		t.Fatalf("SyncAll() error = %v", err)
+	}
+	if len(fake.pullRequests) != 1 || fake.pullRequests[0].Head != pr.Head {
+		t.Fatalf("pull requests = %+v; want only %s", fake.pullRequests, pr.Head)
+	}
+
+	// A run with nothing new leaves the branch alone.
+	if _, err := s.SyncAll(t.Context(), false); err != nil {
+		t.Fatalf("SyncAll() error = %v", err)
+	}
+
+	want := []string{
+		"commit LICENSE to " + pr.Head + " from main",
+		"commit SECURITY.md to " + pr.Head + " from main",
+		"auto-merge #1 with squash",
+		"commit LICENSE to " + pr.Head + " from main",
+		"commit SECURITY.md to " + pr.Head + " from main",
+	}
+	if !reflect.DeepEqual(fake.fileCalls, want) {
+		t.Fatalf("file calls = %v; want %v", fake.fileCalls, want)
+	}
+}
+
+func TestRenderFile_MissingKey(t *testing.T) {
+	file := config.File{Source: writeSourceFile(t, "{{ .Team }}"), Template: true}
+	if _, err := renderFile(file, templateData{Owner: "o", Name: "r"}); err == nil {
+		t.Fatal("renderFile() error = nil; want error for unknown field")
+	}
+}
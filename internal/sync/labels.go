@@ -0,0 +1,160 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mholtzscher/github-janitor/internal/config"
+	"github.com/mholtzscher/github-janitor/internal/github"
+)
+
+// LabelOperation is a planned create, update or delete of an issue label.
+type LabelOperation struct {
+	Action string `json:"action"`
+
+	// Name is the existing label to update or delete. An update whose label
+	// has a different name renames it.
+	Name string `json:"name,omitempty"`
+
+	Label *github.LabelInfo `json:"label,omitempty"`
+}
+
+// syncLabels reconciles the configured labels with those on the repository,
+// matching them by name or alias, ignoring case. Labels that are not
+// configured are kept, reported or deleted according to unmanaged_labels.
+func (s *Syncer) syncLabels(
+	ctx context.Context,
+	repo config.Repository,
+	settings config.Settings,
+	origins config.Origins,
+	dryRun bool,
+) Result {
+	source := origins.Of("labels")
+
+	result := Result{
+		Repository: repo.FullName(),
+		Changes:    make([]Change, 0),
+	}
+
+	current, err := s.client.ListLabels(ctx, repo.Owner, repo.Name)
+	if err != nil {
+		result.Error = err
+		return result
+	}
+	existing := make(map[string]github.LabelInfo, len(current))
+	for _, label := range current {
+		existing[strings.ToLower(label.Name)] = label
+	}
+
+	planned := &PlannedRepository{Owner: repo.Owner, Name: repo.Name}
+	result.Planned = planned
+
+	managed := make(map[string]bool, len(settings.Labels))
+	for _, configured := range settings.Labels {
+		desired := &github.LabelInfo{
+			Name:        configured.Name,
+			Color:       configured.NormalizedColor(),
+			Description: configured.Description,
+		}
+		field := "labels." + configured.Name
+
+		found, exists := findLabel(existing, configured)
+		if !exists {
+			result.Changes = append(result.Changes, Change{
+				Field:   field,
+				Current: resourceAbsent,
+				Desired: resourcePresent,
+				Source:  source,
+			})
+			planned.Labels = append(planned.Labels, LabelOperation{Action: ActionCreate, Label: desired})
+			continue
+		}
+		managed[strings.ToLower(found.Name)] = true
+
+		changes := diffLabel(field, found, *desired)
+		if len(changes) == 0 {
+			continue
+		}
+		result.Changes = append(result.Changes, withSource(changes, source)...)
+		planned.Labels = append(planned.Labels, LabelOperation{Action: ActionUpdate, Name: found.Name, Label: desired})
+	}
+
+	mode := settings.UnmanagedLabelsOrDefault()
+	for _, label := range current {
+		if managed[strings.ToLower(label.Name)] || mode == config.UnmanagedKeep {
+			continue
+		}
+		change := Change{
+			Field:   "labels." + label.Name,
+			Current: resourcePresent,
+			Desired: resourceUnmanaged,
+			Source:  origins.Of("unmanaged_labels"),
+		}
+		if mode == config.UnmanagedDelete {
+			change.Desired = resourceAbsent
+			change.Removal = true
+			planned.Labels = append(planned.Labels, LabelOperation{Action: ActionDelete, Name: label.Name})
+		}
+		result.Changes = append(result.Changes, change)
+	}
+
+	if len(planned.Labels) > 0 {
+		planned.observed.Labels = current
+	}
+
+	if !dryRun {
+		for _, op := range planned.Labels {
+			if applyErr := s.applyLabelOperation(ctx, repo.Owner, repo.Name, op); applyErr != nil {
+				result.Error = applyErr
+				return result
+			}
+		}
+	}
+
+	return result
+}
+
+// findLabel returns the existing label with the configured name, or failing
+// that the first existing label named by one of its aliases.
+func findLabel(existing map[string]github.LabelInfo, configured config.Label) (github.LabelInfo, bool) {
+	for _, name := range append([]string{configured.Name}, configured.Aliases...) {
+		if label, ok := existing[strings.ToLower(name)]; ok {
+			return label, true
+		}
+	}
+	return github.LabelInfo{}, false
+}
+
+// diffLabel reports the name, color and description differences of a label
+// as changes under prefix, e.g. "labels.bug.color".
+func diffLabel(prefix string, current, desired github.LabelInfo) []Change {
+	changes := make([]Change, 0)
+	if current.Name != desired.Name {
+		changes = append(changes, Change{Field: prefix + ".name", Current: current.Name, Desired: desired.Name})
+	}
+	if !strings.EqualFold(current.Color, desired.Color) {
+		changes = append(changes, Change{Field: prefix + ".color", Current: current.Color, Desired: desired.Color})
+	}
+	if current.Description != desired.Description {
+		changes = append(changes, Change{
+			Field:   prefix + ".description",
+			Current: current.Description,
+			Desired: desired.Description,
+		})
+	}
+	return changes
+}
+
+// applyLabelOperation performs a single planned label operation.
+func (s *Syncer) applyLabelOperation(ctx context.Context, owner, name string, op LabelOperation) error {
+	switch op.Action {
+	case ActionCreate:
+		return s.client.CreateLabel(ctx, owner, name, op.Label)
+	case ActionUpdate:
+		return s.client.UpdateLabel(ctx, owner, name, op.Name, op.Label)
+	case ActionDelete:
+		return s.client.DeleteLabel(ctx, owner, name, op.Name)
+	}
+	return fmt.Errorf("unknown label action %q", op.Action)
+}
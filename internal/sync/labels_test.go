@@ -0,0 +1,104 @@
+package sync //nolint:testpackage // Tests internal implementation details
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mholtzscher/github-janitor/internal/config"
+	"github.com/mholtzscher/github-janitor/internal/github"
+)
+
+func labelFixture() (*fakeGitHubClient, *config.Config) {
+	fake, cfg := repoFixture(config.Settings{
+		Labels: []config.Label{
+			{Name: "bug", Color: "#D73A4A", Description: "Something isn't working"},
+			{Name: "good first issue", Color: "7057ff", Aliases: []string{"starter"}},
+			{Name: "docs", Color: "0075ca"},
+		},
+	})
+	fake.labels = []github.LabelInfo{
+		{Name: "Bug", Color: "d73a4a", Description: "Something isn't working"},
+		{Name: "starter", Color: "7057ff"},
+		{Name: "wontfix", Color: "ffffff"},
+	}
+	return fake, cfg
+}
+
+func TestSyncAll_Labels(t *testing.T) {
+	fake, cfg := labelFixture()
+	s := &Syncer{client: fake, config: cfg}
+
+	results, err := s.SyncAll(t.Context(), true)
+	if err != nil {
+		t.Fatalf("SyncAll() error = %v", err)
+	}
+	if results[0].Error != nil {
+		t.Fatalf("Error = %v; want nil", results[0].Error)
+	}
+
+	changes := changeByField(t, results[0].Changes)
+	if c := changes["labels.bug.name"]; c.Current != "Bug" || c.Desired != "bug" {
+		t.Fatalf("bug rename = %+v; want Bug → bug", c)
+	}
+	if _, ok := changes["labels.bug.color"]; ok {
+		t.Fatal("unexpected color change for bug; colors differ only in case")
+	}
+	if c := changes["labels.good first issue.name"]; c.Current != "starter" || c.Desired != "good first issue" {
+		t.Fatalf("alias rename = %+v; want starter → good first issue", c)
+	}
+	if c := changes["labels.docs"]; c.Current != resourceAbsent || c.Desired != resourcePresent {
+		t.Fatalf("docs change = %+v; want creation", c)
+	}
+	if _, ok := changes["labels.wontfix"]; ok {
+		t.Fatal("unexpected change for unmanaged label in keep mode")
+	}
+	if len(fake.labelCalls) != 0 {
+		t.Fatalf("label calls = %v; want none in dry-run", fake.labelCalls)
+	}
+
+	if _, syncErr := s.SyncAll(t.Context(), false); syncErr != nil {
+		t.Fatalf("SyncAll() error = %v", syncErr)
+	}
+	want := []string{"update Bug bug", "update starter good first issue", "create docs"}
+	if !reflect.DeepEqual(fake.labelCalls, want) {
+		t.Fatalf("label calls = %v; want %v", fake.labelCalls, want)
+	}
+}
+
+func TestSyncAll_UnmanagedLabels(t *testing.T) {
+	tests := []struct {
+		mode      string
+		wantField bool
+		desired   any
+		wantCalls []string
+	}{
+		{config.UnmanagedKeep, false, nil, nil},
+		{config.UnmanagedReport, true, resourceUnmanaged, nil},
+		{config.UnmanagedDelete, true, resourceAbsent, []string{"delete wontfix"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			fake, cfg := labelFixture()
+			cfg.Settings.Labels = cfg.Settings.Labels[:2]
+			cfg.Settings.Labels[0].Name = "Bug"
+			cfg.Settings.Labels[1].Name = "starter"
+			cfg.Settings.UnmanagedLabels = &tt.mode
+			s := &Syncer{client: fake, config: cfg}
+
+			results, err := s.SyncAll(t.Context(), false)
+			if err != nil {
+				t.Fatalf("SyncAll() error = %v", err)
+			}
+			c, ok := changeByField(t, results[0].Changes)["labels.wontfix"]
+			if ok != tt.wantField || (ok && c.Desired != tt.desired) {
+				t.Fatalf("wontfix change = %+v (present: %v); want desired %v (present: %v)", c, ok, tt.desired, tt.wantField)
+			}
+			if tt.wantField && len(results[0].Changes) != 1 {
+				t.Fatalf("Changes = %+v; want only the unmanaged label", results[0].Changes)
+			}
+			if !reflect.DeepEqual(fake.labelCalls, tt.wantCalls) {
+				t.Fatalf("label calls = %v; want %v", fake.labelCalls, tt.wantCalls)
+			}
+		})
+	}
+}
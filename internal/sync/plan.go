@@ -0,0 +1,519 @@
+package sync
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+
+	gogithub "github.com/google/go-github/v82/github"
+
+	"github.com/mholtzscher/github-janitor/internal/config"
+	"github.com/mholtzscher/github-janitor/internal/github"
+)
+
+// PlanVersion is the version of the saved plan file layout.
+const PlanVersion = 3
+
+// ErrDrift is returned when a repository changed after its plan was created.
+var ErrDrift = errors.New("live state has drifted since the plan was created; re-run plan")
+
+// Plan is a saved set of changes that can be applied exactly as reviewed.
+type Plan struct {
+	Version int `json:"version"`
+
+	// APIURL is the Enterprise Server API URL the plan was made against, as
+	// reported by github.Client.APIURL. Empty means github.com.
+	APIURL string `json:"api_url,omitempty"`
+
+	Repositories []PlannedRepository `json:"repositories"`
+}
+
+// CheckAPIURL returns an error unless apiURL is the API URL the plan was
+// made against, so a plan is never applied to a different server.
+func (p *Plan) CheckAPIURL(apiURL string) error {
+	if apiURL == p.APIURL {
+		return nil
+	}
+	return fmt.Errorf(
+		"plan was made against %s, not %s; apply it to the same server or re-run plan",
+		serverName(p.APIURL),
+		serverName(apiURL),
+	)
+}
+
+// serverName describes an API URL for messages; empty means github.com.
+func serverName(apiURL string) string {
+	if apiURL == "" {
+		return "github.com"
+	}
+	return apiURL
+}
+
+// PlannedRepository holds the patches computed for a single repository.
+type PlannedRepository struct {
+	Owner string `json:"owner"`
+	Name  string `json:"name"`
+
+	// Fingerprint identifies the repository state observed when planning.
+	Fingerprint string `json:"fingerprint"`
+
+	// Settings is the repository settings patch, if settings change.
+	Settings *gogithub.Repository `json:"settings,omitempty"`
+
+	// BranchProtections lists every protected branch observed when planning.
+	BranchProtections []PlannedBranchProtection `json:"branch_protections,omitempty"`
+
+	// Rulesets lists the ruleset operations to perform, in order.
+	Rulesets []RulesetOperation `json:"rulesets,omitempty"`
+
+	// Labels lists the label operations to perform, in order.
+	Labels []LabelOperation `json:"labels,omitempty"`
+
+	// Webhooks lists the webhook operations to perform, in order.
+	Webhooks []WebhookOperation `json:"webhooks,omitempty"`
+
+	// Access lists the team and collaborator grants to add, change or remove.
+	Access []AccessOperation `json:"access,omitempty"`
+
+	// Variables lists the Actions variables to create or update.
+	Variables []VariableOperation `json:"variables,omitempty"`
+
+	// Secrets lists the Actions secrets to upload. Only their sources are
+	// saved; values are read when the plan is applied.
+	Secrets []SecretOperation `json:"secrets,omitempty"`
+
+	// Environments lists the deployment environment operations to perform.
+	Environments []EnvironmentOperation `json:"environments,omitempty"`
+
+	// DeployKeys lists the deploy key operations to perform, in order.
+	DeployKeys []DeployKeyOperation `json:"deploy_keys,omitempty"`
+
+	// Security lists the security features to enable or disable, in order.
+	Security []SecurityOperation `json:"security,omitempty"`
+
+	// Actions is the desired GitHub Actions policy, if it changes.
+	Actions *github.ActionsPermissions `json:"actions,omitempty"`
+
+	// Files is the commit of repository files, if any file changes.
+	Files *FileSync `json:"files,omitempty"`
+
+	// Changes describes the patches for display when the plan is applied.
+	Changes []Change `json:"changes"`
+
+	observed observedState
+}
+
+// PlannedBranchProtection is the planned protection of a single branch.
+type PlannedBranchProtection struct {
+	Branch string `json:"branch"`
+
+	// Protection is the desired protection, if protection changes.
+	Protection *github.BranchProtectionInfo `json:"protection,omitempty"`
+
+	// Changes describes the protection patch for display.
+	Changes []Change `json:"changes,omitempty"`
+}
+
+// observedState is the live state a plan was computed from.
+type observedState struct {
+	Repository        *github.RepositoryInfo         `json:"repository"`
+	BranchProtections []*github.BranchProtectionInfo `json:"branch_protections,omitempty"`
+
+	// Rulesets is only recorded when the plan changes rulesets.
+	Rulesets []github.RulesetInfo `json:"rulesets,omitempty"`
+
+	// Labels is only recorded when the plan changes labels.
+	Labels []github.LabelInfo `json:"labels,omitempty"`
+
+	// Webhooks is only recorded when the plan changes webhooks.
+	Webhooks []github.WebhookInfo `json:"webhooks,omitempty"`
+
+	// Teams and Collaborators are only recorded when the plan changes grants
+	// of that kind.
+	Teams         []github.AccessGrant `json:"teams,omitempty"`
+	Collaborators []github.AccessGrant `json:"collaborators,omitempty"`
+
+	// Variables and Secrets are only recorded when the plan changes them.
+	Variables []github.VariableInfo `json:"variables,omitempty"`
+	Secrets   []github.SecretInfo   `json:"secrets,omitempty"`
+
+	// Environments is only recorded when the plan changes environments, and
+	// EnvironmentVariables only for existing environments whose variables change.
+	Environments         []github.EnvironmentInfo         `json:"environments,omitempty"`
+	EnvironmentVariables map[string][]github.VariableInfo `json:"environment_variables,omitempty"`
+
+	// DeployKeys is only recorded when the plan changes deploy keys.
+	DeployKeys []github.DeployKeyInfo `json:"deploy_keys,omitempty"`
+
+	// SecurityFeatures holds the features the plan changes that are not
+	// reported with the repository.
+	SecurityFeatures map[string]bool `json:"security_features,omitempty"`
+
+	// Actions is only recorded when the plan changes the Actions policy.
+	Actions *github.ActionsPermissions `json:"actions,omitempty"`
+
+	// Files holds the blob SHA of each file the plan writes, empty for
+	// files that do not exist.
+	Files map[string]string `json:"files,omitempty"`
+}
+
+// FullName returns the full repository name (owner/name).
+func (p PlannedRepository) FullName() string {
+	return fmt.Sprintf("%s/%s", p.Owner, p.Name)
+}
+
+// merge copies the operations and observed state planned by a resource sync
+// into p. Each resource sync only fills in its own fields, so every field set
+// in sub is copied.
+func (p *PlannedRepository) merge(sub *PlannedRepository) {
+	copySetFields(reflect.ValueOf(p).Elem(), reflect.ValueOf(sub).Elem())
+	copySetFields(reflect.ValueOf(&p.observed).Elem(), reflect.ValueOf(&sub.observed).Elem())
+}
+
+// copySetFields sets each exported field of dst to the matching field of
+// src, unless the src field is the zero value.
+func copySetFields(dst, src reflect.Value) {
+	for i := range src.NumField() {
+		if !src.Type().Field(i).IsExported() || src.Field(i).IsZero() {
+			continue
+		}
+		dst.Field(i).Set(src.Field(i))
+	}
+}
+
+// hasChanges reports whether the planned repository has anything to apply.
+func (p PlannedRepository) hasChanges() bool {
+	if p.Settings != nil || len(p.Rulesets) > 0 || len(p.Labels) > 0 || len(p.Webhooks) > 0 || len(p.Access) > 0 {
+		return true
+	}
+	if len(p.Variables) > 0 || len(p.Secrets) > 0 || len(p.Environments) > 0 || len(p.DeployKeys) > 0 {
+		return true
+	}
+	if len(p.Security) > 0 || p.Actions != nil || p.Files != nil {
+		return true
+	}
+	return slices.ContainsFunc(p.BranchProtections, func(bp PlannedBranchProtection) bool {
+		return bp.Protection != nil
+	})
+}
+
+// changesAccess reports whether the plan changes grants of the given kind.
+func (p PlannedRepository) changesAccess(kind string) bool {
+	return slices.ContainsFunc(p.Access, func(op AccessOperation) bool { return op.Kind == kind })
+}
+
+// NewPlan collects the changes from dry-run results into a plan.
+// Repositories that errored or have nothing to apply are left out.
+func NewPlan(results []Result) *Plan {
+	plan := &Plan{Version: PlanVersion, Repositories: make([]PlannedRepository, 0)}
+	for _, result := range results {
+		if result.Error != nil || result.Planned == nil || !result.Planned.hasChanges() {
+			continue
+		}
+		planned := *result.Planned
+		planned.Changes = result.Changes
+		plan.Repositories = append(plan.Repositories, planned)
+	}
+	return plan
+}
+
+// WritePlan saves a plan to path.
+func WritePlan(path string, plan *Plan) error {
+	data, err := json.MarshalIndent(plan, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode plan: %w", err)
+	}
+	if writeErr := os.WriteFile(path, append(data, '\n'), config.DefaultFileMode); writeErr != nil {
+		return fmt.Errorf("failed to write plan file: %w", writeErr)
+	}
+	return nil
+}
+
+// ReadPlan loads a plan saved by WritePlan.
+func ReadPlan(path string) (*Plan, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plan file: %w", err)
+	}
+
+	var plan Plan
+	if parseErr := json.Unmarshal(data, &plan); parseErr != nil {
+		return nil, fmt.Errorf("failed to parse plan file: %w", parseErr)
+	}
+	if plan.Version != PlanVersion {
+		return nil, fmt.Errorf("unsupported plan version %d (expected %d)", plan.Version, PlanVersion)
+	}
+
+	return &plan, nil
+}
+
+// ApplyPlan applies a saved plan exactly, refusing any repository whose live
+// state no longer matches the fingerprint recorded when the plan was made.
+func (s *Syncer) ApplyPlan(ctx context.Context, plan *Plan) []Result {
+	results := s.runPool(
+		ctx,
+		len(plan.Repositories),
+		func(i int) string { return plan.Repositories[i].FullName() },
+		func(ctx context.Context, i int) Result {
+			return s.applyPlannedRepository(ctx, plan.Repositories[i])
+		},
+	)
+	return flattenResults(results)
+}
+
+func (s *Syncer) applyPlannedRepository(ctx context.Context, planned PlannedRepository) Result {
+	result := Result{
+		Repository: planned.FullName(),
+		Changes:    planned.Changes,
+	}
+
+	current, err := s.client.GetRepository(ctx, planned.Owner, planned.Name)
+	if err != nil {
+		result.Error = err
+		return result
+	}
+	if !current.Exists {
+		return result
+	}
+	result.Exists = true
+
+	observed := observedState{Repository: current}
+	for _, bp := range planned.BranchProtections {
+		protection, getErr := s.client.GetBranchProtection(ctx, planned.Owner, planned.Name, bp.Branch)
+		if getErr != nil {
+			result.Error = getErr
+			return result
+		}
+		observed.BranchProtections = append(observed.BranchProtections, protection)
+	}
+	if len(planned.Rulesets) > 0 {
+		observed.Rulesets, err = s.client.ListRulesets(ctx, planned.Owner, planned.Name)
+		if err != nil {
+			result.Error = err
+			return result
+		}
+	}
+	if len(planned.Labels) > 0 {
+		observed.Labels, err = s.client.ListLabels(ctx, planned.Owner, planned.Name)
+		if err != nil {
+			result.Error = err
+			return result
+		}
+	}
+	if len(planned.Webhooks) > 0 {
+		observed.Webhooks, err = s.client.ListWebhooks(ctx, planned.Owner, planned.Name)
+		if err != nil {
+			result.Error = err
+			return result
+		}
+	}
+	if planned.changesAccess(AccessTeam) {
+		observed.Teams, err = s.client.ListTeamAccess(ctx, planned.Owner, planned.Name)
+		if err != nil {
+			result.Error = err
+			return result
+		}
+	}
+	if planned.changesAccess(AccessCollaborator) {
+		observed.Collaborators, err = s.client.ListCollaborators(ctx, planned.Owner, planned.Name)
+		if err != nil {
+			result.Error = err
+			return result
+		}
+	}
+
+	if len(planned.Variables) > 0 {
+		observed.Variables, err = s.client.ListVariables(ctx, planned.Owner, planned.Name)
+		if err != nil {
+			result.Error = err
+			return result
+		}
+	}
+	if len(planned.Secrets) > 0 {
+		observed.Secrets, err = s.client.ListSecrets(ctx, planned.Owner, planned.Name)
+		if err != nil {
+			result.Error = err
+			return result
+		}
+	}
+
+	if len(planned.Environments) > 0 {
+		observed.Environments, err = s.client.ListEnvironments(ctx, planned.Owner, planned.Name)
+		if err != nil {
+			result.Error = err
+			return result
+		}
+	}
+	for _, op := range planned.Environments {
+		if op.Action != ActionUpdate || len(op.Variables) == 0 {
+			continue
+		}
+		variables, listErr := s.client.ListEnvironmentVariables(ctx, planned.Owner, planned.Name, op.Name)
+		if listErr != nil {
+			result.Error = listErr
+			return result
+		}
+		if observed.EnvironmentVariables == nil {
+			observed.EnvironmentVariables = make(map[string][]github.VariableInfo)
+		}
+		observed.EnvironmentVariables[op.Name] = variables
+	}
+
+	if len(planned.DeployKeys) > 0 {
+		observed.DeployKeys, err = s.client.ListDeployKeys(ctx, planned.Owner, planned.Name)
+		if err != nil {
+			result.Error = err
+			return result
+		}
+	}
+
+	for _, op := range planned.Security {
+		if !readSeparately(op.Feature) {
+			continue
+		}
+		enabled, getErr := s.client.GetSecurityFeature(ctx, planned.Owner, planned.Name, op.Feature)
+		if getErr != nil {
+			result.Error = getErr
+			return result
+		}
+		if observed.SecurityFeatures == nil {
+			observed.SecurityFeatures = make(map[string]bool)
+		}
+		observed.SecurityFeatures[op.Feature] = enabled
+	}
+
+	if planned.Actions != nil {
+		observed.Actions, err = s.client.GetActionsPermissions(ctx, planned.Owner, planned.Name)
+		if err != nil {
+			result.Error = err
+			return result
+		}
+	}
+
+	if planned.Files != nil {
+		observed.Files = make(map[string]string, len(planned.Files.Files))
+		for _, file := range planned.Files.Files {
+			existing, getErr := s.client.GetFile(ctx, planned.Owner, planned.Name, file.Path, planned.Files.Branch)
+			if getErr != nil {
+				result.Error = getErr
+				return result
+			}
+			observed.Files[file.Path] = existing.SHA
+		}
+	}
+
+	fingerprint, err := observed.fingerprint()
+	if err != nil {
+		result.Error = err
+		return result
+	}
+	if fingerprint != planned.Fingerprint {
+		result.Error = ErrDrift
+		return result
+	}
+
+	if planned.Settings != nil {
+		updateErr := s.client.UpdateRepositorySettings(ctx, planned.Owner, planned.Name, planned.Settings)
+		if updateErr != nil {
+			result.Error = fmt.Errorf("failed to update settings: %w", updateErr)
+			return result
+		}
+	}
+	for _, bp := range planned.BranchProtections {
+		if bp.Protection == nil {
+			continue
+		}
+		branch := branchResult(planned.FullName(), bp.Branch)
+		if bp.Changes != nil {
+			branch.Changes = bp.Changes
+		}
+		if updateErr := s.client.UpdateBranchProtection(ctx, planned.Owner, planned.Name, bp.Protection); updateErr != nil {
+			branch.Error = fmt.Errorf("failed to update branch protection: %w", updateErr)
+		}
+		result.branches = append(result.branches, branch)
+	}
+	for _, op := range planned.Rulesets {
+		if applyErr := s.applyRulesetOperation(ctx, planned.Owner, planned.Name, op); applyErr != nil {
+			result.Error = applyErr
+			return result
+		}
+	}
+	for _, op := range planned.Labels {
+		if applyErr := s.applyLabelOperation(ctx, planned.Owner, planned.Name, op); applyErr != nil {
+			result.Error = applyErr
+			return result
+		}
+	}
+	for _, op := range planned.Webhooks {
+		if applyErr := s.applyWebhookOperation(ctx, planned.Owner, planned.Name, op); applyErr != nil {
+			result.Error = applyErr
+			return result
+		}
+	}
+	for _, op := range planned.Access {
+		if applyErr := s.applyAccessOperation(ctx, planned.Owner, planned.Name, op); applyErr != nil {
+			result.Error = applyErr
+			return result
+		}
+	}
+	for _, op := range planned.Variables {
+		if applyErr := s.applyVariableOperation(ctx, planned.Owner, planned.Name, op); applyErr != nil {
+			result.Error = applyErr
+			return result
+		}
+	}
+	for _, op := range planned.Secrets {
+		if applyErr := s.applySecretOperation(ctx, planned.Owner, planned.Name, op); applyErr != nil {
+			result.Error = applyErr
+			return result
+		}
+	}
+	for _, op := range planned.Environments {
+		if applyErr := s.applyEnvironmentOperation(ctx, planned.Owner, planned.Name, op); applyErr != nil {
+			result.Error = applyErr
+			return result
+		}
+	}
+	for _, op := range planned.DeployKeys {
+		if applyErr := s.applyDeployKeyOperation(ctx, planned.Owner, planned.Name, op); applyErr != nil {
+			result.Error = applyErr
+			return result
+		}
+	}
+	for _, op := range planned.Security {
+		if applyErr := s.applySecurityOperation(ctx, planned.Owner, planned.Name, op); applyErr != nil {
+			result.Error = applyErr
+			return result
+		}
+	}
+	if planned.Actions != nil {
+		if applyErr := s.client.SetActionsPermissions(ctx, planned.Owner, planned.Name, planned.Actions); applyErr != nil {
+			result.Error = applyErr
+			return result
+		}
+	}
+	if planned.Files != nil {
+		if applyErr := s.applyFileSync(ctx, planned.Owner, planned.Name, planned.Files); applyErr != nil {
+			result.Error = applyErr
+			return result
+		}
+	}
+
+	return result
+}
+
+// fingerprint hashes the observed state.
+func (o observedState) fingerprint() (string, error) {
+	data, err := json.Marshal(o)
+	if err != nil {
+		return "", fmt.Errorf("failed to fingerprint state: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
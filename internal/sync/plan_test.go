@@ -0,0 +1,113 @@
+package sync //nolint:testpackage // Tests internal implementation details
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/mholtzscher/github-janitor/internal/config"
+	"github.com/mholtzscher/github-janitor/internal/github"
+)
+
+func planFixture() (*fakeGitHubClient, *config.Config) {
+	fake, cfg := repoFixture(config.Settings{
+		AllowMergeCommit: boolPtr(false),
+		BranchProtection: config.BranchProtectionRules{{
+			Enabled:         boolPtr(true),
+			Pattern:         "main",
+			RequiredReviews: intPtr(1),
+		}},
+	})
+	fake.getRepoResp.AllowMergeCommit = true
+	fake.getBranchResp = &github.BranchProtectionInfo{Enabled: true, Pattern: "main"}
+	return fake, cfg
+}
+
+func TestPlan_RoundTripAndApply(t *testing.T) {
+	fake, cfg := planFixture()
+	s := &Syncer{client: fake, config: cfg}
+
+	results, err := s.SyncAll(t.Context(), true)
+	if err != nil {
+		t.Fatalf("SyncAll() error = %v", err)
+	}
+	plan := NewPlan(results)
+	if len(plan.Repositories) != 1 {
+		t.Fatalf("len(plan.Repositories) = %d; want 1", len(plan.Repositories))
+	}
+
+	path := filepath.Join(t.TempDir(), "plan.json")
+	if writeErr := WritePlan(path, plan); writeErr != nil {
+		t.Fatalf("WritePlan() error = %v", writeErr)
+	}
+	loaded, err := ReadPlan(path)
+	if err != nil {
+		t.Fatalf("ReadPlan() error = %v", err)
+	}
+
+	planned := loaded.Repositories[0]
+	if planned.Settings == nil || planned.Settings.AllowMergeCommit == nil || *planned.Settings.AllowMergeCommit {
+		t.Fatalf("Settings = %+v; want allow_merge_commit false", planned.Settings)
+	}
+	if len(planned.BranchProtections) != 1 || planned.BranchProtections[0].Protection == nil ||
+		planned.BranchProtections[0].Protection.RequiredReviews != 1 {
+		t.Fatalf("BranchProtections = %+v; want 1 required review", planned.BranchProtections)
+	}
+
+	applied := s.ApplyPlan(t.Context(), loaded)
+	if applied[0].Error != nil {
+		t.Fatalf("Error = %v; want nil", applied[0].Error)
+	}
+	if fake.updateRepoCalls != 1 || fake.updateBranchCalls != 1 {
+		t.Fatalf("update calls = %d/%d; want 1/1", fake.updateRepoCalls, fake.updateBranchCalls)
+	}
+	if len(applied[0].Changes) != len(results[0].Changes) {
+		t.Fatalf("len(Changes) = %d; want %d", len(applied[0].Changes), len(results[0].Changes))
+	}
+}
+
+func TestPlan_RecordsEnterpriseAPIURL(t *testing.T) {
+	const ghes = "https://github.example.com/api/v3/"
+	path := filepath.Join(t.TempDir(), "plan.json")
+	if err := WritePlan(path, &Plan{Version: PlanVersion, APIURL: ghes}); err != nil {
+		t.Fatalf("WritePlan() error = %v", err)
+	}
+	plan, err := ReadPlan(path)
+	if err != nil {
+		t.Fatalf("ReadPlan() error = %v", err)
+	}
+	if plan.APIURL != ghes {
+		t.Fatalf("APIURL = %q; want %q", plan.APIURL, ghes)
+	}
+
+	if checkErr := plan.CheckAPIURL(ghes); checkErr != nil {
+		t.Fatalf("CheckAPIURL(%q) error = %v", ghes, checkErr)
+	}
+	for _, apiURL := range []string{"", "https://other.example.com/api/v3/"} {
+		if checkErr := plan.CheckAPIURL(apiURL); checkErr == nil {
+			t.Fatalf("CheckAPIURL(%q) error = nil; want server mismatch", apiURL)
+		}
+	}
+}
+
+func TestApplyPlan_RefusesOnDrift(t *testing.T) {
+	fake, cfg := planFixture()
+	s := &Syncer{client: fake, config: cfg}
+
+	results, err := s.SyncAll(t.Context(), true)
+	if err != nil {
+		t.Fatalf("SyncAll() error = %v", err)
+	}
+	plan := NewPlan(results)
+
+	// Someone edits the repository in the UI after the plan was reviewed.
+	fake.getBranchResp = &github.BranchProtectionInfo{Enabled: true, Pattern: "main", AllowDeletions: true}
+
+	applied := s.ApplyPlan(t.Context(), plan)
+	if !errors.Is(applied[0].Error, ErrDrift) {
+		t.Fatalf("Error = %v; want %v", applied[0].Error, ErrDrift)
+	}
+	if fake.updateRepoCalls != 0 || fake.updateBranchCalls != 0 {
+		t.Fatalf("update calls = %d/%d; want 0/0", fake.updateRepoCalls, fake.updateBranchCalls)
+	}
+}
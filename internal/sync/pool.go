@@ -0,0 +1,77 @@
+package sync
+
+import (
+	"context"
+	gosync "sync"
+	"sync/atomic"
+)
+
+// failFastReason is reported for repositories left untouched after a failure
+// when fail-fast is enabled.
+const failFastReason = "not processed: an earlier repository failed (--fail-fast)"
+
+// runPool runs task for each index in [0, n) on a bounded pool of workers and
+// returns the results in index order, regardless of completion order.
+//
+// Tasks not yet started when ctx is done, or after the first failure when
+// fail-fast is enabled, are not run; they are reported as not processed under
+// the repository name returned by name.
+func (s *Syncer) runPool(
+	ctx context.Context,
+	n int,
+	name func(i int) string,
+	task func(ctx context.Context, i int) Result,
+) []Result {
+	results := make([]Result, n)
+
+	workers := max(s.parallelism, 1)
+	workers = min(workers, n)
+
+	var failed atomic.Bool
+	jobs := make(chan int)
+
+	var wg gosync.WaitGroup
+	for range workers {
+		wg.Go(func() {
+			for i := range jobs {
+				switch {
+				case ctx.Err() != nil:
+					results[i] = notProcessedResult(name(i), "not processed: "+context.Cause(ctx).Error())
+				case s.failFast && failed.Load():
+					results[i] = notProcessedResult(name(i), failFastReason)
+				default:
+					results[i] = task(ctx, i)
+					if results[i].failed() {
+						failed.Store(true)
+					}
+				}
+			}
+		})
+	}
+
+	for i := range n {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
+
+// notProcessedResult builds the result for a repository that was never started.
+func notProcessedResult(repository, reason string) Result {
+	result := skippedResult(repository, reason)
+	result.NotProcessed = true
+	return result
+}
+
+// NotProcessed returns the names of repositories that were never started.
+func NotProcessed(results []Result) []string {
+	var names []string
+	for _, result := range results {
+		if result.NotProcessed {
+			names = append(names, result.Repository)
+		}
+	}
+	return names
+}
@@ -0,0 +1,328 @@
+package sync
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+
+	"github.com/mholtzscher/github-janitor/internal/config"
+	"github.com/mholtzscher/github-janitor/internal/github"
+)
+
+// Actions of a planned operation on a repository sub-resource.
+const (
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionDelete = "delete"
+)
+
+// Presence values shown when a sub-resource is created or deleted, or is
+// reported as unmanaged.
+const (
+	resourceAbsent    = "absent"
+	resourcePresent   = "present"
+	resourceUnmanaged = "unmanaged"
+)
+
+// RulesetOperation is a planned create, update or delete of a ruleset.
+type RulesetOperation struct {
+	Action string `json:"action"`
+
+	// ID is the existing ruleset to update or delete.
+	ID int64 `json:"id,omitempty"`
+
+	Ruleset *github.RulesetInfo `json:"ruleset"`
+}
+
+// syncRulesets reconciles the configured rulesets with those on the
+// repository, matching them by name. Rulesets that are not configured are
+// left alone.
+func (s *Syncer) syncRulesets(
+	ctx context.Context,
+	repo config.Repository,
+	settings config.Settings,
+	origins config.Origins,
+	dryRun bool,
+) Result {
+	source := origins.Of("rulesets")
+
+	result := Result{
+		Repository: repo.FullName(),
+		Changes:    make([]Change, 0),
+	}
+
+	current, err := s.client.ListRulesets(ctx, repo.Owner, repo.Name)
+	if err != nil {
+		result.Error = err
+		return result
+	}
+	existing := make(map[string]github.RulesetInfo, len(current))
+	for _, ruleset := range current {
+		existing[ruleset.Name] = ruleset
+	}
+
+	planned := &PlannedRepository{Owner: repo.Owner, Name: repo.Name}
+	result.Planned = planned
+
+	for _, configured := range settings.Rulesets {
+		found, exists := existing[configured.Name]
+		field := "rulesets." + configured.Name
+
+		switch {
+		case configured.Delete && exists:
+			result.Changes = append(result.Changes, Change{
+				Field:   field,
+				Current: resourcePresent,
+				Desired: resourceAbsent,
+				Source:  source,
+				Removal: true,
+			})
+			planned.Rulesets = append(planned.Rulesets, RulesetOperation{
+				Action:  ActionDelete,
+				ID:      found.ID,
+				Ruleset: &github.RulesetInfo{Name: found.Name},
+			})
+		case configured.Delete:
+			continue
+		case exists:
+			desired := desiredRuleset(configured)
+			changes, diffErr := diffRulesets(field, &found, &desired)
+			if diffErr != nil {
+				result.Error = diffErr
+				return result
+			}
+			if len(changes) == 0 {
+				continue
+			}
+			result.Changes = append(result.Changes, withSource(changes, source)...)
+			planned.Rulesets = append(planned.Rulesets, RulesetOperation{
+				Action:  ActionUpdate,
+				ID:      found.ID,
+				Ruleset: &desired,
+			})
+		default:
+			desired := desiredRuleset(configured)
+			changes, diffErr := diffRulesets(field, nil, &desired)
+			if diffErr != nil {
+				result.Error = diffErr
+				return result
+			}
+			result.Changes = append(result.Changes, Change{
+				Field:   field,
+				Current: resourceAbsent,
+				Desired: resourcePresent,
+				Source:  source,
+			})
+			result.Changes = append(result.Changes, withSource(changes, source)...)
+			planned.Rulesets = append(planned.Rulesets, RulesetOperation{Action: ActionCreate, Ruleset: &desired})
+		}
+	}
+
+	if len(planned.Rulesets) > 0 {
+		planned.observed.Rulesets = current
+	}
+
+	if !dryRun {
+		for _, op := range planned.Rulesets {
+			if applyErr := s.applyRulesetOperation(ctx, repo.Owner, repo.Name, op); applyErr != nil {
+				result.Error = applyErr
+				return result
+			}
+		}
+	}
+
+	return result
+}
+
+// applyRulesetOperation performs a single planned ruleset operation.
+func (s *Syncer) applyRulesetOperation(ctx context.Context, owner, name string, op RulesetOperation) error {
+	switch op.Action {
+	case ActionCreate:
+		return s.client.CreateRuleset(ctx, owner, name, op.Ruleset)
+	case ActionUpdate:
+		return s.client.UpdateRuleset(ctx, owner, name, op.ID, op.Ruleset)
+	case ActionDelete:
+		return s.client.DeleteRuleset(ctx, owner, name, op.ID)
+	}
+	return fmt.Errorf("unknown ruleset action %q", op.Action)
+}
+
+// desiredRuleset converts a configured ruleset into its API form, filling in
+// the defaults GitHub applies.
+func desiredRuleset(rs config.Ruleset) github.RulesetInfo {
+	info := github.RulesetInfo{
+		Name:        rs.Name,
+		Target:      rs.TargetOrDefault(),
+		Enforcement: rs.EnforcementOrDefault(),
+		Include:     rs.Include,
+		Exclude:     rs.Exclude,
+	}
+	for _, actor := range rs.BypassActors {
+		mode := actor.BypassMode
+		if mode == "" {
+			mode = "always"
+		}
+		info.BypassActors = append(info.BypassActors, github.RulesetBypassActor{
+			ActorType:  actor.ActorType,
+			ActorID:    actor.ActorID,
+			BypassMode: mode,
+		})
+	}
+
+	rules := rs.Rules
+	info.Rules = github.RulesetRules{
+		Creation:                 rules.Creation,
+		Update:                   rules.Update,
+		Deletion:                 rules.Deletion,
+		RequiredLinearHistory:    rules.RequiredLinearHistory,
+		RequiredSignatures:       rules.RequiredSignatures,
+		NonFastForward:           rules.NonFastForward,
+		RequiredDeployments:      rules.RequiredDeployments,
+		CommitMessagePattern:     desiredPatternRule(rules.CommitMessagePattern),
+		CommitAuthorEmailPattern: desiredPatternRule(rules.CommitAuthorEmailPattern),
+		CommitterEmailPattern:    desiredPatternRule(rules.CommitterEmailPattern),
+		BranchNamePattern:        desiredPatternRule(rules.BranchNamePattern),
+		TagNamePattern:           desiredPatternRule(rules.TagNamePattern),
+		FilePathRestriction:      rules.FilePathRestriction,
+		FileExtensionRestriction: rules.FileExtensionRestriction,
+		MaxFilePathLength:        rules.MaxFilePathLength,
+		MaxFileSize:              rules.MaxFileSize,
+	}
+	if pr := rules.PullRequest; pr != nil {
+		info.Rules.PullRequest = &github.RulesetPullRequest{
+			RequiredApprovingReviewCount:   pr.RequiredApprovingReviewCount,
+			DismissStaleReviewsOnPush:      pr.DismissStaleReviewsOnPush,
+			RequireCodeOwnerReview:         pr.RequireCodeOwnerReview,
+			RequireLastPushApproval:        pr.RequireLastPushApproval,
+			RequiredReviewThreadResolution: pr.RequiredReviewThreadResolution,
+		}
+	}
+	if checks := rules.RequiredStatusChecks; checks != nil {
+		info.Rules.RequiredStatusChecks = &github.RulesetStatusChecks{
+			Contexts: checks.Contexts,
+			Strict:   checks.Strict,
+		}
+	}
+
+	return normalizeRuleset(info)
+}
+
+func desiredPatternRule(rule *config.PatternRule) *github.RulesetPatternRule {
+	if rule == nil {
+		return nil
+	}
+	return &github.RulesetPatternRule{
+		Name:     rule.Name,
+		Operator: rule.Operator,
+		Pattern:  rule.Pattern,
+		Negate:   rule.Negate,
+	}
+}
+
+// normalizeRuleset returns a copy of the ruleset with its lists sorted and
+// empty lists dropped, so that ordering differences are not reported as drift.
+func normalizeRuleset(info github.RulesetInfo) github.RulesetInfo {
+	info.Include = sortedStrings(info.Include)
+	info.Exclude = sortedStrings(info.Exclude)
+	info.Rules.RequiredDeployments = sortedStrings(info.Rules.RequiredDeployments)
+	info.Rules.FilePathRestriction = sortedStrings(info.Rules.FilePathRestriction)
+	info.Rules.FileExtensionRestriction = sortedStrings(info.Rules.FileExtensionRestriction)
+	if checks := info.Rules.RequiredStatusChecks; checks != nil {
+		info.Rules.RequiredStatusChecks = &github.RulesetStatusChecks{
+			Contexts: sortedStrings(checks.Contexts),
+			Strict:   checks.Strict,
+		}
+	}
+
+	if len(info.BypassActors) == 0 {
+		info.BypassActors = nil
+	} else {
+		info.BypassActors = slices.Clone(info.BypassActors)
+		slices.SortFunc(info.BypassActors, func(a, b github.RulesetBypassActor) int {
+			return cmp.Or(cmp.Compare(a.ActorType, b.ActorType), cmp.Compare(a.ActorID, b.ActorID))
+		})
+	}
+
+	return info
+}
+
+// sortedStrings returns a sorted copy of values, or nil if it is empty.
+func sortedStrings(values []string) []string {
+	if len(values) == 0 {
+		return nil
+	}
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+	return sorted
+}
+
+// diffRulesets reports every leaf value that differs between two rulesets as
+// a change under prefix, e.g. "rulesets.main.rules.pull_request.required_approving_review_count".
+// A nil current ruleset reports every desired value.
+func diffRulesets(prefix string, current, desired *github.RulesetInfo) ([]Change, error) {
+	var currentValue, desiredValue any
+	if current != nil {
+		normalized := normalizeRuleset(*current)
+		if err := roundTripJSON(&normalized, &currentValue); err != nil {
+			return nil, err
+		}
+	}
+	if err := roundTripJSON(desired, &desiredValue); err != nil {
+		return nil, err
+	}
+
+	changes := make([]Change, 0)
+	diffValues(prefix, currentValue, desiredValue, &changes)
+	return changes, nil
+}
+
+// roundTripJSON converts v into its generic JSON form (maps, slices and scalars).
+func roundTripJSON(v any, out *any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode ruleset: %w", err)
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to decode ruleset: %w", err)
+	}
+	return nil
+}
+
+// diffValues walks two generic JSON values and records each differing leaf.
+// Objects are compared key by key, treating a missing object as empty; any
+// other value, including lists, is compared as a whole.
+func diffValues(path string, current, desired any, changes *[]Change) {
+	currentMap, currentIsMap := current.(map[string]any)
+	desiredMap, desiredIsMap := desired.(map[string]any)
+	if (currentIsMap || current == nil) && (desiredIsMap || desired == nil) && (currentIsMap || desiredIsMap) {
+		keys := make([]string, 0, len(currentMap)+len(desiredMap))
+		for key := range currentMap {
+			keys = append(keys, key)
+		}
+		for key := range desiredMap {
+			if _, ok := currentMap[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		slices.Sort(keys)
+		for _, key := range keys {
+			diffValues(path+"."+key, currentMap[key], desiredMap[key], changes)
+		}
+		return
+	}
+
+	if !reflect.DeepEqual(current, desired) {
+		*changes = append(*changes, Change{Field: path, Current: current, Desired: desired})
+	}
+}
+
+// withSource sets the config layer on each change.
+func withSource(changes []Change, source string) []Change {
+	for i := range changes {
+		changes[i].Source = source
+	}
+	return changes
+}
@@ -0,0 +1,140 @@
+package sync //nolint:testpackage // Tests internal implementation details
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/mholtzscher/github-janitor/internal/config"
+	"github.com/mholtzscher/github-janitor/internal/github"
+)
+
+func rulesetFixture() (*fakeGitHubClient, *config.Config) {
+	fake, cfg := repoFixture(config.Settings{
+		Rulesets: []config.Ruleset{
+			{
+				Name:    "main",
+				Include: []string{"~DEFAULT_BRANCH"},
+				Rules: config.RulesetRules{
+					Deletion:    true,
+					PullRequest: &config.PullRequestRule{RequiredApprovingReviewCount: 2},
+				},
+			},
+			{Name: "legacy", Delete: true},
+			{
+				Name:   "push-limits",
+				Target: config.RulesetTargetPush,
+				Rules:  config.RulesetRules{FilePathRestriction: []string{"secrets/**"}},
+			},
+		},
+	})
+	fake.rulesets = []github.RulesetInfo{
+		{
+			ID: 1, Name: "main", Target: "branch", Enforcement: "active",
+			Include: []string{"~DEFAULT_BRANCH"}, Exclude: []string{},
+			Rules: github.RulesetRules{
+				Deletion:    true,
+				PullRequest: &github.RulesetPullRequest{RequiredApprovingReviewCount: 1},
+			},
+		},
+		{ID: 2, Name: "legacy", Target: "branch", Enforcement: "active", Include: []string{"~ALL"}},
+		{ID: 3, Name: "unmanaged", Target: "tag", Enforcement: "active", Include: []string{"~ALL"}},
+	}
+	return fake, cfg
+}
+
+func TestSyncAll_Rulesets(t *testing.T) {
+	fake, cfg := rulesetFixture()
+	s := &Syncer{client: fake, config: cfg}
+
+	results, err := s.SyncAll(t.Context(), true)
+	if err != nil {
+		t.Fatalf("SyncAll() error = %v", err)
+	}
+	if results[0].Error != nil {
+		t.Fatalf("Error = %v; want nil", results[0].Error)
+	}
+
+	changes := changeByField(t, results[0].Changes)
+	review := changes["rulesets.main.rules.pull_request.required_approving_review_count"]
+	if review.Current != float64(1) || review.Desired != float64(2) {
+		t.Fatalf("review count change = %+v; want 1 → 2", review)
+	}
+	if review.Source != config.LayerGlobal {
+		t.Fatalf("Source = %q; want %q", review.Source, config.LayerGlobal)
+	}
+	if c := changes["rulesets.legacy"]; c.Current != resourcePresent || c.Desired != resourceAbsent {
+		t.Fatalf("legacy change = %+v; want deletion", c)
+	}
+	if c := changes["rulesets.push-limits"]; c.Current != resourceAbsent || c.Desired != resourcePresent {
+		t.Fatalf("push-limits change = %+v; want creation", c)
+	}
+	if c := changes["rulesets.push-limits.rules.file_path_restriction"]; c.Current != nil ||
+		!reflect.DeepEqual(c.Desired, []any{"secrets/**"}) {
+		t.Fatalf("file_path_restriction change = %+v; want new restriction", c)
+	}
+	for field := range changes {
+		if field == "rulesets.main.include" || field == "rulesets.main.exclude" || field == "rulesets.unmanaged" {
+			t.Fatalf("unexpected change for %s", field)
+		}
+	}
+	if len(fake.rulesetCalls) != 0 {
+		t.Fatalf("ruleset calls = %v; want none in dry-run", fake.rulesetCalls)
+	}
+
+	actions := make([]string, 0, len(results[0].Planned.Rulesets))
+	for _, op := range results[0].Planned.Rulesets {
+		actions = append(actions, op.Action+" "+op.Ruleset.Name)
+	}
+	want := []string{"update main", "delete legacy", "create push-limits"}
+	if !reflect.DeepEqual(actions, want) {
+		t.Fatalf("planned operations = %v; want %v", actions, want)
+	}
+
+	if _, syncErr := s.SyncAll(t.Context(), false); syncErr != nil {
+		t.Fatalf("SyncAll() error = %v", syncErr)
+	}
+	wantCalls := []string{"update 1 main", "delete 2", "create push-limits"}
+	if !reflect.DeepEqual(fake.rulesetCalls, wantCalls) {
+		t.Fatalf("ruleset calls = %v; want %v", fake.rulesetCalls, wantCalls)
+	}
+}
+
+func TestSyncAll_RulesetsInSync(t *testing.T) {
+	fake, cfg := rulesetFixture()
+	cfg.Settings.Rulesets = cfg.Settings.Rulesets[:1]
+	cfg.Settings.Rulesets[0].Rules.PullRequest.RequiredApprovingReviewCount = 1
+	s := &Syncer{client: fake, config: cfg}
+
+	results, err := s.SyncAll(t.Context(), true)
+	if err != nil {
+		t.Fatalf("SyncAll() error = %v", err)
+	}
+	if len(results[0].Changes) != 0 {
+		t.Fatalf("Changes = %+v; want none", results[0].Changes)
+	}
+	if results[0].Planned.hasChanges() {
+		t.Fatal("hasChanges() = true; want false")
+	}
+}
+
+func TestApplyPlan_RulesetDrift(t *testing.T) {
+	fake, cfg := rulesetFixture()
+	s := &Syncer{client: fake, config: cfg}
+
+	results, err := s.SyncAll(t.Context(), true)
+	if err != nil {
+		t.Fatalf("SyncAll() error = %v", err)
+	}
+	plan := NewPlan(results)
+
+	fake.rulesets = fake.rulesets[:2]
+
+	applied := s.ApplyPlan(t.Context(), plan)
+	if !errors.Is(applied[0].Error, ErrDrift) {
+		t.Fatalf("Error = %v; want %v", applied[0].Error, ErrDrift)
+	}
+	if len(fake.rulesetCalls) != 0 {
+		t.Fatalf("ruleset calls = %v; want none", fake.rulesetCalls)
+	}
+}
@@ -0,0 +1,220 @@
+package sync
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	gosync "sync"
+
+	"github.com/mholtzscher/github-janitor/internal/config"
+)
+
+// secretWillBeSet is the desired value shown for a secret to be uploaded.
+const secretWillBeSet = "secret will be set"
+
+// secretRecordFileMode keeps the secret record readable by its owner only.
+const secretRecordFileMode = 0o600
+
+// SecretOperation is a planned upload of an Actions secret. The value is read
+// from Env or File when the operation is applied, so plans never contain it.
+type SecretOperation struct {
+	Action string `json:"action"`
+	Name   string `json:"name"`
+	Env    string `json:"env,omitempty"`
+	File   string `json:"file,omitempty"`
+}
+
+// source returns the configured source of the secret value.
+func (op SecretOperation) source() config.Secret {
+	return config.Secret{Env: op.Env, File: op.File}
+}
+
+// secretRecord remembers a hash of every secret value uploaded, since GitHub
+// never returns secret values. It is loaded from and saved to a local JSON
+// file; with no path it is kept in memory only.
+type secretRecord struct {
+	path string
+
+	mu     gosync.Mutex
+	loaded bool
+	hashes map[string]string
+}
+
+// secretRecordFile is the layout of the secret record file.
+type secretRecordFile struct {
+	Secrets map[string]string `json:"secrets"`
+}
+
+// newSecretRecord returns a record backed by the file at path.
+func newSecretRecord(path string) *secretRecord {
+	return &secretRecord{path: path}
+}
+
+// matches reports whether value is the value last uploaded for the secret.
+func (r *secretRecord) matches(repo, secret, value string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.load(); err != nil {
+		return false, err
+	}
+	return r.hashes[secretKey(repo, secret)] == hashSecret(repo, secret, value), nil
+}
+
+// store records value as uploaded for the secret and saves the record.
+func (r *secretRecord) store(repo, secret, value string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.load(); err != nil {
+		return err
+	}
+	r.hashes[secretKey(repo, secret)] = hashSecret(repo, secret, value)
+	if r.path == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(secretRecordFile{Secrets: r.hashes}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode secret record: %w", err)
+	}
+	if writeErr := os.WriteFile(r.path, append(data, '\n'), secretRecordFileMode); writeErr != nil {
+		return fmt.Errorf("failed to write secret record: %w", writeErr)
+	}
+	return nil
+}
+
+// load reads the record file once. A missing file is an empty record.
+func (r *secretRecord) load() error {
+	if r.loaded {
+		return nil
+	}
+	r.hashes = make(map[string]string)
+	if r.path != "" {
+		data, err := os.ReadFile(r.path)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+		case err != nil:
+			return fmt.Errorf("failed to read secret record: %w", err)
+		default:
+			var file secretRecordFile
+			if parseErr := json.Unmarshal(data, &file); parseErr != nil {
+				return fmt.Errorf("failed to parse secret record %s: %w", r.path, parseErr)
+			}
+			maps.Copy(r.hashes, file.Secrets)
+		}
+	}
+	r.loaded = true
+	return nil
+}
+
+// secretKey identifies a secret in the record. Repository and secret names
+// are case-insensitive.
+func secretKey(repo, secret string) string {
+	return strings.ToLower(repo) + "/" + strings.ToUpper(secret)
+}
+
+// hashSecret hashes a secret value together with its key, so that equal
+// values stored in different places have different hashes.
+func hashSecret(repo, secret, value string) string {
+	sum := sha256.Sum256([]byte(secretKey(repo, secret) + "\x00" + value))
+	return hex.EncodeToString(sum[:])
+}
+
+// syncSecrets uploads the configured Actions secrets that are missing from
+// the repository or whose value differs from the one last uploaded, as
+// remembered by the secret record. Secrets that are not configured are left
+// alone, and values never appear in changes.
+func (s *Syncer) syncSecrets(
+	ctx context.Context,
+	repo config.Repository,
+	settings config.Settings,
+	origins config.Origins,
+	dryRun bool,
+) Result {
+
+	result := Result{
+		Repository: repo.FullName(),
+		Changes:    make([]Change, 0),
+	}
+
+	current, err := s.client.ListSecrets(ctx, repo.Owner, repo.Name)
+	if err != nil {
+		result.Error = err
+		return result
+	}
+	existing := make(map[string]bool, len(current))
+	for _, secret := range current {
+		existing[strings.ToUpper(secret.Name)] = true
+	}
+
+	planned := &PlannedRepository{Owner: repo.Owner, Name: repo.Name}
+	result.Planned = planned
+
+	for _, name := range slices.Sorted(maps.Keys(settings.Secrets)) {
+		source := settings.Secrets[name]
+		change := Change{
+			Field:   "secrets." + name,
+			Current: resourceAbsent,
+			Desired: secretWillBeSet,
+			Source:  origins.Of("secrets." + name),
+		}
+		op := SecretOperation{Action: ActionCreate, Name: name, Env: source.Env, File: source.File}
+
+		// Read the value even when the secret is created, so that a missing
+		// source is reported when planning rather than when applying.
+		value, valueErr := source.Value()
+		if valueErr != nil {
+			result.Error = fmt.Errorf("secret %s: %w", name, valueErr)
+			return result
+		}
+		if existing[strings.ToUpper(name)] {
+			uploaded, recordErr := s.secrets.matches(repo.FullName(), name, value)
+			if recordErr != nil {
+				result.Error = recordErr
+				return result
+			}
+			if uploaded {
+				continue
+			}
+			change.Current = secretSet
+			op.Action = ActionUpdate
+		}
+		result.Changes = append(result.Changes, change)
+		planned.Secrets = append(planned.Secrets, op)
+	}
+
+	if len(planned.Secrets) > 0 {
+		planned.observed.Secrets = current
+	}
+
+	if !dryRun {
+		for _, op := range planned.Secrets {
+			if applyErr := s.applySecretOperation(ctx, repo.Owner, repo.Name, op); applyErr != nil {
+				result.Error = applyErr
+				return result
+			}
+		}
+	}
+
+	return result
+}
+
+// applySecretOperation reads a secret value from its source, uploads it and
+// records its hash.
+func (s *Syncer) applySecretOperation(ctx context.Context, owner, name string, op SecretOperation) error {
+	value, err := op.source().Value()
+	if err != nil {
+		return fmt.Errorf("secret %s: %w", op.Name, err)
+	}
+	if setErr := s.client.SetSecret(ctx, owner, name, op.Name, value); setErr != nil {
+		return setErr
+	}
+	return s.secrets.store(owner+"/"+name, op.Name, value)
+}
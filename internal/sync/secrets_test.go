@@ -0,0 +1,120 @@
+package sync //nolint:testpackage // Tests internal implementation details
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mholtzscher/github-janitor/internal/config"
+	"github.com/mholtzscher/github-janitor/internal/github"
+)
+
+func secretFixture(t *testing.T) (*fakeGitHubClient, *config.Config, string) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("NPM_TOKEN", "npm-s3cret")
+	keyPath := filepath.Join(dir, "signing.pem")
+	if err := os.WriteFile(keyPath, []byte("key-s3cret\n"), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	fake, cfg := repoFixture(config.Settings{
+		Secrets: map[string]config.Secret{
+			"NPM_TOKEN":   {Env: "NPM_TOKEN"},
+			"SIGNING_KEY": {File: keyPath},
+		},
+	})
+	fake.secrets = []github.SecretInfo{{Name: "NPM_TOKEN"}}
+	return fake, cfg, filepath.Join(dir, "secrets.json")
+}
+
+func TestSyncAll_Secrets(t *testing.T) {
+	fake, cfg, recordPath := secretFixture(t)
+	s := &Syncer{client: fake, config: cfg, secrets: newSecretRecord(recordPath)}
+
+	results, err := s.SyncAll(t.Context(), true)
+	if err != nil {
+		t.Fatalf("SyncAll() error = %v", err)
+	}
+	if results[0].Error != nil {
+		t.Fatalf("Error = %v; want nil", results[0].Error)
+	}
+
+	changes := changeByField(t, results[0].Changes)
+	if c := changes["secrets.NPM_TOKEN"]; c.Current != secretSet || c.Desired != secretWillBeSet {
+		t.Fatalf("NPM_TOKEN change = %+v; want set → %s", c, secretWillBeSet)
+	}
+	if c := changes["secrets.SIGNING_KEY"]; c.Current != resourceAbsent || c.Desired != secretWillBeSet {
+		t.Fatalf("SIGNING_KEY change = %+v; want absent → %s", c, secretWillBeSet)
+	}
+
+	data, err := json.Marshal(struct {
+		Changes []Change
+		Plan    *Plan
+	}{results[0].Changes, NewPlan(results)})
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if strings.Contains(string(data), "s3cret") {
+		t.Fatalf("secret leaked into changes or plan: %s", data)
+	}
+
+	if _, syncErr := s.SyncAll(t.Context(), false); syncErr != nil {
+		t.Fatalf("SyncAll() error = %v", syncErr)
+	}
+	want := []string{"set NPM_TOKEN=npm-s3cret", "set SIGNING_KEY=key-s3cret"}
+	if !reflect.DeepEqual(fake.secretCalls, want) {
+		t.Fatalf("secret calls = %v; want %v", fake.secretCalls, want)
+	}
+
+	record, err := os.ReadFile(recordPath)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if strings.Contains(string(record), "s3cret") {
+		t.Fatalf("secret leaked into record: %s", record)
+	}
+
+	// A new run reads the record and uploads only the secret whose value changed.
+	fake.secrets = append(fake.secrets, github.SecretInfo{Name: "SIGNING_KEY"})
+	fake.secretCalls = nil
+	t.Setenv("NPM_TOKEN", "rotated")
+	s = &Syncer{client: fake, config: cfg, secrets: newSecretRecord(recordPath)}
+
+	results, err = s.SyncAll(t.Context(), true)
+	if err != nil {
+		t.Fatalf("SyncAll() error = %v", err)
+	}
+	changes = changeByField(t, results[0].Changes)
+	if len(changes) != 1 || changes["secrets.NPM_TOKEN"].Desired != secretWillBeSet {
+		t.Fatalf("changes = %+v; want only secrets.NPM_TOKEN", changes)
+	}
+}
+
+func TestSyncAll_SecretSourceMissing(t *testing.T) {
+	fake, cfg, recordPath := secretFixture(t)
+	t.Setenv("NPM_TOKEN", "")
+	s := &Syncer{client: fake, config: cfg, secrets: newSecretRecord(recordPath)}
+
+	results, err := s.SyncAll(t.Context(), true)
+	if err != nil {
+		t.Fatalf("SyncAll() error = %v", err)
+	}
+	if results[0].Error == nil || !strings.Contains(results[0].Error.Error(), "NPM_TOKEN") {
+		t.Fatalf("Error = %v; want missing NPM_TOKEN", results[0].Error)
+	}
+}
+
+func TestSecretRecord_CorruptFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secrets.json")
+	if err := os.WriteFile(path, []byte("{"), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	if _, err := newSecretRecord(path).matches("o/r", "TOKEN", "value"); err == nil {
+		t.Fatal("matches() error = nil; want parse error")
+	}
+}
@@ -0,0 +1,130 @@
+package sync
+
+import (
+	"context"
+	"slices"
+
+	"github.com/mholtzscher/github-janitor/internal/config"
+	"github.com/mholtzscher/github-janitor/internal/github"
+)
+
+// SecurityOperation is a planned change to a security feature.
+type SecurityOperation struct {
+	Feature string `json:"feature"`
+	Enabled bool   `json:"enabled"`
+}
+
+// securityFeature pairs a security feature with its configured value.
+type securityFeature struct {
+	name       string
+	configured *bool
+}
+
+// securityFeatures lists the configured features in the order they can be
+// enabled: each feature comes after the feature it depends on.
+func securityFeatures(security *config.Security) []securityFeature {
+	return []securityFeature{
+		{github.FeatureDependabotAlerts, security.DependabotAlerts},
+		{github.FeatureDependabotSecurityUpdates, security.DependabotSecurityUpdates},
+		{github.FeatureSecretScanning, security.SecretScanning},
+		{github.FeatureSecretScanningPushProtection, security.SecretScanningPushProtection},
+		{github.FeaturePrivateVulnerabilityReporting, security.PrivateVulnerabilityReporting},
+		{github.FeatureCodeScanningDefaultSetup, security.CodeScanningDefaultSetup},
+	}
+}
+
+// readSeparately reports whether a feature is read from its own endpoint
+// rather than from the repository's security_and_analysis settings.
+func readSeparately(feature string) bool {
+	switch feature {
+	case github.FeatureDependabotAlerts,
+		github.FeaturePrivateVulnerabilityReporting,
+		github.FeatureCodeScanningDefaultSetup:
+		return true
+	}
+	return false
+}
+
+// syncSecurity reconciles the configured security features. Features are
+// disabled before the features they depend on and enabled after them.
+func (s *Syncer) syncSecurity(
+	ctx context.Context,
+	repo config.Repository,
+	current *github.RepositoryInfo,
+	settings config.Settings,
+	origins config.Origins,
+	dryRun bool,
+) Result {
+	result := Result{
+		Repository: repo.FullName(),
+		Changes:    make([]Change, 0),
+	}
+
+	planned := &PlannedRepository{Owner: repo.Owner, Name: repo.Name}
+	result.Planned = planned
+
+	reported := map[string]bool{
+		github.FeatureDependabotSecurityUpdates:    current.SecurityAndAnalysis.DependabotSecurityUpdates,
+		github.FeatureSecretScanning:               current.SecurityAndAnalysis.SecretScanning,
+		github.FeatureSecretScanningPushProtection: current.SecurityAndAnalysis.SecretScanningPushProtection,
+	}
+
+	var enable, disable []SecurityOperation
+	observed := make(map[string]bool)
+	for _, feature := range securityFeatures(settings.Security) {
+		if feature.configured == nil {
+			continue
+		}
+		enabled := reported[feature.name]
+		if readSeparately(feature.name) {
+			var err error
+			enabled, err = s.client.GetSecurityFeature(ctx, repo.Owner, repo.Name, feature.name)
+			if err != nil {
+				result.Error = err
+				return result
+			}
+		}
+		if enabled == *feature.configured {
+			continue
+		}
+
+		field := "security." + feature.name
+		result.Changes = append(result.Changes, Change{
+			Field:   field,
+			Current: enabled,
+			Desired: *feature.configured,
+			Source:  origins.Of(field),
+		})
+		op := SecurityOperation{Feature: feature.name, Enabled: *feature.configured}
+		if op.Enabled {
+			enable = append(enable, op)
+		} else {
+			disable = append(disable, op)
+		}
+		if readSeparately(feature.name) {
+			observed[feature.name] = enabled
+		}
+	}
+	slices.Reverse(disable)
+	planned.Security = append(disable, enable...)
+
+	if len(observed) > 0 {
+		planned.observed.SecurityFeatures = observed
+	}
+
+	if !dryRun {
+		for _, op := range planned.Security {
+			if applyErr := s.applySecurityOperation(ctx, repo.Owner, repo.Name, op); applyErr != nil {
+				result.Error = applyErr
+				return result
+			}
+		}
+	}
+
+	return result
+}
+
+// applySecurityOperation enables or disables a security feature.
+func (s *Syncer) applySecurityOperation(ctx context.Context, owner, name string, op SecurityOperation) error {
+	return s.client.SetSecurityFeature(ctx, owner, name, op.Feature, op.Enabled)
+}
@@ -0,0 +1,61 @@
+package sync //nolint:testpackage // Tests internal implementation details
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mholtzscher/github-janitor/internal/config"
+	"github.com/mholtzscher/github-janitor/internal/github"
+)
+
+func TestSyncAll_Security(t *testing.T) {
+	fake, cfg := repoFixture(config.Settings{
+		Security: &config.Security{
+			DependabotAlerts:              boolPtr(false),
+			DependabotSecurityUpdates:     boolPtr(false),
+			SecretScanning:                boolPtr(true),
+			PrivateVulnerabilityReporting: boolPtr(true),
+			CodeScanningDefaultSetup:      boolPtr(true),
+		},
+	})
+	fake.getRepoResp.SecurityAndAnalysis = github.SecurityAndAnalysis{
+		DependabotSecurityUpdates:    true,
+		SecretScanning:               true,
+		SecretScanningPushProtection: true,
+	}
+	fake.securityFeatures = map[string]bool{
+		github.FeatureDependabotAlerts:              true,
+		github.FeaturePrivateVulnerabilityReporting: false,
+	}
+	s := &Syncer{client: fake, config: cfg}
+
+	results, err := s.SyncAll(t.Context(), false)
+	if err != nil {
+		t.Fatalf("SyncAll() error = %v", err)
+	}
+	if results[0].Error != nil {
+		t.Fatalf("Error = %v; want nil", results[0].Error)
+	}
+
+	changes := changeByField(t, results[0].Changes)
+	if _, ok := changes["security.secret_scanning"]; ok {
+		t.Fatal("secret_scanning changed; want it left enabled")
+	}
+	if c := changes["security.dependabot_alerts"]; c.Current != true || c.Desired != false {
+		t.Fatalf("dependabot_alerts change = %+v; want true → false", c)
+	}
+	if c := changes["security.code_scanning_default_setup"]; c.Current != false || c.Desired != true {
+		t.Fatalf("code_scanning_default_setup change = %+v; want false → true", c)
+	}
+
+	// Security updates are disabled before the alerts they depend on.
+	want := []string{
+		"dependabot_security_updates=false",
+		"dependabot_alerts=false",
+		"private_vulnerability_reporting=true",
+		"code_scanning_default_setup=true",
+	}
+	if !reflect.DeepEqual(fake.securityCalls, want) {
+		t.Fatalf("security calls = %v; want %v", fake.securityCalls, want)
+	}
+}
@@ -1,8 +1,13 @@
 package sync
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"reflect"
+	"regexp"
+	"slices"
+	"strings"
 
 	gogithub "github.com/google/go-github/v82/github"
 
@@ -12,22 +17,96 @@ import (
 
 // Syncer orchestrates the synchronization of repository settings.
 type Syncer struct {
-	client githubAPI
-	config *config.Config
+	client      githubAPI
+	config      *config.Config
+	parallelism int
+	failFast    bool
+	secrets     *secretRecord
 }
 
+// Options controls how the syncer processes repositories.
+type Options struct {
+	// Parallelism is the number of repositories processed concurrently.
+	// Values below 1 process repositories one at a time.
+	Parallelism int
+
+	// FailFast stops starting new repositories after the first failure.
+	FailFast bool
+
+	// SecretsState is the path of the file recording hashes of the Actions
+	// secret values uploaded, used to decide whether to upload them again.
+	// Empty keeps the record in memory only.
+	SecretsState string
+}
+
+// githubAPI is the subset of the GitHub client used by the syncer.
+// Implementations must be safe for concurrent use.
 type githubAPI interface {
-	GetRepository(owner, name string) (*github.RepositoryInfo, error)
-	UpdateRepositorySettings(owner, name string, patch *gogithub.Repository) error
-	GetBranchProtection(owner, name, pattern string) (*github.BranchProtectionInfo, error)
-	UpdateBranchProtection(owner, name string, protection *github.BranchProtectionInfo) error
+	ListRepositories(ctx context.Context, ownerType, owner string) ([]github.RepositorySummary, error)
+	GetRepository(ctx context.Context, owner, name string) (*github.RepositoryInfo, error)
+	UpdateRepositorySettings(ctx context.Context, owner, name string, patch *gogithub.Repository) error
+	GetBranchProtection(ctx context.Context, owner, name, pattern string) (*github.BranchProtectionInfo, error)
+	UpdateBranchProtection(ctx context.Context, owner, name string, protection *github.BranchProtectionInfo) error
+	ListBranches(ctx context.Context, owner, name string) ([]string, error)
+	ListRulesets(ctx context.Context, owner, name string) ([]github.RulesetInfo, error)
+	CreateRuleset(ctx context.Context, owner, name string, ruleset *github.RulesetInfo) error
+	UpdateRuleset(ctx context.Context, owner, name string, id int64, ruleset *github.RulesetInfo) error
+	DeleteRuleset(ctx context.Context, owner, name string, id int64) error
+	ListLabels(ctx context.Context, owner, name string) ([]github.LabelInfo, error)
+	CreateLabel(ctx context.Context, owner, name string, label *github.LabelInfo) error
+	UpdateLabel(ctx context.Context, owner, name, current string, label *github.LabelInfo) error
+	DeleteLabel(ctx context.Context, owner, name, label string) error
+	ListWebhooks(ctx context.Context, owner, name string) ([]github.WebhookInfo, error)
+	CreateWebhook(ctx context.Context, owner, name string, webhook *github.WebhookInfo) error
+	UpdateWebhook(ctx context.Context, owner, name string, id int64, webhook *github.WebhookInfo) error
+	DeleteWebhook(ctx context.Context, owner, name string, id int64) error
+	ListTeamAccess(ctx context.Context, owner, name string) ([]github.AccessGrant, error)
+	SetTeamAccess(ctx context.Context, owner, name, team, permission string) error
+	RemoveTeamAccess(ctx context.Context, owner, name, team string) error
+	ListCollaborators(ctx context.Context, owner, name string) ([]github.AccessGrant, error)
+	SetCollaboratorAccess(ctx context.Context, owner, name string, collaborator *github.AccessGrant) error
+	RemoveCollaborator(ctx context.Context, owner, name string, collaborator *github.AccessGrant) error
+	ListVariables(ctx context.Context, owner, name string) ([]github.VariableInfo, error)
+	CreateVariable(ctx context.Context, owner, name string, variable *github.VariableInfo) error
+	UpdateVariable(ctx context.Context, owner, name string, variable *github.VariableInfo) error
+	ListSecrets(ctx context.Context, owner, name string) ([]github.SecretInfo, error)
+	SetSecret(ctx context.Context, owner, name, secret, value string) error
+	ListEnvironments(ctx context.Context, owner, name string) ([]github.EnvironmentInfo, error)
+	SetEnvironment(ctx context.Context, owner, name string, env *github.EnvironmentInfo) error
+	DeleteEnvironment(ctx context.Context, owner, name, env string) error
+	ListEnvironmentVariables(ctx context.Context, owner, name, env string) ([]github.VariableInfo, error)
+	CreateEnvironmentVariable(ctx context.Context, owner, name, env string, variable *github.VariableInfo) error
+	UpdateEnvironmentVariable(ctx context.Context, owner, name, env string, variable *github.VariableInfo) error
+	ListDeployKeys(ctx context.Context, owner, name string) ([]github.DeployKeyInfo, error)
+	CreateDeployKey(ctx context.Context, owner, name string, key *github.DeployKeyInfo) error
+	DeleteDeployKey(ctx context.Context, owner, name string, id int64) error
+	GetSecurityFeature(ctx context.Context, owner, name, feature string) (bool, error)
+	SetSecurityFeature(ctx context.Context, owner, name, feature string, enabled bool) error
+	GetActionsPermissions(ctx context.Context, owner, name string) (*github.ActionsPermissions, error)
+	SetActionsPermissions(ctx context.Context, owner, name string, permissions *github.ActionsPermissions) error
+	GetFile(ctx context.Context, owner, name, path, ref string) (*github.FileInfo, error)
+	CommitFiles(ctx context.Context, owner, name, base, branch, message string, files []github.FileContent) error
+	FindPullRequest(ctx context.Context, owner, name, head, base string) (*github.PullRequestInfo, bool, error)
+	CreatePullRequest(ctx context.Context, owner, name string, pr *github.PullRequestInfo) (*github.PullRequestInfo, error)
+	UpdatePullRequest(ctx context.Context, owner, name string, pr *github.PullRequestInfo) error
+	EnableAutoMerge(ctx context.Context, owner, name string, pr *github.PullRequestInfo, method string) error
 }
 
 // Change represents a single setting change.
 type Change struct {
-	Field   string
-	Current any
-	Desired any
+	Field   string `json:"field"`
+	Current any    `json:"current"`
+	Desired any    `json:"desired"`
+
+	// Source is the config layer the desired value came from (see config.LayerGlobal).
+	Source string `json:"source,omitempty"`
+
+	// Removal marks a change that deletes something from the repository,
+	// such as a team's access.
+	Removal bool `json:"removal,omitempty"`
+
+	// Diff is a unified diff of a file's content, for file changes.
+	Diff string `json:"diff,omitempty"`
 }
 
 // applySetting updates the API patch (when configured) and tracks changes.
@@ -51,6 +130,27 @@ func applySetting[T comparable](result *Result, field string, configured *T, cur
 	return false
 }
 
+// applySettingSet is applySetting for a list whose order does not matter,
+// such as topics. An empty configured list leaves the setting unmanaged.
+func applySettingSet(result *Result, field string, configured, current []string, patchField *[]string) bool {
+	if len(configured) == 0 {
+		return false
+	}
+
+	*patchField = append([]string(nil), configured...)
+
+	if !slices.Equal(slices.Sorted(slices.Values(current)), slices.Sorted(slices.Values(configured))) {
+		result.Changes = append(result.Changes, Change{
+			Field:   field,
+			Current: current,
+			Desired: configured,
+		})
+		return true
+	}
+
+	return false
+}
+
 // applyDesiredSetting applies an optional config value to a desired target and tracks changes.
 // Returns true if a change was detected.
 func applyDesiredSetting[T comparable](result *Result, field string, configured *T, desired *T) bool {
@@ -89,36 +189,247 @@ func applyDesiredStringSlice(result *Result, field string, configured []string,
 	return changed
 }
 
-// Result represents the result of syncing a single repository.
+// Result represents the result of syncing a single repository, or a single
+// protected branch of a repository.
 type Result struct {
 	Repository string
 	Exists     bool
 	Changes    []Change
 	Error      error
+
+	// Branch is the protected branch a branch protection result covers; its
+	// Repository is suffixed with " (branch: <branch>)". Empty for repositories.
+	Branch string
+
+	// Skipped explains why the repository was not synced (e.g. an exclude rule).
+	Skipped string
+
+	// NotProcessed reports that the repository was never started because the
+	// run was cancelled or stopped early by fail-fast.
+	NotProcessed bool
+
+	// Planned holds the computed patches and observed state fingerprint.
+	Planned *PlannedRepository
+
+	// branches holds the branch protection results, which SyncAll and
+	// ApplyPlan list directly after the repository's own result.
+	branches []Result
+}
+
+// mergeSubResult adds the changes of a resource sync, such as labels or
+// webhooks, to its repository's result and its operations and observed state
+// to the repository's plan. Errors are joined so none hides another.
+func mergeSubResult(result *Result, planned *PlannedRepository, sub Result) {
+	result.Changes = append(result.Changes, sub.Changes...)
+	result.Error = errors.Join(result.Error, sub.Error)
+	if sub.Planned != nil {
+		planned.merge(sub.Planned)
+	}
+}
+
+// branchResult builds the result for a protected branch of a repository.
+func branchResult(repository, branch string) Result {
+	return Result{
+		Repository: repository + branchSuffix(branch),
+		Exists:     true,
+		Changes:    make([]Change, 0),
+		Branch:     branch,
+	}
+}
+
+// RepositoryName returns the owner/name of the repository the result covers.
+func (r Result) RepositoryName() string {
+	if r.Branch == "" {
+		return r.Repository
+	}
+	return strings.TrimSuffix(r.Repository, branchSuffix(r.Branch))
+}
+
+// branchSuffix is appended to the repository name of branch results.
+func branchSuffix(branch string) string {
+	return " (branch: " + branch + ")"
+}
+
+// failed reports whether the result or any of its branch results failed.
+func (r Result) failed() bool {
+	return r.Error != nil || slices.ContainsFunc(r.branches, func(b Result) bool { return b.Error != nil })
+}
+
+// flattenResults lists each repository result followed by its branch results.
+func flattenResults(results []Result) []Result {
+	flat := make([]Result, 0, len(results))
+	for _, result := range results {
+		branches := result.branches
+		result.branches = nil
+		flat = append(flat, result)
+		flat = append(flat, branches...)
+	}
+	return flat
+}
+
+// Summary counts repositories by outcome.
+type Summary struct {
+	Drifted int
+	Failed  int
+}
+
+// Summarize counts the repositories that failed and those that have changes,
+// counting a repository once across its branch results. A repository that
+// failed is not also counted as drifted.
+func Summarize(results []Result) Summary {
+	failed := make(map[string]bool)
+	drifted := make(map[string]bool)
+	for _, result := range results {
+		switch {
+		case result.Error != nil:
+			failed[result.RepositoryName()] = true
+		case len(result.Changes) > 0:
+			drifted[result.RepositoryName()] = true
+		}
+	}
+
+	summary := Summary{Failed: len(failed)}
+	for name := range drifted {
+		if !failed[name] {
+			summary.Drifted++
+		}
+	}
+	return summary
 }
 
 // NewSyncer creates a new syncer instance.
-func NewSyncer(client *github.Client, cfg *config.Config) *Syncer {
+func NewSyncer(client *github.Client, cfg *config.Config, opts Options) *Syncer {
 	return &Syncer{
-		client: client,
-		config: cfg,
+		client:      client,
+		config:      cfg,
+		parallelism: opts.Parallelism,
+		failFast:    opts.FailFast,
+		secrets:     newSecretRecord(opts.SecretsState),
 	}
 }
 
-// SyncAll syncs all configured repositories.
-func (s *Syncer) SyncAll(dryRun bool) ([]Result, error) {
-	results := make([]Result, 0, len(s.config.Repositories))
+// SourceResult reports how many repositories a discovery source contributed.
+type SourceResult struct {
+	Source string
+	Count  int
+}
 
+// DiscoverRepositories expands the configured sources into repositories,
+// appending them to the loaded configuration. plan and sync call it before
+// filtering and syncing; config.Load only validates the sources. Discovered
+// repositories that are already configured (explicitly or by an earlier
+// source) are not added again.
+func (s *Syncer) DiscoverRepositories(ctx context.Context) ([]SourceResult, error) {
+	seen := make(map[string]bool, len(s.config.Repositories))
 	for _, repo := range s.config.Repositories {
-		result := s.syncRepository(repo, dryRun)
-		results = append(results, result)
+		seen[strings.ToLower(repo.FullName())] = true
+	}
+
+	results := make([]SourceResult, 0, len(s.config.Sources))
+	for _, src := range s.config.Sources {
+		ownerType, owner := github.OwnerTypeOrg, src.Org
+		if src.User != "" {
+			ownerType, owner = github.OwnerTypeUser, src.User
+		}
+
+		listed, err := s.client.ListRepositories(ctx, ownerType, owner)
+		if err != nil {
+			return nil, fmt.Errorf("source %s: %w", src, err)
+		}
+
+		count := 0
+		for _, summary := range listed {
+			if !sourceMatches(src, summary) {
+				continue
+			}
+			repo := config.Repository{Owner: summary.Owner, Name: summary.Name, Profiles: src.Profiles}
+			key := strings.ToLower(repo.FullName())
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			s.config.Repositories = append(s.config.Repositories, repo)
+			count++
+		}
+
+		results = append(results, SourceResult{Source: src.String(), Count: count})
 	}
 
 	return results, nil
 }
 
+// sourceMatches reports whether a listed repository passes a source's filters.
+func sourceMatches(src config.Source, repo github.RepositorySummary) bool {
+	if src.Visibility != nil && (*src.Visibility == config.VisibilityPrivate) != repo.Private {
+		return false
+	}
+	if src.Fork != nil && *src.Fork != repo.Fork {
+		return false
+	}
+	if src.Archived != nil && *src.Archived != repo.Archived {
+		return false
+	}
+	if len(src.Languages) > 0 && !slices.ContainsFunc(src.Languages, func(lang string) bool {
+		return strings.EqualFold(lang, repo.Language)
+	}) {
+		return false
+	}
+	if len(src.Topics) > 0 && !slices.ContainsFunc(src.Topics, func(topic string) bool {
+		return slices.Contains(repo.Topics, topic)
+	}) {
+		return false
+	}
+	return true
+}
+
+// SyncAll syncs all configured repositories, processing up to the configured
+// parallelism at once. Results are returned in configuration order.
+// Repositories not matched by the include patterns are dropped, and those
+// matched by an exclude rule are reported as skipped without being touched.
+func (s *Syncer) SyncAll(ctx context.Context, dryRun bool) ([]Result, error) {
+	repos := make([]config.Repository, 0, len(s.config.Repositories))
+	for _, repo := range s.config.Repositories {
+		if s.config.Included(repo) {
+			repos = append(repos, repo)
+		}
+	}
+
+	results := s.runPool(
+		ctx,
+		len(repos),
+		func(i int) string { return repos[i].FullName() },
+		func(ctx context.Context, i int) Result {
+			if exclusion := s.config.Excluded(repos[i]); exclusion != nil {
+				return excludedResult(repos[i], exclusion)
+			}
+			return s.syncRepository(ctx, repos[i], dryRun)
+		},
+	)
+
+	return flattenResults(results), nil
+}
+
+// excludedResult builds the skip result for an excluded repository.
+func excludedResult(repo config.Repository, exclusion *config.Exclusion) Result {
+	reason := fmt.Sprintf("excluded by pattern %q", exclusion.Pattern)
+	if exclusion.Reason != "" {
+		reason = "excluded: " + exclusion.Reason
+	}
+	return skippedResult(repo.FullName(), reason)
+}
+
+// skippedResult builds the result for a repository that was not synced.
+func skippedResult(repository, reason string) Result {
+	return Result{
+		Repository: repository,
+		Changes:    make([]Change, 0),
+		Skipped:    reason,
+	}
+}
+
 // syncRepository syncs a single repository.
 func (s *Syncer) syncRepository( //nolint:cyclop,funlen,gocognit,gocyclo // Sync logic maps many settings
+	ctx context.Context,
 	repo config.Repository,
 	dryRun bool,
 ) Result {
@@ -128,7 +439,7 @@ func (s *Syncer) syncRepository( //nolint:cyclop,funlen,gocognit,gocyclo // Sync
 	}
 
 	// Get current repository info
-	current, err := s.client.GetRepository(repo.Owner, repo.Name)
+	current, err := s.client.GetRepository(ctx, repo.Owner, repo.Name)
 	if err != nil {
 		result.Error = err
 		return result
@@ -141,11 +452,16 @@ func (s *Syncer) syncRepository( //nolint:cyclop,funlen,gocognit,gocyclo // Sync
 
 	result.Exists = true
 
+	settings, origins := s.config.SettingsFor(repo)
+
+	planned := &PlannedRepository{Owner: repo.Owner, Name: repo.Name, observed: observedState{Repository: current}}
+	result.Planned = planned
+
 	patch := &gogithub.Repository{}
 	changed := applySetting(
 		&result,
 		"allow_merge_commit",
-		s.config.Settings.AllowMergeCommit,
+		settings.AllowMergeCommit,
 		current.AllowMergeCommit,
 		&patch.AllowMergeCommit,
 	)
@@ -154,7 +470,7 @@ func (s *Syncer) syncRepository( //nolint:cyclop,funlen,gocognit,gocyclo // Sync
 	changed = applySetting(
 		&result,
 		"allow_squash_merge",
-		s.config.Settings.AllowSquashMerge,
+		settings.AllowSquashMerge,
 		current.AllowSquashMerge,
 		&patch.AllowSquashMerge,
 	) ||
@@ -162,7 +478,7 @@ func (s *Syncer) syncRepository( //nolint:cyclop,funlen,gocognit,gocyclo // Sync
 	changed = applySetting(
 		&result,
 		"allow_rebase_merge",
-		s.config.Settings.AllowRebaseMerge,
+		settings.AllowRebaseMerge,
 		current.AllowRebaseMerge,
 		&patch.AllowRebaseMerge,
 	) ||
@@ -170,36 +486,36 @@ func (s *Syncer) syncRepository( //nolint:cyclop,funlen,gocognit,gocyclo // Sync
 	changed = applySetting(
 		&result,
 		"delete_branch_on_merge",
-		s.config.Settings.DeleteBranchOnMerge,
+		settings.DeleteBranchOnMerge,
 		current.DeleteBranchOnMerge,
 		&patch.DeleteBranchOnMerge,
 	) ||
 		changed
-	changed = applySetting(&result, "has_issues", s.config.Settings.HasIssues, current.HasIssues, &patch.HasIssues) ||
+	changed = applySetting(&result, "has_issues", settings.HasIssues, current.HasIssues, &patch.HasIssues) ||
 		changed
 	changed = applySetting(
 		&result,
 		"has_projects",
-		s.config.Settings.HasProjects,
+		settings.HasProjects,
 		current.HasProjects,
 		&patch.HasProjects,
 	) ||
 		changed
-	changed = applySetting(&result, "has_wiki", s.config.Settings.HasWiki, current.HasWiki, &patch.HasWiki) || changed
+	changed = applySetting(&result, "has_wiki", settings.HasWiki, current.HasWiki, &patch.HasWiki) || changed
 	changed = applySetting(
 		&result,
 		"has_discussions",
-		s.config.Settings.HasDiscussions,
+		settings.HasDiscussions,
 		current.HasDiscussions,
 		&patch.HasDiscussions,
 	) ||
 		changed
-	changed = applySetting(&result, "archived", s.config.Settings.Archived, current.Archived, &patch.Archived) ||
+	changed = applySetting(&result, "archived", settings.Archived, current.Archived, &patch.Archived) ||
 		changed
 	changed = applySetting(
 		&result,
 		"allow_update_branch",
-		s.config.Settings.AllowUpdateBranch,
+		settings.AllowUpdateBranch,
 		current.AllowUpdateBranch,
 		&patch.AllowUpdateBranch,
 	) ||
@@ -207,7 +523,7 @@ func (s *Syncer) syncRepository( //nolint:cyclop,funlen,gocognit,gocyclo // Sync
 	changed = applySetting(
 		&result,
 		"web_commit_signoff_required",
-		s.config.Settings.WebCommitSignoffRequired,
+		settings.WebCommitSignoffRequired,
 		current.WebCommitSignoffRequired,
 		&patch.WebCommitSignoffRequired,
 	) ||
@@ -215,7 +531,7 @@ func (s *Syncer) syncRepository( //nolint:cyclop,funlen,gocognit,gocyclo // Sync
 	changed = applySetting(
 		&result,
 		"allow_forking",
-		s.config.Settings.AllowForking,
+		settings.AllowForking,
 		current.AllowForking,
 		&patch.AllowForking,
 	) ||
@@ -225,7 +541,7 @@ func (s *Syncer) syncRepository( //nolint:cyclop,funlen,gocognit,gocyclo // Sync
 	changed = applySetting(
 		&result,
 		"squash_merge_commit_title",
-		s.config.Settings.SquashMergeCommitTitle,
+		settings.SquashMergeCommitTitle,
 		current.SquashMergeCommitTitle,
 		&patch.SquashMergeCommitTitle,
 	) ||
@@ -233,7 +549,7 @@ func (s *Syncer) syncRepository( //nolint:cyclop,funlen,gocognit,gocyclo // Sync
 	changed = applySetting(
 		&result,
 		"squash_merge_commit_message",
-		s.config.Settings.SquashMergeCommitMessage,
+		settings.SquashMergeCommitMessage,
 		current.SquashMergeCommitMessage,
 		&patch.SquashMergeCommitMessage,
 	) ||
@@ -241,7 +557,7 @@ func (s *Syncer) syncRepository( //nolint:cyclop,funlen,gocognit,gocyclo // Sync
 	changed = applySetting(
 		&result,
 		"merge_commit_title",
-		s.config.Settings.MergeCommitTitle,
+		settings.MergeCommitTitle,
 		current.MergeCommitTitle,
 		&patch.MergeCommitTitle,
 	) ||
@@ -249,7 +565,7 @@ func (s *Syncer) syncRepository( //nolint:cyclop,funlen,gocognit,gocyclo // Sync
 	changed = applySetting(
 		&result,
 		"merge_commit_message",
-		s.config.Settings.MergeCommitMessage,
+		settings.MergeCommitMessage,
 		current.MergeCommitMessage,
 		&patch.MergeCommitMessage,
 	) ||
@@ -259,24 +575,22 @@ func (s *Syncer) syncRepository( //nolint:cyclop,funlen,gocognit,gocyclo // Sync
 	changed = applySetting(
 		&result,
 		"description",
-		s.config.Settings.Description,
+		settings.Description,
 		current.Description,
 		&patch.Description,
 	) ||
 		changed
-	changed = applySetting(&result, "homepage", s.config.Settings.Homepage, current.Homepage, &patch.Homepage) ||
+	changed = applySetting(&result, "homepage", settings.Homepage, current.Homepage, &patch.Homepage) ||
 		changed
 
 	// Track topics (special case: slice)
-	if len(s.config.Settings.Topics) > 0 {
-		changed = applyDesiredStringSlice(&result, "topics", s.config.Settings.Topics, &patch.Topics) || changed
-	}
+	changed = applySettingSet(&result, "topics", settings.Topics, current.Topics, &patch.Topics) || changed
 
 	// Track default branch
 	changed = applySetting(
 		&result,
 		"default_branch",
-		s.config.Settings.DefaultBranch,
+		settings.DefaultBranch,
 		current.DefaultBranch,
 		&patch.DefaultBranch,
 	) ||
@@ -286,15 +600,15 @@ func (s *Syncer) syncRepository( //nolint:cyclop,funlen,gocognit,gocyclo // Sync
 	changed = applySetting(
 		&result,
 		"allow_auto_merge",
-		s.config.Settings.AllowAutoMerge,
+		settings.AllowAutoMerge,
 		current.AllowAutoMerge,
 		&patch.AllowAutoMerge,
 	) ||
 		changed
 
 	// Track visibility (special case: maps string to bool)
-	if s.config.Settings.Visibility != nil {
-		desiredPrivate := *s.config.Settings.Visibility == "private"
+	if settings.Visibility != nil {
+		desiredPrivate := *settings.Visibility == "private"
 		patch.Private = &desiredPrivate
 		if current.Private != desiredPrivate {
 			visibilityMap := map[bool]string{true: "private", false: "public"}
@@ -307,16 +621,20 @@ func (s *Syncer) syncRepository( //nolint:cyclop,funlen,gocognit,gocyclo // Sync
 		}
 	}
 
+	if changed {
+		planned.Settings = patch
+	}
+
 	if !dryRun && changed {
-		if updateErr := s.client.UpdateRepositorySettings(repo.Owner, repo.Name, patch); updateErr != nil {
+		if updateErr := s.client.UpdateRepositorySettings(ctx, repo.Owner, repo.Name, patch); updateErr != nil {
 			result.Error = fmt.Errorf("failed to update settings: %w", updateErr)
 			return result
 		}
 	}
 
 	// Handle GitHub Pages separately (requires different API)
-	if s.config.Settings.GitHubPages != nil && s.config.Settings.GitHubPages.Enabled != nil {
-		desiredPagesEnabled := *s.config.Settings.GitHubPages.Enabled
+	if settings.GitHubPages != nil && settings.GitHubPages.Enabled != nil {
+		desiredPagesEnabled := *settings.GitHubPages.Enabled
 		if current.GitHubPagesEnabled != desiredPagesEnabled {
 			// GitHub Pages requires a separate API call to enable/disable
 			// For now, we track the change but don't apply it automatically
@@ -333,42 +651,198 @@ func (s *Syncer) syncRepository( //nolint:cyclop,funlen,gocognit,gocyclo // Sync
 		}
 	}
 
-	// Sync branch protection if configured
-	if s.config.Settings.BranchProtection != nil {
-		bpResult := s.syncBranchProtection(repo, dryRun)
-		result.Changes = append(result.Changes, bpResult.Changes...)
-		if bpResult.Error != nil {
-			result.Error = bpResult.Error
+	annotateSources(result.Changes, "", origins)
+
+	// Sync each branch protection rule; every protected branch is reported as
+	// its own result.
+	for _, bp := range settings.BranchProtection {
+		if bp.Pattern == "" {
+			continue
+		}
+		branches, branchErr := s.protectedBranches(ctx, repo, bp.Pattern)
+		if branchErr != nil {
+			result.Error = errors.Join(result.Error, branchErr)
+			continue
+		}
+		for _, branch := range branches {
+			bpResult := s.syncBranchProtection(ctx, repo, bp, branch, origins, dryRun)
+			if bpResult.Planned != nil {
+				planned.BranchProtections = append(planned.BranchProtections, PlannedBranchProtection{
+					Branch:     branch,
+					Protection: bpResult.Planned.BranchProtections[0].Protection,
+					Changes:    bpResult.Changes,
+				})
+				planned.observed.BranchProtections = append(
+					planned.observed.BranchProtections,
+					bpResult.Planned.observed.BranchProtections...,
+				)
+				// The branch is planned as part of its repository.
+				bpResult.Planned = nil
+			}
+			result.branches = append(result.branches, bpResult)
 		}
 	}
 
+	// Sync the resources that have their own APIs. Each runs only when
+	// configured; deploy keys and environments also run when undeclared ones
+	// are reported or deleted.
+	resources := []struct {
+		enabled bool
+		sync    func() Result
+	}{
+		{len(settings.Rulesets) > 0, func() Result {
+			return s.syncRulesets(ctx, repo, settings, origins, dryRun)
+		}},
+		{len(settings.Labels) > 0, func() Result {
+			return s.syncLabels(ctx, repo, settings, origins, dryRun)
+		}},
+		{len(settings.Webhooks) > 0, func() Result {
+			return s.syncWebhooks(ctx, repo, settings, origins, dryRun)
+		}},
+		{settings.Access != nil, func() Result {
+			return s.syncAccess(ctx, repo, settings, origins, dryRun)
+		}},
+		{len(settings.Variables) > 0, func() Result {
+			return s.syncVariables(ctx, repo, settings, origins, dryRun)
+		}},
+		{len(settings.Secrets) > 0, func() Result {
+			return s.syncSecrets(ctx, repo, settings, origins, dryRun)
+		}},
+		{len(settings.Environments) > 0 || settings.UnmanagedEnvironmentsOrDefault() != config.UnmanagedKeep, func() Result {
+			return s.syncEnvironments(ctx, repo, settings, origins, dryRun)
+		}},
+		{len(settings.DeployKeys) > 0 || settings.UnmanagedDeployKeysOrDefault() != config.UnmanagedKeep, func() Result {
+			return s.syncDeployKeys(ctx, repo, settings, origins, dryRun)
+		}},
+		{settings.Security != nil, func() Result {
+			return s.syncSecurity(ctx, repo, current, settings, origins, dryRun)
+		}},
+		{settings.Actions != nil, func() Result {
+			return s.syncActions(ctx, repo, settings, origins, dryRun)
+		}},
+		{len(settings.Files) > 0, func() Result {
+			return s.syncFiles(ctx, repo, current, settings, origins, dryRun)
+		}},
+	}
+	for _, resource := range resources {
+		if resource.enabled {
+			mergeSubResult(&result, planned, resource.sync())
+		}
+	}
+
+	fingerprint, err := planned.observed.fingerprint()
+	if err != nil {
+		result.Error = err
+		return result
+	}
+	planned.Fingerprint = fingerprint
+
+	// A repository with a failed branch cannot be planned consistently.
+	if result.failed() {
+		result.Planned = nil
+	}
+
 	return result
 }
 
-// syncBranchProtection syncs branch protection settings.
+// protectedBranches resolves a branch protection pattern to branch names.
+// Patterns containing glob characters are matched against the branches that
+// exist now, so a branch created later is only protected by the next sync;
+// any other pattern is a branch name.
+func (s *Syncer) protectedBranches(ctx context.Context, repo config.Repository, pattern string) ([]string, error) {
+	if !strings.ContainsAny(pattern, "*?[") {
+		return []string{pattern}, nil
+	}
+	re, err := branchPatternRegexp(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	branches, err := s.client.ListBranches(ctx, repo.Owner, repo.Name)
+	if err != nil {
+		return nil, err
+	}
+	var matched []string
+	for _, branch := range branches {
+		if re.MatchString(branch) {
+			matched = append(matched, branch)
+		}
+	}
+	slices.Sort(matched)
+	return matched, nil
+}
+
+// branchPatternRegexp compiles a branch protection pattern with GitHub's
+// fnmatch rules: "*" and "?" do not match "/", while "**/" matches any
+// number of directories, so release/**/* also matches release/1.x/hotfix.
+func branchPatternRegexp(pattern string) (*regexp.Regexp, error) {
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += len("**/") - 1
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("branch protection pattern %q: unterminated [", pattern)
+			}
+			class := pattern[i+1 : i+1+end]
+			if negated, ok := strings.CutPrefix(class, "!"); ok {
+				class = "^" + negated
+			}
+			b.WriteString("[" + class + "]")
+			i += end + 1
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+
+	re, err := regexp.Compile(b.String())
+	if err != nil {
+		return nil, fmt.Errorf("branch protection pattern %q: %w", pattern, err)
+	}
+	return re, nil
+}
+
+// syncBranchProtection syncs the protection of a single branch matched by a
+// branch protection rule.
 func (s *Syncer) syncBranchProtection( //nolint:funlen,gocognit // Protection settings are numerous
+	ctx context.Context,
 	repo config.Repository,
+	bp config.BranchProtection,
+	branch string,
+	origins config.Origins,
 	dryRun bool,
 ) Result {
-	bp := s.config.Settings.BranchProtection
-
-	result := Result{
-		Repository: fmt.Sprintf("%s (branch: %s)", repo.FullName(), bp.Pattern),
-		Changes:    make([]Change, 0),
-	}
+	prefix := "branch_protection." + bp.Pattern + "."
 
-	pattern := bp.Pattern
+	result := branchResult(repo.FullName(), branch)
 
 	// Get current protection
-	current, err := s.client.GetBranchProtection(repo.Owner, repo.Name, pattern)
+	current, err := s.client.GetBranchProtection(ctx, repo.Owner, repo.Name, branch)
 	if err != nil {
 		result.Error = err
 		return result
 	}
 
+	result.Planned = &PlannedRepository{
+		Owner:             repo.Owner,
+		Name:              repo.Name,
+		BranchProtections: []PlannedBranchProtection{{Branch: branch}},
+		observed:          observedState{BranchProtections: []*github.BranchProtectionInfo{current}},
+	}
+	planned := &result.Planned.BranchProtections[0]
+
 	desired := *current
-	desired.Pattern = pattern
-	desired.Enabled = bp.Enabled
+	desired.Pattern = branch
+	desired.Enabled = bp.IsEnabled()
 
 	changed := false
 
@@ -385,9 +859,13 @@ func (s *Syncer) syncBranchProtection( //nolint:funlen,gocognit // Protection se
 	}
 
 	// If branch protection is being disabled, the only action is removal.
-	if !bp.Enabled {
+	if !bp.IsEnabled() {
+		annotateSources(result.Changes, prefix, origins)
+		if changed {
+			planned.Protection = &desired
+		}
 		if !dryRun && changed {
-			if updateErr := s.client.UpdateBranchProtection(repo.Owner, repo.Name, &desired); updateErr != nil {
+			if updateErr := s.client.UpdateBranchProtection(ctx, repo.Owner, repo.Name, &desired); updateErr != nil {
 				result.Error = fmt.Errorf("failed to update branch protection: %w", updateErr)
 			}
 		}
@@ -487,9 +965,15 @@ func (s *Syncer) syncBranchProtection( //nolint:funlen,gocognit // Protection se
 		changed
 	changed = applyDesiredSetting(&result, "allow_deletions", bp.AllowDeletions, &desired.AllowDeletions) || changed
 
+	annotateSources(result.Changes, prefix, origins)
+
+	if changed {
+		planned.Protection = &desired
+	}
+
 	// Apply changes if not dry-run
 	if !dryRun && changed {
-		if updateErr := s.client.UpdateBranchProtection(repo.Owner, repo.Name, &desired); updateErr != nil {
+		if updateErr := s.client.UpdateBranchProtection(ctx, repo.Owner, repo.Name, &desired); updateErr != nil {
 			result.Error = fmt.Errorf("failed to update branch protection: %w", updateErr)
 			return result
 		}
@@ -497,3 +981,21 @@ func (s *Syncer) syncBranchProtection( //nolint:funlen,gocognit // Protection se
 
 	return result
 }
+
+// sourceAliases maps change fields that do not correspond 1:1 to a config key
+// onto the config key that drives them, relative to the same prefix.
+var sourceAliases = map[string]string{ //nolint:gochecknoglobals // Static lookup table
+	"github_pages":      "github_pages.enabled",
+	"branch_protection": "enabled",
+}
+
+// annotateSources records the config layer each change's desired value came from.
+func annotateSources(changes []Change, prefix string, origins config.Origins) {
+	for i := range changes {
+		key := changes[i].Field
+		if alias, ok := sourceAliases[key]; ok {
+			key = alias
+		}
+		changes[i].Source = origins.Of(prefix + key)
+	}
+}
@@ -1,8 +1,13 @@
 package sync //nolint:testpackage // Tests internal implementation details
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"path/filepath"
 	"reflect"
+	"strings"
+	gosync "sync"
 	"testing"
 
 	gogithub "github.com/google/go-github/v82/github"
@@ -16,6 +21,7 @@ func stringPtr(v string) *string { return &v }
 func intPtr(v int) *int          { return &v }
 
 type fakeGitHubClient struct {
+	mu                gosync.Mutex
 	getRepoCalls      int
 	updateRepoCalls   int
 	getBranchCalls    int
@@ -31,16 +37,57 @@ type fakeGitHubClient struct {
 	getBranchResp     *github.BranchProtectionInfo
 	getBranchErr      error
 	updateBranchErr   error
+	listRepos         map[string][]github.RepositorySummary
+	getRepoErrs       map[string]error
+	branchResps       map[string]*github.BranchProtectionInfo
+	branches          []string
+	rulesets          []github.RulesetInfo
+	rulesetCalls      []string
+	labels            []github.LabelInfo
+	labelCalls        []string
+	teams             []github.AccessGrant
+	collaborators     []github.AccessGrant
+	accessCalls       []string
+	webhooks          []github.WebhookInfo
+	webhookCalls      []string
+	variables         []github.VariableInfo
+	variableCalls     []string
+	secrets           []github.SecretInfo
+	secretCalls       []string
+	environments      []github.EnvironmentInfo
+	envVariables      map[string][]github.VariableInfo
+	environmentCalls  []string
+	deployKeys        []github.DeployKeyInfo
+	deployKeyCalls    []string
+	securityFeatures  map[string]bool
+	securityCalls     []string
+	actions           *github.ActionsPermissions
+	actionsCalls      []github.ActionsPermissions
+	files             map[string]string
+	branchFiles       map[string]map[string]string
+	fileCalls         []string
+	pullRequests      []github.PullRequestInfo
 }
 
-func (f *fakeGitHubClient) GetRepository(owner, name string) (*github.RepositoryInfo, error) {
+func (f *fakeGitHubClient) ListRepositories(_ context.Context, ownerType, owner string) ([]github.RepositorySummary, error) {
+	return f.listRepos[ownerType+":"+owner], nil
+}
+
+func (f *fakeGitHubClient) GetRepository(_ context.Context, owner, name string) (*github.RepositoryInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
 	f.getRepoCalls++
 	f.lastRepoOwner = owner
 	f.lastRepoName = name
+	if err, ok := f.getRepoErrs[owner+"/"+name]; ok {
+		return nil, err
+	}
 	return f.getRepoResp, f.getRepoErr
 }
 
-func (f *fakeGitHubClient) UpdateRepositorySettings(owner, name string, patch *gogithub.Repository) error {
+func (f *fakeGitHubClient) UpdateRepositorySettings(_ context.Context, owner, name string, patch *gogithub.Repository) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
 	f.updateRepoCalls++
 	f.lastRepoOwner = owner
 	f.lastRepoName = name
@@ -48,15 +95,30 @@ func (f *fakeGitHubClient) UpdateRepositorySettings(owner, name string, patch *g
 	return f.updateRepoErr
 }
 
-func (f *fakeGitHubClient) GetBranchProtection(owner, name, pattern string) (*github.BranchProtectionInfo, error) {
+func (f *fakeGitHubClient) GetBranchProtection(_ context.Context, owner, name, pattern string) (*github.BranchProtectionInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
 	f.getBranchCalls++
 	f.lastRepoOwner = owner
 	f.lastRepoName = name
 	f.lastBPPattern = pattern
+	if resp, ok := f.branchResps[pattern]; ok {
+		return resp, nil
+	}
 	return f.getBranchResp, f.getBranchErr
 }
 
-func (f *fakeGitHubClient) UpdateBranchProtection(owner, name string, protection *github.BranchProtectionInfo) error {
+func (f *fakeGitHubClient) ListBranches(_ context.Context, _, _ string) ([]string, error) {
+	return f.branches, nil
+}
+
+func (f *fakeGitHubClient) UpdateBranchProtection(
+	_ context.Context,
+	owner, name string,
+	protection *github.BranchProtectionInfo,
+) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
 	f.updateBranchCalls++
 	f.lastRepoOwner = owner
 	f.lastRepoName = name
@@ -64,6 +126,368 @@ func (f *fakeGitHubClient) UpdateBranchProtection(owner, name string, protection
 	return f.updateBranchErr
 }
 
+func (f *fakeGitHubClient) ListRulesets(_ context.Context, _, _ string) ([]github.RulesetInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.rulesets, nil
+}
+
+func (f *fakeGitHubClient) CreateRuleset(_ context.Context, _, _ string, ruleset *github.RulesetInfo) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.rulesetCalls = append(f.rulesetCalls, "create "+ruleset.Name)
+	return nil
+}
+
+func (f *fakeGitHubClient) UpdateRuleset(_ context.Context, _, _ string, id int64, ruleset *github.RulesetInfo) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.rulesetCalls = append(f.rulesetCalls, fmt.Sprintf("update %d %s", id, ruleset.Name))
+	return nil
+}
+
+func (f *fakeGitHubClient) DeleteRuleset(_ context.Context, _, _ string, id int64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.rulesetCalls = append(f.rulesetCalls, fmt.Sprintf("delete %d", id))
+	return nil
+}
+
+func (f *fakeGitHubClient) ListLabels(_ context.Context, _, _ string) ([]github.LabelInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.labels, nil
+}
+
+func (f *fakeGitHubClient) CreateLabel(_ context.Context, _, _ string, label *github.LabelInfo) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.labelCalls = append(f.labelCalls, "create "+label.Name)
+	return nil
+}
+
+func (f *fakeGitHubClient) UpdateLabel(_ context.Context, _, _, current string, label *github.LabelInfo) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.labelCalls = append(f.labelCalls, fmt.Sprintf("update %s %s", current, label.Name))
+	return nil
+}
+
+func (f *fakeGitHubClient) DeleteLabel(_ context.Context, _, _, label string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.labelCalls = append(f.labelCalls, "delete "+label)
+	return nil
+}
+
+func (f *fakeGitHubClient) ListWebhooks(_ context.Context, _, _ string) ([]github.WebhookInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.webhooks, nil
+}
+
+func (f *fakeGitHubClient) CreateWebhook(_ context.Context, _, _ string, webhook *github.WebhookInfo) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.webhookCalls = append(f.webhookCalls, fmt.Sprintf("create %s secret=%q", webhook.URL, webhook.Secret))
+	return nil
+}
+
+func (f *fakeGitHubClient) UpdateWebhook(_ context.Context, _, _ string, id int64, webhook *github.WebhookInfo) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.webhookCalls = append(f.webhookCalls, fmt.Sprintf("update %d secret=%q", id, webhook.Secret))
+	return nil
+}
+
+func (f *fakeGitHubClient) DeleteWebhook(_ context.Context, _, _ string, id int64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.webhookCalls = append(f.webhookCalls, fmt.Sprintf("delete %d", id))
+	return nil
+}
+
+func (f *fakeGitHubClient) ListTeamAccess(_ context.Context, _, _ string) ([]github.AccessGrant, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.teams, nil
+}
+
+func (f *fakeGitHubClient) SetTeamAccess(_ context.Context, _, _, team, permission string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.accessCalls = append(f.accessCalls, fmt.Sprintf("set team %s %s", team, permission))
+	return nil
+}
+
+func (f *fakeGitHubClient) RemoveTeamAccess(_ context.Context, _, _, team string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.accessCalls = append(f.accessCalls, "remove team "+team)
+	return nil
+}
+
+func (f *fakeGitHubClient) ListCollaborators(_ context.Context, _, _ string) ([]github.AccessGrant, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.collaborators, nil
+}
+
+func (f *fakeGitHubClient) SetCollaboratorAccess(
+	_ context.Context,
+	_, _ string,
+	collaborator *github.AccessGrant,
+) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.accessCalls = append(f.accessCalls,
+		fmt.Sprintf("set collaborator %s %s", collaborator.Name, collaborator.Permission))
+	return nil
+}
+
+func (f *fakeGitHubClient) RemoveCollaborator(_ context.Context, _, _ string, collaborator *github.AccessGrant) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.accessCalls = append(f.accessCalls, "remove collaborator "+collaborator.Name)
+	return nil
+}
+
+func (f *fakeGitHubClient) ListVariables(_ context.Context, _, _ string) ([]github.VariableInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.variables, nil
+}
+
+func (f *fakeGitHubClient) CreateVariable(_ context.Context, _, _ string, variable *github.VariableInfo) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.variableCalls = append(f.variableCalls, fmt.Sprintf("create %s=%s", variable.Name, variable.Value))
+	return nil
+}
+
+func (f *fakeGitHubClient) UpdateVariable(_ context.Context, _, _ string, variable *github.VariableInfo) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.variableCalls = append(f.variableCalls, fmt.Sprintf("update %s=%s", variable.Name, variable.Value))
+	return nil
+}
+
+func (f *fakeGitHubClient) ListSecrets(_ context.Context, _, _ string) ([]github.SecretInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.secrets, nil
+}
+
+func (f *fakeGitHubClient) SetSecret(_ context.Context, _, _ string, secret, value string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.secretCalls = append(f.secretCalls, fmt.Sprintf("set %s=%s", secret, value))
+	return nil
+}
+
+func (f *fakeGitHubClient) ListEnvironments(_ context.Context, _, _ string) ([]github.EnvironmentInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.environments, nil
+}
+
+func (f *fakeGitHubClient) SetEnvironment(_ context.Context, _, _ string, env *github.EnvironmentInfo) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.environmentCalls = append(f.environmentCalls, fmt.Sprintf("set %+v", *env))
+	return nil
+}
+
+func (f *fakeGitHubClient) DeleteEnvironment(_ context.Context, _, _ string, env string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.environmentCalls = append(f.environmentCalls, "delete "+env)
+	return nil
+}
+
+func (f *fakeGitHubClient) ListEnvironmentVariables(
+	_ context.Context, _, _ string, env string,
+) ([]github.VariableInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.envVariables[env], nil
+}
+
+func (f *fakeGitHubClient) CreateEnvironmentVariable(
+	_ context.Context, _, _ string, env string, variable *github.VariableInfo,
+) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.environmentCalls = append(f.environmentCalls, fmt.Sprintf("create %s %s=%s", env, variable.Name, variable.Value))
+	return nil
+}
+
+func (f *fakeGitHubClient) UpdateEnvironmentVariable(
+	_ context.Context, _, _ string, env string, variable *github.VariableInfo,
+) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.environmentCalls = append(f.environmentCalls, fmt.Sprintf("update %s %s=%s", env, variable.Name, variable.Value))
+	return nil
+}
+
+func (f *fakeGitHubClient) ListDeployKeys(_ context.Context, _, _ string) ([]github.DeployKeyInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.deployKeys, nil
+}
+
+func (f *fakeGitHubClient) CreateDeployKey(_ context.Context, _, _ string, key *github.DeployKeyInfo) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.deployKeyCalls = append(f.deployKeyCalls, fmt.Sprintf("create %s read_only=%t", key.Title, key.ReadOnly))
+	return nil
+}
+
+func (f *fakeGitHubClient) DeleteDeployKey(_ context.Context, _, _ string, id int64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.deployKeyCalls = append(f.deployKeyCalls, fmt.Sprintf("delete %d", id))
+	return nil
+}
+
+func (f *fakeGitHubClient) GetSecurityFeature(_ context.Context, _, _ string, feature string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.securityFeatures[feature], nil
+}
+
+func (f *fakeGitHubClient) SetSecurityFeature(_ context.Context, _, _ string, feature string, enabled bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.securityCalls = append(f.securityCalls, fmt.Sprintf("%s=%t", feature, enabled))
+	return nil
+}
+
+func (f *fakeGitHubClient) GetActionsPermissions(_ context.Context, _, _ string) (*github.ActionsPermissions, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.actions == nil {
+		return &github.ActionsPermissions{Enabled: true, AllowedActions: "all", DefaultWorkflowPermissions: "read"}, nil
+	}
+	return f.actions, nil
+}
+
+func (f *fakeGitHubClient) SetActionsPermissions(
+	_ context.Context,
+	_, _ string,
+	permissions *github.ActionsPermissions,
+) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.actionsCalls = append(f.actionsCalls, *permissions)
+	return nil
+}
+
+func (f *fakeGitHubClient) GetFile(_ context.Context, _, _, path, ref string) (*github.FileInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	content, ok := f.branchFiles[ref][path]
+	if !ok {
+		content, ok = f.files[path]
+	}
+	if !ok {
+		return &github.FileInfo{Path: path}, nil
+	}
+	return &github.FileInfo{Path: path, Exists: true, SHA: blobSHA(content), Content: content}, nil
+}
+
+func (f *fakeGitHubClient) CommitFiles(
+	_ context.Context,
+	_, _, base, branch, _ string,
+	files []github.FileContent,
+) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.branchFiles == nil {
+		f.branchFiles = make(map[string]map[string]string)
+	}
+	// Branches other than base are reset to base before the commit.
+	committed := make(map[string]string, len(files))
+	for _, file := range files {
+		f.fileCalls = append(f.fileCalls, fmt.Sprintf("commit %s to %s from %s", file.Path, branch, base))
+		committed[file.Path] = file.Content
+	}
+	if branch != base {
+		f.branchFiles[branch] = committed
+	}
+	return nil
+}
+
+func (f *fakeGitHubClient) FindPullRequest(
+	_ context.Context,
+	_, _, headPrefix, base string,
+) (*github.PullRequestInfo, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i := range f.pullRequests {
+		if strings.HasPrefix(f.pullRequests[i].Head, headPrefix) && f.pullRequests[i].Base == base {
+			return &f.pullRequests[i], true, nil
+		}
+	}
+	return nil, false, nil
+}
+
+func (f *fakeGitHubClient) CreatePullRequest(
+	_ context.Context,
+	_, _ string,
+	pr *github.PullRequestInfo,
+) (*github.PullRequestInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	created := *pr
+	created.Number = len(f.pullRequests) + 1
+	f.pullRequests = append(f.pullRequests, created)
+	return &created, nil
+}
+
+func (f *fakeGitHubClient) UpdatePullRequest(_ context.Context, _, _ string, pr *github.PullRequestInfo) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i := range f.pullRequests {
+		if f.pullRequests[i].Number == pr.Number {
+			f.pullRequests[i].Title = pr.Title
+			f.pullRequests[i].Body = pr.Body
+		}
+	}
+	return nil
+}
+
+func (f *fakeGitHubClient) EnableAutoMerge(
+	_ context.Context,
+	_, _ string,
+	pr *github.PullRequestInfo,
+	method string,
+) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i := range f.pullRequests {
+		if f.pullRequests[i].Number == pr.Number {
+			f.pullRequests[i].AutoMerge = true
+		}
+	}
+	f.fileCalls = append(f.fileCalls, fmt.Sprintf("auto-merge #%d with %s", pr.Number, method))
+	return nil
+}
+
+// repoFixture returns a fake on which repository o/r exists and a config
+// that syncs it with settings. Tests add their own fake state on top.
+func repoFixture(settings config.Settings) (*fakeGitHubClient, *config.Config) {
+	fake := &fakeGitHubClient{
+		getRepoResp: &github.RepositoryInfo{Owner: "o", Name: "r", Exists: true},
+	}
+	cfg := &config.Config{
+		Repositories: []config.Repository{{Owner: "o", Name: "r"}},
+		Settings:     settings,
+	}
+	return fake, cfg
+}
+
 func changeByField(t *testing.T, changes []Change) map[string]Change {
 	t.Helper()
 	got := make(map[string]Change, len(changes))
@@ -251,7 +675,7 @@ func TestSyncRepository_SkipsWhenRepoDoesNotExist(t *testing.T) {
 	fake := &fakeGitHubClient{getRepoResp: &github.RepositoryInfo{Owner: "o", Name: "r", Exists: false}}
 	s := &Syncer{client: fake, config: &config.Config{Repositories: []config.Repository{repo}}}
 
-	result := s.syncRepository(repo, false)
+	result := s.syncRepository(t.Context(), repo, false)
 	if result.Error != nil {
 		t.Fatalf("Error = %v; want nil", result.Error)
 	}
@@ -284,7 +708,7 @@ func TestSyncRepository_DryRunDoesNotUpdate(t *testing.T) {
 	}
 
 	s := &Syncer{client: fake, config: cfg}
-	result := s.syncRepository(repo, true)
+	result := s.syncRepository(t.Context(), repo, true)
 	if result.Error != nil {
 		t.Fatalf("Error = %v; want nil", result.Error)
 	}
@@ -327,7 +751,7 @@ func TestSyncRepository_AppliesUpdateWhenChanged(t *testing.T) {
 	}
 
 	s := &Syncer{client: fake, config: cfg}
-	result := s.syncRepository(repo, false)
+	result := s.syncRepository(t.Context(), repo, false)
 	if result.Error != nil {
 		t.Fatalf("Error = %v; want nil", result.Error)
 	}
@@ -348,6 +772,39 @@ func TestSyncRepository_AppliesUpdateWhenChanged(t *testing.T) {
 	}
 }
 
+func TestSyncRepository_AppliesRepositoryOverride(t *testing.T) {
+	repo := config.Repository{
+		Owner:    "o",
+		Name:     "docs",
+		Settings: &config.Settings{HasWiki: boolPtr(true)},
+	}
+
+	fake := &fakeGitHubClient{
+		getRepoResp: &github.RepositoryInfo{Owner: "o", Name: "docs", Exists: true},
+	}
+	cfg := &config.Config{
+		Repositories: []config.Repository{repo},
+		Settings: config.Settings{
+			HasWiki:   boolPtr(false),
+			HasIssues: boolPtr(true),
+		},
+	}
+
+	s := &Syncer{client: fake, config: cfg}
+	result := s.syncRepository(t.Context(), repo, true)
+	if result.Error != nil {
+		t.Fatalf("Error = %v; want nil", result.Error)
+	}
+
+	got := changeByField(t, result.Changes)
+	if c, ok := got["has_wiki"]; !ok || c.Desired != true || c.Source != config.LayerRepository {
+		t.Fatalf("has_wiki change = %+v; want -> true from repository", c)
+	}
+	if c, ok := got["has_issues"]; !ok || c.Desired != true || c.Source != config.LayerGlobal {
+		t.Fatalf("has_issues change = %+v; want -> true from global", c)
+	}
+}
+
 func TestSyncRepository_PropagatesUpdateError(t *testing.T) {
 	repo := config.Repository{Owner: "o", Name: "r"}
 	boom := errors.New("boom")
@@ -362,7 +819,7 @@ func TestSyncRepository_PropagatesUpdateError(t *testing.T) {
 	}
 
 	s := &Syncer{client: fake, config: cfg}
-	result := s.syncRepository(repo, false)
+	result := s.syncRepository(t.Context(), repo, false)
 	if result.Error == nil {
 		t.Fatal("Error = nil; want error")
 	}
@@ -378,12 +835,12 @@ func TestSyncBranchProtection_DisableRemovesProtection(t *testing.T) {
 	cfg := &config.Config{
 		Repositories: []config.Repository{repo},
 		Settings: config.Settings{
-			BranchProtection: &config.BranchProtection{Enabled: false, Pattern: "main"},
+			BranchProtection: config.BranchProtectionRules{{Enabled: boolPtr(false), Pattern: "main"}},
 		},
 	}
 
 	s := &Syncer{client: fake, config: cfg}
-	result := s.syncBranchProtection(repo, false)
+	result := s.syncBranchProtection(t.Context(), repo, cfg.Settings.BranchProtection[0], "main", nil, false)
 	if result.Error != nil {
 		t.Fatalf("Error = %v; want nil", result.Error)
 	}
@@ -407,16 +864,16 @@ func TestSyncBranchProtection_ErrorsWhenStatusChecksRequiredButNoneConfiguredOrE
 	cfg := &config.Config{
 		Repositories: []config.Repository{repo},
 		Settings: config.Settings{
-			BranchProtection: &config.BranchProtection{
-				Enabled:             true,
+			BranchProtection: config.BranchProtectionRules{{
+				Enabled:             boolPtr(true),
 				Pattern:             "main",
 				RequireStatusChecks: boolPtr(true),
-			},
+			}},
 		},
 	}
 
 	s := &Syncer{client: fake, config: cfg}
-	result := s.syncBranchProtection(repo, false)
+	result := s.syncBranchProtection(t.Context(), repo, cfg.Settings.BranchProtection[0], "main", nil, false)
 	if result.Error == nil {
 		t.Fatal("Error = nil; want error")
 	}
@@ -440,17 +897,17 @@ func TestSyncBranchProtection_ConfiguredContextsOverrideAndClearChecks(t *testin
 	cfg := &config.Config{
 		Repositories: []config.Repository{repo},
 		Settings: config.Settings{
-			BranchProtection: &config.BranchProtection{
-				Enabled:             true,
+			BranchProtection: config.BranchProtectionRules{{
+				Enabled:             boolPtr(true),
 				Pattern:             "main",
 				RequireStatusChecks: boolPtr(true),
 				StatusCheckContexts: []string{"ci/test"},
-			},
+			}},
 		},
 	}
 
 	s := &Syncer{client: fake, config: cfg}
-	result := s.syncBranchProtection(repo, false)
+	result := s.syncBranchProtection(t.Context(), repo, cfg.Settings.BranchProtection[0], "main", nil, false)
 	if result.Error != nil {
 		t.Fatalf("Error = %v; want nil", result.Error)
 	}
@@ -488,17 +945,17 @@ func TestSyncBranchProtection_ConfiguredReviewsEnablePRReviews(t *testing.T) {
 	cfg := &config.Config{
 		Repositories: []config.Repository{repo},
 		Settings: config.Settings{
-			BranchProtection: &config.BranchProtection{
-				Enabled:             true,
+			BranchProtection: config.BranchProtectionRules{{
+				Enabled:             boolPtr(true),
 				Pattern:             "main",
 				RequiredReviews:     intPtr(2),
 				DismissStaleReviews: boolPtr(true),
-			},
+			}},
 		},
 	}
 
 	s := &Syncer{client: fake, config: cfg}
-	result := s.syncBranchProtection(repo, false)
+	result := s.syncBranchProtection(t.Context(), repo, cfg.Settings.BranchProtection[0], "main", nil, false)
 	if result.Error != nil {
 		t.Fatalf("Error = %v; want nil", result.Error)
 	}
@@ -532,16 +989,16 @@ func TestSyncBranchProtection_ConfiguredZeroReviewsEnablesPRReviews(t *testing.T
 	cfg := &config.Config{
 		Repositories: []config.Repository{repo},
 		Settings: config.Settings{
-			BranchProtection: &config.BranchProtection{
-				Enabled:         true,
+			BranchProtection: config.BranchProtectionRules{{
+				Enabled:         boolPtr(true),
 				Pattern:         "main",
 				RequiredReviews: intPtr(0),
-			},
+			}},
 		},
 	}
 
 	s := &Syncer{client: fake, config: cfg}
-	result := s.syncBranchProtection(repo, false)
+	result := s.syncBranchProtection(t.Context(), repo, cfg.Settings.BranchProtection[0], "main", nil, false)
 	if result.Error != nil {
 		t.Fatalf("Error = %v; want nil", result.Error)
 	}
@@ -557,3 +1014,331 @@ func TestSyncBranchProtection_ConfiguredZeroReviewsEnablesPRReviews(t *testing.T
 		t.Fatalf("pull_request_reviews_enabled change = %v; want false -> true", c)
 	}
 }
+
+func TestSyncAll_MultipleBranchProtectionRules(t *testing.T) {
+	fake := &fakeGitHubClient{
+		getRepoResp:   &github.RepositoryInfo{Exists: true},
+		getBranchResp: &github.BranchProtectionInfo{Enabled: true, PullRequestReviewsEnabled: true, RequiredReviews: 1},
+		branches:      []string{"release/2.0", "main", "dev", "release/1.0"},
+	}
+	cfg := &config.Config{
+		Repositories: []config.Repository{{Owner: "o", Name: "r"}},
+		Settings: config.Settings{
+			BranchProtection: config.BranchProtectionRules{
+				{Enabled: boolPtr(true), Pattern: "main", RequiredReviews: intPtr(2)},
+				{Enabled: boolPtr(true), Pattern: "release/*", RequiredReviews: intPtr(1)},
+			},
+		},
+	}
+
+	s := &Syncer{client: fake, config: cfg}
+	results, err := s.SyncAll(t.Context(), true)
+	if err != nil {
+		t.Fatalf("SyncAll() error = %v", err)
+	}
+
+	names := make([]string, 0, len(results))
+	for _, result := range results {
+		names = append(names, result.Repository)
+	}
+	want := []string{"o/r", "o/r (branch: main)", "o/r (branch: release/1.0)", "o/r (branch: release/2.0)"}
+	if !reflect.DeepEqual(names, want) {
+		t.Fatalf("results = %v; want %v", names, want)
+	}
+	if results[1].RepositoryName() != "o/r" || results[1].Branch != "main" {
+		t.Fatalf("results[1] = %+v; want branch main of o/r", results[1])
+	}
+	if c := changeByField(t, results[1].Changes)["required_reviews"]; c.Current != 1 || c.Desired != 2 {
+		t.Fatalf("required_reviews change = %+v; want 1 -> 2", c)
+	}
+	if len(results[2].Changes) != 0 || len(results[3].Changes) != 0 {
+		t.Fatalf("release changes = %+v, %+v; want none", results[2].Changes, results[3].Changes)
+	}
+	if fake.updateBranchCalls != 0 {
+		t.Fatalf("updateBranchCalls = %d; want 0 in dry-run", fake.updateBranchCalls)
+	}
+	if got := Summarize(results); got != (Summary{Drifted: 1}) {
+		t.Fatalf("Summarize() = %+v; want {Drifted:1}", got)
+	}
+
+	plan := NewPlan(results)
+	if len(plan.Repositories) != 1 || len(plan.Repositories[0].BranchProtections) != 3 {
+		t.Fatalf("plan = %+v; want one repository with three branches", plan.Repositories)
+	}
+}
+
+func TestBranchPatternRegexp(t *testing.T) {
+	tests := []struct {
+		pattern string
+		branch  string
+		want    bool
+	}{
+		{"release/*", "release/1.0", true},
+		{"release/*", "release/1.x/hotfix", false},
+		{"release/**/*", "release/1.0", true},
+		{"release/**/*", "release/1.x/hotfix", true},
+		{"v?.x", "v1.x", true},
+		{"v?.x", "v1/x", false},
+		{"[!m]*", "main", false},
+		{"[!m]*", "dev", true},
+		{"feat.*", "featXy", false},
+	}
+	for _, tt := range tests {
+		re, err := branchPatternRegexp(tt.pattern)
+		if err != nil {
+			t.Fatalf("branchPatternRegexp(%q) error = %v", tt.pattern, err)
+		}
+		if got := re.MatchString(tt.branch); got != tt.want {
+			t.Fatalf("%q matches %q = %v; want %v", tt.pattern, tt.branch, got, tt.want)
+		}
+	}
+
+	if _, err := branchPatternRegexp("release/[12"); err == nil {
+		t.Fatal("branchPatternRegexp() error = nil; want unterminated [")
+	}
+}
+
+func TestDiscoverRepositories_FiltersAndDeduplicates(t *testing.T) {
+	fake := &fakeGitHubClient{
+		listRepos: map[string][]github.RepositorySummary{
+			"org:acme": {
+				{Owner: "acme", Name: "svc-a", Language: "Go", Topics: []string{"service"}},
+				{Owner: "acme", Name: "svc-b", Language: "go", Topics: []string{"service"}, Archived: true},
+				{Owner: "acme", Name: "svc-c", Language: "Go", Topics: []string{"service"}, Fork: true},
+				{Owner: "acme", Name: "web", Language: "TypeScript", Topics: []string{"service"}},
+				{Owner: "acme", Name: "Existing", Language: "Go", Topics: []string{"service"}},
+			},
+			"user:me": {
+				{Owner: "me", Name: "dotfiles"},
+				{Owner: "me", Name: "secret", Private: true},
+			},
+		},
+	}
+	cfg := &config.Config{
+		Repositories: []config.Repository{{Owner: "acme", Name: "existing"}},
+		Sources: []config.Source{
+			{
+				Org:       "acme",
+				Fork:      boolPtr(false),
+				Archived:  boolPtr(false),
+				Languages: []string{"Go"},
+				Topics:    []string{"service"},
+				Profiles:  []string{"svc"},
+			},
+			{User: "me", Visibility: stringPtr("public")},
+		},
+	}
+
+	s := &Syncer{client: fake, config: cfg}
+	sources, err := s.DiscoverRepositories(t.Context())
+	if err != nil {
+		t.Fatalf("DiscoverRepositories() error = %v", err)
+	}
+
+	wantSources := []SourceResult{{Source: "org:acme", Count: 1}, {Source: "user:me", Count: 1}}
+	if !reflect.DeepEqual(sources, wantSources) {
+		t.Fatalf("sources = %+v; want %+v", sources, wantSources)
+	}
+
+	var got []string
+	for _, repo := range cfg.Repositories {
+		got = append(got, repo.FullName())
+	}
+	want := []string{"acme/existing", "acme/svc-a", "me/dotfiles"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("repositories = %v; want %v", got, want)
+	}
+	if !reflect.DeepEqual(cfg.Repositories[1].Profiles, []string{"svc"}) {
+		t.Fatalf("Profiles = %v; want [svc]", cfg.Repositories[1].Profiles)
+	}
+}
+
+func TestSyncAll_AppliesIncludeAndExclude(t *testing.T) {
+	fake := &fakeGitHubClient{getRepoResp: &github.RepositoryInfo{Exists: true}}
+	cfg := &config.Config{
+		Repositories: []config.Repository{
+			{Owner: "o", Name: "svc-a"},
+			{Owner: "o", Name: "svc-old"},
+			{Owner: "o", Name: "web"},
+		},
+		Include: []string{"svc-*"},
+		Exclude: []config.Exclusion{{Pattern: "svc-old", Reason: "retired"}},
+	}
+
+	s := &Syncer{client: fake, config: cfg}
+	results, err := s.SyncAll(t.Context(), true)
+	if err != nil {
+		t.Fatalf("SyncAll() error = %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("len(results) = %d; want 2", len(results))
+	}
+	if results[0].Repository != "o/svc-a" || results[0].Skipped != "" {
+		t.Fatalf("results[0] = %+v; want synced o/svc-a", results[0])
+	}
+	if results[1].Repository != "o/svc-old" || results[1].Skipped != "excluded: retired" {
+		t.Fatalf("results[1] = %+v; want o/svc-old skipped as retired", results[1])
+	}
+	if fake.getRepoCalls != 1 {
+		t.Fatalf("getRepoCalls = %d; want 1", fake.getRepoCalls)
+	}
+}
+
+func TestSyncAll_ParallelKeepsOrderAndContinuesAfterError(t *testing.T) {
+	fake := &fakeGitHubClient{
+		getRepoResp: &github.RepositoryInfo{Exists: true},
+		getRepoErrs: map[string]error{"o/r3": errors.New("boom")},
+	}
+	cfg := &config.Config{}
+	for _, name := range []string{"r1", "r2", "r3", "r4", "r5", "r6", "r7", "r8"} {
+		cfg.Repositories = append(cfg.Repositories, config.Repository{Owner: "o", Name: name})
+	}
+
+	s := &Syncer{client: fake, config: cfg, parallelism: 4}
+	results, err := s.SyncAll(t.Context(), true)
+	if err != nil {
+		t.Fatalf("SyncAll() error = %v", err)
+	}
+	if len(results) != len(cfg.Repositories) {
+		t.Fatalf("len(results) = %d; want %d", len(results), len(cfg.Repositories))
+	}
+	for i, result := range results {
+		if want := cfg.Repositories[i].FullName(); result.Repository != want {
+			t.Fatalf("results[%d].Repository = %q; want %q", i, result.Repository, want)
+		}
+		if wantErr := result.Repository == "o/r3"; (result.Error != nil) != wantErr {
+			t.Fatalf("results[%d].Error = %v; want error: %v", i, result.Error, wantErr)
+		}
+	}
+	if fake.getRepoCalls != len(cfg.Repositories) {
+		t.Fatalf("getRepoCalls = %d; want %d", fake.getRepoCalls, len(cfg.Repositories))
+	}
+}
+
+func TestSyncAll_FailFastSkipsRemaining(t *testing.T) {
+	fake := &fakeGitHubClient{
+		getRepoResp: &github.RepositoryInfo{Exists: true},
+		getRepoErrs: map[string]error{"o/r2": errors.New("boom")},
+	}
+	cfg := &config.Config{Repositories: []config.Repository{
+		{Owner: "o", Name: "r1"},
+		{Owner: "o", Name: "r2"},
+		{Owner: "o", Name: "r3"},
+	}}
+
+	s := &Syncer{client: fake, config: cfg, parallelism: 1, failFast: true}
+	results, err := s.SyncAll(t.Context(), true)
+	if err != nil {
+		t.Fatalf("SyncAll() error = %v", err)
+	}
+	if results[0].Error != nil || results[0].Skipped != "" {
+		t.Fatalf("results[0] = %+v; want synced", results[0])
+	}
+	if results[1].Error == nil {
+		t.Fatalf("results[1].Error = nil; want error")
+	}
+	if results[2].Repository != "o/r3" || results[2].Skipped != failFastReason || !results[2].NotProcessed {
+		t.Fatalf("results[2] = %+v; want o/r3 skipped as not processed", results[2])
+	}
+	if fake.getRepoCalls != 2 {
+		t.Fatalf("getRepoCalls = %d; want 2", fake.getRepoCalls)
+	}
+}
+
+func TestSyncAll_CancelledContextReportsNotProcessed(t *testing.T) {
+	fake := &fakeGitHubClient{getRepoResp: &github.RepositoryInfo{Exists: true}}
+	cfg := &config.Config{Repositories: []config.Repository{
+		{Owner: "o", Name: "r1"},
+		{Owner: "o", Name: "r2"},
+	}}
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	s := &Syncer{client: fake, config: cfg, parallelism: 2}
+	results, err := s.SyncAll(ctx, true)
+	if err != nil {
+		t.Fatalf("SyncAll() error = %v", err)
+	}
+	if got, want := NotProcessed(results), []string{"o/r1", "o/r2"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("NotProcessed() = %v; want %v", got, want)
+	}
+	if results[0].Skipped != "not processed: context canceled" {
+		t.Fatalf("Skipped = %q; want %q", results[0].Skipped, "not processed: context canceled")
+	}
+	if fake.getRepoCalls != 0 {
+		t.Fatalf("getRepoCalls = %d; want 0", fake.getRepoCalls)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	results := []Result{
+		{Repository: "o/clean", Exists: true},
+		{Repository: "o/drift", Exists: true, Changes: []Change{{Field: "has_wiki"}}},
+		{Repository: "o/failed", Exists: true, Changes: []Change{{Field: "github_pages"}}, Error: errors.New("boom")},
+		{Repository: "o/skipped", Skipped: "excluded: retired"},
+		{Repository: "o/branch (branch: main)", Branch: "main", Exists: true, Changes: []Change{{Field: "enabled"}}},
+		{Repository: "o/branch (branch: dev)", Branch: "dev", Exists: true, Changes: []Change{{Field: "enabled"}}},
+	}
+
+	got := Summarize(results)
+	if got != (Summary{Drifted: 2, Failed: 1}) {
+		t.Fatalf("Summarize() = %+v; want {Drifted:2 Failed:1}", got)
+	}
+}
+
+func TestSyncAll_MatchingTopicsReportNoDrift(t *testing.T) {
+	fake, cfg := repoFixture(config.Settings{Topics: []string{"cli", "go"}})
+	fake.getRepoResp.Topics = []string{"go", "cli"}
+	s := &Syncer{client: fake, config: cfg}
+
+	results, err := s.SyncAll(t.Context(), true)
+	if err != nil {
+		t.Fatalf("SyncAll() error = %v", err)
+	}
+	if len(results[0].Changes) != 0 {
+		t.Fatalf("Changes = %+v; want none", results[0].Changes)
+	}
+	if results[0].Planned != nil && results[0].Planned.Settings != nil {
+		t.Fatalf("Planned.Settings = %+v; want nil", results[0].Planned.Settings)
+	}
+	// plan --detailed-exitcode exits 0 when nothing failed or drifted.
+	if got := Summarize(results); got != (Summary{}) {
+		t.Fatalf("Summarize() = %+v; want no drift", got)
+	}
+
+	cfg.Settings.Topics = []string{"cli", "go", "tool"}
+	results, err = s.SyncAll(t.Context(), true)
+	if err != nil {
+		t.Fatalf("SyncAll() error = %v", err)
+	}
+	if c := changeByField(t, results[0].Changes)["topics"]; !reflect.DeepEqual(c.Desired, []string{"cli", "go", "tool"}) {
+		t.Fatalf("topics change = %+v; want [cli go tool]", c)
+	}
+}
+
+func TestSyncRepository_JoinsResourceErrors(t *testing.T) {
+	repo := config.Repository{Owner: "o", Name: "r"}
+	missing := filepath.Join(t.TempDir(), "missing")
+	fake := &fakeGitHubClient{
+		getRepoResp: &github.RepositoryInfo{Owner: "o", Name: "r", Exists: true, DefaultBranch: "main"},
+	}
+	cfg := &config.Config{
+		Repositories: []config.Repository{repo},
+		Settings: config.Settings{
+			DeployKeys: []config.DeployKey{{Title: "bot", KeyFile: missing + ".pub"}},
+			Files:      map[string]config.File{"LICENSE": {Source: missing}},
+		},
+	}
+	s := &Syncer{client: fake, config: cfg}
+
+	result := s.syncRepository(t.Context(), repo, true)
+	if result.Error == nil {
+		t.Fatal("Error = nil; want deploy key and file errors")
+	}
+	for _, want := range []string{"missing.pub", "file LICENSE"} {
+		if !strings.Contains(result.Error.Error(), want) {
+			t.Fatalf("Error = %v; want it to mention %q", result.Error, want)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/mholtzscher/github-janitor/internal/config"
+	"github.com/mholtzscher/github-janitor/internal/github"
+)
+
+// VariableOperation is a planned create or update of an Actions variable.
+type VariableOperation struct {
+	Action   string              `json:"action"`
+	Variable github.VariableInfo `json:"variable"`
+}
+
+// syncVariables reconciles the configured Actions variables with those on
+// the repository, matching names ignoring case. Variables that are not
+// configured are left alone.
+func (s *Syncer) syncVariables(
+	ctx context.Context,
+	repo config.Repository,
+	settings config.Settings,
+	origins config.Origins,
+	dryRun bool,
+) Result {
+
+	result := Result{
+		Repository: repo.FullName(),
+		Changes:    make([]Change, 0),
+	}
+
+	current, err := s.client.ListVariables(ctx, repo.Owner, repo.Name)
+	if err != nil {
+		result.Error = err
+		return result
+	}
+
+	planned := &PlannedRepository{Owner: repo.Owner, Name: repo.Name}
+	result.Planned = planned
+
+	result.Changes, planned.Variables = diffVariables("variables", current, settings.Variables, origins.Of)
+
+	if len(planned.Variables) > 0 {
+		planned.observed.Variables = current
+	}
+
+	if !dryRun {
+		for _, op := range planned.Variables {
+			if applyErr := s.applyVariableOperation(ctx, repo.Owner, repo.Name, op); applyErr != nil {
+				result.Error = applyErr
+				return result
+			}
+		}
+	}
+
+	return result
+}
+
+// diffVariables compares current variables with the configured ones under
+// prefix, e.g. "variables", matching names ignoring case. source returns the
+// config layer for a change's field.
+func diffVariables(
+	prefix string,
+	current []github.VariableInfo,
+	configured map[string]string,
+	source func(field string) string,
+) ([]Change, []VariableOperation) {
+	existing := make(map[string]github.VariableInfo, len(current))
+	for _, variable := range current {
+		existing[strings.ToUpper(variable.Name)] = variable
+	}
+
+	changes := make([]Change, 0)
+	var ops []VariableOperation
+	for _, name := range slices.Sorted(maps.Keys(configured)) {
+		value := configured[name]
+		field := prefix + "." + name
+		change := Change{Field: field, Current: resourceAbsent, Desired: value, Source: source(field)}
+		op := VariableOperation{Action: ActionCreate, Variable: github.VariableInfo{Name: name, Value: value}}
+
+		if found, exists := existing[strings.ToUpper(name)]; exists {
+			if found.Value == value {
+				continue
+			}
+			change.Current = found.Value
+			op.Action = ActionUpdate
+			op.Variable.Name = found.Name
+		}
+		changes = append(changes, change)
+		ops = append(ops, op)
+	}
+	return changes, ops
+}
+
+// applyVariableOperation performs a single planned variable operation.
+func (s *Syncer) applyVariableOperation(ctx context.Context, owner, name string, op VariableOperation) error {
+	switch op.Action {
+	case ActionCreate:
+		return s.client.CreateVariable(ctx, owner, name, &op.Variable)
+	case ActionUpdate:
+		return s.client.UpdateVariable(ctx, owner, name, &op.Variable)
+	}
+	return fmt.Errorf("unknown variable action %q", op.Action)
+}
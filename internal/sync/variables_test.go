@@ -0,0 +1,45 @@
+package sync //nolint:testpackage // Tests internal implementation details
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mholtzscher/github-janitor/internal/config"
+	"github.com/mholtzscher/github-janitor/internal/github"
+)
+
+func TestSyncAll_Variables(t *testing.T) {
+	fake, cfg := repoFixture(config.Settings{
+		Variables: map[string]string{"region": "us-east-1", "STAGE": "prod", "TEAM": "platform"},
+	})
+	fake.variables = []github.VariableInfo{
+		{Name: "REGION", Value: "us-west-2"},
+		{Name: "STAGE", Value: "prod"},
+		{Name: "UNMANAGED", Value: "kept"},
+	}
+	s := &Syncer{client: fake, config: cfg}
+
+	results, err := s.SyncAll(t.Context(), false)
+	if err != nil {
+		t.Fatalf("SyncAll() error = %v", err)
+	}
+	if results[0].Error != nil {
+		t.Fatalf("Error = %v; want nil", results[0].Error)
+	}
+
+	changes := changeByField(t, results[0].Changes)
+	if c := changes["variables.region"]; c.Current != "us-west-2" || c.Desired != "us-east-1" {
+		t.Fatalf("region change = %+v; want us-west-2 → us-east-1", c)
+	}
+	if c := changes["variables.TEAM"]; c.Current != resourceAbsent || c.Desired != "platform" {
+		t.Fatalf("TEAM change = %+v; want absent → platform", c)
+	}
+	if len(changes) != 2 {
+		t.Fatalf("changes = %+v; want 2", changes)
+	}
+
+	want := []string{"create TEAM=platform", "update REGION=us-east-1"}
+	if !reflect.DeepEqual(fake.variableCalls, want) {
+		t.Fatalf("variable calls = %v; want %v", fake.variableCalls, want)
+	}
+}
@@ -0,0 +1,188 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/mholtzscher/github-janitor/internal/config"
+	"github.com/mholtzscher/github-janitor/internal/github"
+)
+
+// Values shown for a webhook secret, which is never printed.
+const (
+	secretSet   = "set"
+	secretUnset = "unset"
+)
+
+// WebhookOperation is a planned create, update or delete of a webhook.
+type WebhookOperation struct {
+	Action string `json:"action"`
+
+	// ID is the existing webhook to update or delete.
+	ID int64 `json:"id,omitempty"`
+
+	Webhook *github.WebhookInfo `json:"webhook"`
+
+	// SecretEnv names the environment variable the secret is read from when
+	// the operation is applied, so that plans never contain the secret.
+	SecretEnv string `json:"secret_env,omitempty"`
+}
+
+// syncWebhooks reconciles the configured webhooks with those on the
+// repository, matching them by URL. Webhooks that are not configured are
+// kept, reported or deleted according to unmanaged_webhooks. Secrets are only
+// compared by whether one is set, and are never included in changes.
+func (s *Syncer) syncWebhooks(
+	ctx context.Context,
+	repo config.Repository,
+	settings config.Settings,
+	origins config.Origins,
+	dryRun bool,
+) Result {
+	source := origins.Of("webhooks")
+
+	result := Result{
+		Repository: repo.FullName(),
+		Changes:    make([]Change, 0),
+	}
+
+	current, err := s.client.ListWebhooks(ctx, repo.Owner, repo.Name)
+	if err != nil {
+		result.Error = err
+		return result
+	}
+	existing := make(map[string]github.WebhookInfo, len(current))
+	for _, hook := range current {
+		existing[hook.URL] = hook
+	}
+
+	planned := &PlannedRepository{Owner: repo.Owner, Name: repo.Name}
+	result.Planned = planned
+
+	for _, configured := range settings.Webhooks {
+		desired := &github.WebhookInfo{
+			URL:         configured.URL,
+			ContentType: configured.ContentTypeOrDefault(),
+			Events:      sortedStrings(configured.EventsOrDefault()),
+			Active:      configured.ActiveOrDefault(),
+			HasSecret:   configured.SecretEnv != "",
+		}
+		field := "webhooks." + configured.URL
+
+		found, exists := existing[configured.URL]
+		if !exists {
+			result.Changes = append(result.Changes, Change{
+				Field:   field,
+				Current: resourceAbsent,
+				Desired: resourcePresent,
+				Source:  source,
+			})
+			planned.Webhooks = append(planned.Webhooks, WebhookOperation{
+				Action:    ActionCreate,
+				Webhook:   desired,
+				SecretEnv: configured.SecretEnv,
+			})
+			continue
+		}
+
+		changes := diffWebhook(field, found, *desired)
+		if len(changes) == 0 {
+			continue
+		}
+		result.Changes = append(result.Changes, withSource(changes, source)...)
+		planned.Webhooks = append(planned.Webhooks, WebhookOperation{
+			Action:    ActionUpdate,
+			ID:        found.ID,
+			Webhook:   desired,
+			SecretEnv: configured.SecretEnv,
+		})
+	}
+
+	mode := settings.UnmanagedWebhooksOrDefault()
+	for _, hook := range current {
+		if mode == config.UnmanagedKeep || slices.ContainsFunc(settings.Webhooks, func(w config.Webhook) bool {
+			return w.URL == hook.URL
+		}) {
+			continue
+		}
+		change := Change{
+			Field:   "webhooks." + hook.URL,
+			Current: resourcePresent,
+			Desired: resourceUnmanaged,
+			Source:  origins.Of("unmanaged_webhooks"),
+		}
+		if mode == config.UnmanagedDelete {
+			change.Desired = resourceAbsent
+			change.Removal = true
+			planned.Webhooks = append(planned.Webhooks, WebhookOperation{
+				Action:  ActionDelete,
+				ID:      hook.ID,
+				Webhook: &github.WebhookInfo{URL: hook.URL},
+			})
+		}
+		result.Changes = append(result.Changes, change)
+	}
+
+	if len(planned.Webhooks) > 0 {
+		planned.observed.Webhooks = current
+	}
+
+	if !dryRun {
+		for _, op := range planned.Webhooks {
+			if applyErr := s.applyWebhookOperation(ctx, repo.Owner, repo.Name, op); applyErr != nil {
+				result.Error = applyErr
+				return result
+			}
+		}
+	}
+
+	return result
+}
+
+// diffWebhook reports the differences between an existing webhook and the
+// desired one as changes under prefix, e.g. "webhooks.<url>.events". A secret
+// is only reported when the desired webhook has one and the existing one does not.
+func diffWebhook(prefix string, current, desired github.WebhookInfo) []Change {
+	changes := make([]Change, 0)
+	if current.ContentType != desired.ContentType {
+		changes = append(changes, Change{
+			Field:   prefix + ".content_type",
+			Current: current.ContentType,
+			Desired: desired.ContentType,
+		})
+	}
+	if events := sortedStrings(current.Events); !slices.Equal(events, desired.Events) {
+		changes = append(changes, Change{Field: prefix + ".events", Current: events, Desired: desired.Events})
+	}
+	if current.Active != desired.Active {
+		changes = append(changes, Change{Field: prefix + ".active", Current: current.Active, Desired: desired.Active})
+	}
+	if desired.HasSecret && !current.HasSecret {
+		changes = append(changes, Change{Field: prefix + ".secret", Current: secretUnset, Desired: secretSet})
+	}
+	return changes
+}
+
+// applyWebhookOperation performs a single planned webhook operation, reading
+// the secret from the environment just before it is sent.
+func (s *Syncer) applyWebhookOperation(ctx context.Context, owner, name string, op WebhookOperation) error {
+	if op.Action == ActionDelete {
+		return s.client.DeleteWebhook(ctx, owner, name, op.ID)
+	}
+
+	webhook := *op.Webhook
+	secret, err := config.Webhook{URL: webhook.URL, SecretEnv: op.SecretEnv}.Secret()
+	if err != nil {
+		return err
+	}
+	webhook.Secret = secret
+
+	switch op.Action {
+	case ActionCreate:
+		return s.client.CreateWebhook(ctx, owner, name, &webhook)
+	case ActionUpdate:
+		return s.client.UpdateWebhook(ctx, owner, name, op.ID, &webhook)
+	}
+	return fmt.Errorf("unknown webhook action %q", op.Action)
+}
@@ -0,0 +1,113 @@
+package sync //nolint:testpackage // Tests internal implementation details
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mholtzscher/github-janitor/internal/config"
+	"github.com/mholtzscher/github-janitor/internal/github"
+)
+
+const testWebhookSecret = "s3cret-value"
+
+func webhookFixture(t *testing.T) (*fakeGitHubClient, *config.Config) {
+	t.Helper()
+	t.Setenv("CI_WEBHOOK_SECRET", testWebhookSecret)
+
+	fake, cfg := repoFixture(config.Settings{
+		Webhooks: []config.Webhook{
+			{
+				URL:       "https://ci.example.com/hook",
+				Events:    []string{"pull_request", "push"},
+				SecretEnv: "CI_WEBHOOK_SECRET",
+			},
+			{URL: "https://chat.example.com/hook"},
+		},
+		UnmanagedWebhooks: stringPtr(config.UnmanagedDelete),
+	})
+	fake.webhooks = []github.WebhookInfo{
+		{ID: 1, URL: "https://ci.example.com/hook", ContentType: "form", Events: []string{"push"}, Active: true},
+		{ID: 2, URL: "https://old.example.com/hook", ContentType: "json", Events: []string{"push"}, Active: true},
+	}
+	return fake, cfg
+}
+
+func TestSyncAll_Webhooks(t *testing.T) {
+	fake, cfg := webhookFixture(t)
+	s := &Syncer{client: fake, config: cfg}
+
+	results, err := s.SyncAll(t.Context(), true)
+	if err != nil {
+		t.Fatalf("SyncAll() error = %v", err)
+	}
+	if results[0].Error != nil {
+		t.Fatalf("Error = %v; want nil", results[0].Error)
+	}
+
+	changes := changeByField(t, results[0].Changes)
+	if c := changes["webhooks.https://ci.example.com/hook.content_type"]; c.Current != "form" || c.Desired != "json" {
+		t.Fatalf("content_type change = %+v; want form → json", c)
+	}
+	if c := changes["webhooks.https://ci.example.com/hook.secret"]; c.Current != secretUnset || c.Desired != secretSet {
+		t.Fatalf("secret change = %+v; want unset → set", c)
+	}
+	if c := changes["webhooks.https://chat.example.com/hook"]; c.Desired != resourcePresent {
+		t.Fatalf("chat change = %+v; want creation", c)
+	}
+	if c := changes["webhooks.https://old.example.com/hook"]; !c.Removal {
+		t.Fatalf("old change = %+v; want removal", c)
+	}
+
+	data, err := json.Marshal(struct {
+		Changes []Change
+		Plan    *Plan
+	}{results[0].Changes, NewPlan(results)})
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if strings.Contains(string(data), testWebhookSecret) {
+		t.Fatalf("secret leaked into changes or plan: %s", data)
+	}
+
+	if _, syncErr := s.SyncAll(t.Context(), false); syncErr != nil {
+		t.Fatalf("SyncAll() error = %v", syncErr)
+	}
+	want := []string{
+		`update 1 secret="` + testWebhookSecret + `"`,
+		`create https://chat.example.com/hook secret=""`,
+		"delete 2",
+	}
+	if !reflect.DeepEqual(fake.webhookCalls, want) {
+		t.Fatalf("webhook calls = %v; want %v", fake.webhookCalls, want)
+	}
+}
+
+func TestApplyPlan_WebhookSecretReadAtApply(t *testing.T) {
+	fake, cfg := webhookFixture(t)
+	s := &Syncer{client: fake, config: cfg}
+
+	results, err := s.SyncAll(t.Context(), true)
+	if err != nil {
+		t.Fatalf("SyncAll() error = %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "plan.json")
+	if writeErr := WritePlan(path, NewPlan(results)); writeErr != nil {
+		t.Fatalf("WritePlan() error = %v", writeErr)
+	}
+	plan, err := ReadPlan(path)
+	if err != nil {
+		t.Fatalf("ReadPlan() error = %v", err)
+	}
+
+	t.Setenv("CI_WEBHOOK_SECRET", "")
+	applied := s.ApplyPlan(t.Context(), plan)
+	if applied[0].Error == nil || !strings.Contains(applied[0].Error.Error(), "CI_WEBHOOK_SECRET") {
+		t.Fatalf("Error = %v; want missing CI_WEBHOOK_SECRET", applied[0].Error)
+	}
+	if len(fake.webhookCalls) != 0 {
+		t.Fatalf("webhook calls = %v; want none", fake.webhookCalls)
+	}
+}
@@ -5,12 +5,19 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/mholtzscher/github-janitor/cmd"
 )
 
 func main() {
-	if err := cmd.Run(context.Background(), os.Args); err != nil {
+	// Cancel in-flight work on Ctrl-C or SIGTERM so runs stop cleanly.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+
+	err := cmd.Run(ctx, os.Args)
+	stop()
+	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}